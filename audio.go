@@ -0,0 +1,230 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const readChunkSize = 64 << 10
+
+const (
+	defaultSampleRate = 16000
+	opusDecodeRate    = 48000
+)
+
+// DurationOf returns the playback length of pcm, or zero if any of the format
+// parameters is invalid. Trailing bytes that don't make up a full frame are ignored.
+func DurationOf(pcm []byte, sampleRate, channels, bitsPerSample int) time.Duration {
+	if sampleRate <= 0 || channels <= 0 || bitsPerSample <= 0 || bitsPerSample%8 != 0 {
+		return 0
+	}
+	frames := len(pcm) / (channels * bitsPerSample / 8)
+	return time.Duration(frames) * time.Second / time.Duration(sampleRate)
+}
+
+// bytesFor returns how many bytes d of 16-bit mono audio at rate takes.
+func bytesFor(d time.Duration, rate int) int {
+	return int(d * time.Duration(rate) / time.Second * 2)
+}
+
+func ReadAudioFile(path string) ([]byte, error) {
+	return ReadAudioFileContext(context.Background(), path)
+}
+
+// ReadAudioFileContext reads the file at path in chunks, giving up with
+// ctx.Err() as soon as ctx is done.
+func ReadAudioFileContext(ctx context.Context, path string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, info.Size()))
+	chunk := make([]byte, readChunkSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n, err := file.Read(chunk)
+		buf.Write(chunk[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// contentTypeRate returns the rate parameter of a Content-Type such as
+// ContentType, or zero if it has none.
+func contentTypeRate(contentType string) int {
+	for _, param := range strings.Split(contentType, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if ok && strings.EqualFold(name, "rate") {
+			rate, _ := strconv.Atoi(value)
+			return rate
+		}
+	}
+	return 0
+}
+
+// withRate returns contentType with its rate parameter set to rate, adding
+// one if it has none.
+func withRate(contentType string, rate int) string {
+	var params []string
+	for _, param := range strings.Split(contentType, ";") {
+		param = strings.TrimSpace(param)
+		name, _, _ := strings.Cut(param, "=")
+		if param != "" && !strings.EqualFold(name, "rate") {
+			params = append(params, param)
+		}
+	}
+	params = append(params, fmt.Sprintf("rate=%d", rate))
+	return strings.Join(params, "; ") + ";"
+}
+
+// isL16 reports whether contentType is linear PCM.
+func isL16(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.EqualFold(strings.TrimSpace(mediaType), "audio/l16")
+}
+
+// Resample converts 16-bit little-endian mono PCM from one sample rate to
+// another by linear interpolation.
+func Resample(pcm []byte, from, to int) []byte {
+	n := len(pcm) / 2
+	if from <= 0 || to <= 0 || from == to || n == 0 {
+		return pcm
+	}
+	sample := func(i int) float64 {
+		return float64(int16(binary.LittleEndian.Uint16(pcm[2*i:])))
+	}
+	m := int(int64(n) * int64(to) / int64(from))
+	out := make([]byte, 2*m)
+	for j := 0; j < m; j++ {
+		pos := float64(j) * float64(from) / float64(to)
+		i := int(pos)
+		v := sample(i)
+		if i+1 < n {
+			v += (sample(i+1) - v) * (pos - float64(i))
+		}
+		binary.LittleEndian.PutUint16(out[2*j:], uint16(int16(math.Round(v))))
+	}
+	return out
+}
+
+func OggOpusContentType(rate int) string {
+	return fmt.Sprintf("audio/ogg; codecs=opus; rate=%d;", rate)
+}
+
+func FLACContentType(rate int) string {
+	return fmt.Sprintf("audio/x-flac; rate=%d;", rate)
+}
+
+// FLACSampleRate reads the sample rate from the STREAMINFO block that starts
+// a FLAC stream. ListenFile and the like detect FLAC audio with DetectFormat
+// and send it with FLACContentType unless WithContentType says otherwise.
+func FLACSampleRate(flac []byte) (int, error) {
+	// "fLaC", the 4-byte header of the STREAMINFO block, then 10 bytes of
+	// block and frame sizes before the 20-bit sample rate.
+	if len(flac) < 21 || string(flac[:4]) != "fLaC" || flac[4]&0x7f != 0 {
+		return 0, ErrNotFLAC
+	}
+	info := flac[8:]
+	rate := int(info[10])<<12 | int(info[11])<<4 | int(info[12])>>4
+	if rate == 0 {
+		return 0, ErrNotFLAC
+	}
+	return rate, nil
+}
+
+// OpusSampleRate reads the input sample rate from the OpusHead packet that
+// starts an Ogg Opus stream. Streams that don't record it report 48 kHz, the
+// rate Opus always decodes at.
+func OpusSampleRate(ogg []byte) (int, error) {
+	packet, ok := oggPacket(ogg)
+	if !ok {
+		return 0, ErrNotOggOpus
+	}
+	rate, _, ok := opusHead(packet)
+	if !ok {
+		return 0, ErrNotOggOpus
+	}
+	return rate, nil
+}
+
+// opusHead reads the input sample rate and the channels of an OpusHead
+// packet: "OpusHead", the version, the channel count, the pre-skip and the
+// rate.
+func opusHead(packet []byte) (rate, channels int, ok bool) {
+	if len(packet) < 16 || string(packet[:8]) != "OpusHead" {
+		return 0, 0, false
+	}
+	rate = int(binary.LittleEndian.Uint32(packet[12:16]))
+	if rate == 0 {
+		rate = opusDecodeRate
+	}
+	return rate, int(packet[9]), true
+}
+
+// silenceThreshold is the absolute 16-bit sample value below which audio is
+// considered silent.
+const silenceThreshold = 500
+
+// TrimSilence removes leading and trailing silence from 16-bit little-endian
+// mono PCM, keeping lead of audio ahead of the first sample above the
+// silence threshold. lead is measured at 16 kHz, the rate sent by default;
+// clients trim at their own WithSampleRate. Audio that is silent throughout
+// trims to nothing.
+func TrimSilence(pcm []byte, lead time.Duration) []byte {
+	return trimSilence(pcm, lead, defaultSampleRate)
+}
+
+// trimSilence is TrimSilence for PCM sampled at rate.
+func trimSilence(pcm []byte, lead time.Duration, rate int) []byte {
+	n := len(pcm) / 2
+	first, last := -1, -1
+	for i := 0; i < n; i++ {
+		if abs16(pcm[2*i:]) >= silenceThreshold {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return pcm[:0]
+	}
+	first -= int(lead * time.Duration(rate) / time.Second)
+	if first < 0 {
+		first = 0
+	}
+	return pcm[2*first : 2*(last+1)]
+}
+
+func abs16(b []byte) int {
+	v := int(int16(binary.LittleEndian.Uint16(b)))
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,62 @@
+package gorec
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func oggOpus(rate uint32) []byte {
+	b := make([]byte, 27, 64)
+	copy(b, "OggS")
+	b[26] = 1
+	b = append(b, 19)
+	b = append(b, "OpusHead\x01\x01\x00\x00"...)
+	b = binary.LittleEndian.AppendUint32(b, rate)
+	return append(b, 0, 0, 0)
+}
+
+func TestOpusSampleRate(t *testing.T) {
+	rate, err := OpusSampleRate(oggOpus(16000))
+	if err != nil || rate != 16000 {
+		t.Fatalf("OpusSampleRate = %d, %v, want 16000", rate, err)
+	}
+	rate, err = OpusSampleRate(oggOpus(0))
+	if err != nil || rate != opusDecodeRate {
+		t.Fatalf("OpusSampleRate without input rate = %d, %v, want %d", rate, err, opusDecodeRate)
+	}
+}
+
+func TestOpusSampleRateInvalid(t *testing.T) {
+	truncated := make([]byte, 27)
+	copy(truncated, "OggS")
+	truncated[26] = 200
+	for name, b := range map[string][]byte{
+		"not ogg":          []byte("nope"),
+		"truncated header": truncated,
+		"short packet":     oggOpus(16000)[:40],
+	} {
+		if _, err := OpusSampleRate(b); err != ErrNotOggOpus {
+			t.Errorf("%s: err = %v, want ErrNotOggOpus", name, err)
+		}
+	}
+}
+
+func TestTrimSilence(t *testing.T) {
+	pcm := make([]byte, 2*16000)
+	for i := 8000; i < 9000; i++ {
+		binary.LittleEndian.PutUint16(pcm[2*i:], 0xf830)
+	}
+	if got := len(TrimSilence(pcm, 0)); got != 2000 {
+		t.Errorf("TrimSilence(pcm, 0) kept %d bytes, want 2000", got)
+	}
+	if got := len(TrimSilence(pcm, 100*time.Millisecond)); got != 2000+3200 {
+		t.Errorf("TrimSilence(pcm, 100ms) kept %d bytes, want 5200", got)
+	}
+	if got := len(trimSilence(pcm, 100*time.Millisecond, 8000)); got != 2000+1600 {
+		t.Errorf("trimSilence(pcm, 100ms, 8000) kept %d bytes, want 3600", got)
+	}
+	if got := len(TrimSilence(make([]byte, 100), 0)); got != 0 {
+		t.Errorf("TrimSilence(silence, 0) kept %d bytes", got)
+	}
+}
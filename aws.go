@@ -0,0 +1,369 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AWSBackend is a Backend for Amazon Transcribe's batch jobs: each audio is
+// uploaded to Bucket in S3, transcribed by a job polled every PollInterval
+// until done, and deleted along with its job once the transcript is
+// fetched. Requests are signed with the credentials AccessKeyID,
+// SecretAccessKey and, for temporary ones, SessionToken, for Region.
+//
+// Linear PCM is uploaded as WAV. Word timings and confidences come from
+// the transcript's items; with Speakers above one, their speaker labels
+// number the Words' Speaker from 1.
+type AWSBackend struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Region          string
+	Bucket          string
+
+	// Speakers, when above one, has Transcribe tell apart up to that many
+	// speakers.
+	Speakers int
+
+	// PollInterval is how often a job is checked on, five seconds by
+	// default.
+	PollInterval time.Duration
+
+	// TranscribeEndpoint and S3Endpoint override the endpoints of Region,
+	// S3's addressing the bucket by path. HTTPClient defaults to a default
+	// client.
+	TranscribeEndpoint string
+	S3Endpoint         string
+	HTTPClient         *http.Client
+
+	clock clock
+}
+
+type awsJob struct {
+	TranscriptionJob struct {
+		TranscriptionJobStatus string `json:"TranscriptionJobStatus"`
+		FailureReason          string `json:"FailureReason"`
+		Transcript             struct {
+			TranscriptFileUri string `json:"TranscriptFileUri"`
+		} `json:"Transcript"`
+	} `json:"TranscriptionJob"`
+}
+
+type awsTranscript struct {
+	Results struct {
+		Transcripts []struct {
+			Transcript string `json:"transcript"`
+		} `json:"transcripts"`
+		Items []struct {
+			Type         string `json:"type"`
+			StartTime    string `json:"start_time"`
+			EndTime      string `json:"end_time"`
+			SpeakerLabel string `json:"speaker_label"`
+			Alternatives []struct {
+				Confidence string `json:"confidence"`
+				Content    string `json:"content"`
+			} `json:"alternatives"`
+		} `json:"items"`
+		SpeakerLabels struct {
+			Segments []struct {
+				Items []struct {
+					StartTime    string `json:"start_time"`
+					SpeakerLabel string `json:"speaker_label"`
+				} `json:"items"`
+			} `json:"segments"`
+		} `json:"speaker_labels"`
+	} `json:"results"`
+}
+
+func (b *AWSBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, params BackendParams) (*GoogleResponse, error) {
+	content, err := ioutil.ReadAll(audio)
+	if err != nil {
+		return nil, err
+	}
+	rate := contentTypeRate(params.ContentType)
+	var format string
+	switch {
+	case isL16(params.ContentType):
+		if rate <= 0 {
+			rate = defaultSampleRate
+		}
+		w := WAV{SampleRate: rate, BitsPerSample: 16, Channels: 1, Data: content}
+		content, format = w.Bytes(), "wav"
+	case cloudEncoding(params.ContentType) == "FLAC":
+		format = "flac"
+	case cloudEncoding(params.ContentType) == "OGG_OPUS":
+		format = "ogg"
+	default:
+		return nil, fmt.Errorf("%w: Transcribe takes no %s", ErrUnsupportedFormat, params.ContentType)
+	}
+
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	name := "gorec-" + hex.EncodeToString(id[:])
+	object := b.s3URL() + "/gorec/" + name + "." + format
+	if err := b.send(ctx, "PUT", object, "s3", nil, content, nil); err != nil {
+		return nil, fmt.Errorf("Uploading audio: %w", err)
+	}
+	defer b.cleanup(ctx, func(ctx context.Context) { b.send(ctx, "DELETE", object, "s3", nil, nil, nil) })
+
+	job := map[string]any{
+		"TranscriptionJobName": name,
+		"LanguageCode":         lang.Code(),
+		"MediaFormat":          format,
+		"Media":                map[string]string{"MediaFileUri": "s3://" + b.Bucket + "/gorec/" + name + "." + format},
+	}
+	if rate > 0 {
+		job["MediaSampleRateHertz"] = rate
+	}
+	if b.Speakers > 1 {
+		job["Settings"] = map[string]any{"ShowSpeakerLabels": true, "MaxSpeakerLabels": b.Speakers}
+	}
+	if err := b.transcribe(ctx, "StartTranscriptionJob", job, nil); err != nil {
+		return nil, err
+	}
+	defer b.cleanup(ctx, func(ctx context.Context) {
+		b.transcribe(ctx, "DeleteTranscriptionJob", map[string]string{"TranscriptionJobName": name}, nil)
+	})
+
+	interval := b.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	var status awsJob
+	for {
+		if err := b.transcribe(ctx, "GetTranscriptionJob", map[string]string{"TranscriptionJobName": name}, &status); err != nil {
+			return nil, err
+		}
+		switch status.TranscriptionJob.TranscriptionJobStatus {
+		case "COMPLETED":
+			var t awsTranscript
+			// The transcript's URL is presigned.
+			if err := b.fetch(ctx, status.TranscriptionJob.Transcript.TranscriptFileUri, &t); err != nil {
+				return nil, err
+			}
+			return t.googleResponse(), nil
+		case "FAILED":
+			return nil, fmt.Errorf("Transcription job failed: %s", status.TranscriptionJob.FailureReason)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-b.now().After(interval):
+		}
+	}
+}
+
+// cleanup runs fn to delete what a request left behind, even once ctx is
+// done.
+func (b *AWSBackend) cleanup(ctx context.Context, fn func(context.Context)) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	fn(ctx)
+}
+
+func (t *awsTranscript) googleResponse() *GoogleResponse {
+	if len(t.Results.Transcripts) == 0 || strings.TrimSpace(t.Results.Transcripts[0].Transcript) == "" {
+		return &GoogleResponse{}
+	}
+	speakers := make(map[string]string)
+	for _, seg := range t.Results.SpeakerLabels.Segments {
+		for _, item := range seg.Items {
+			speakers[item.StartTime] = item.SpeakerLabel
+		}
+	}
+	alt := Alternative{Transcript: t.Results.Transcripts[0].Transcript}
+	labels := make(map[string]int)
+	for _, item := range t.Results.Items {
+		if item.Type != "pronunciation" || len(item.Alternatives) == 0 {
+			continue
+		}
+		start, _ := strconv.ParseFloat(item.StartTime, 64)
+		end, _ := strconv.ParseFloat(item.EndTime, 64)
+		w := Word{
+			Word:  item.Alternatives[0].Content,
+			Start: time.Duration(start * float64(time.Second)),
+			End:   time.Duration(end * float64(time.Second)),
+		}
+		label := item.SpeakerLabel
+		if label == "" {
+			label = speakers[item.StartTime]
+		}
+		if label != "" {
+			if _, ok := labels[label]; !ok {
+				labels[label] = len(labels) + 1
+			}
+			w.Speaker = labels[label]
+		}
+		w.Confidence, _ = strconv.ParseFloat(item.Alternatives[0].Confidence, 64)
+		alt.Confidence += w.Confidence
+		alt.Words = append(alt.Words, w)
+	}
+	if len(alt.Words) > 0 {
+		alt.Confidence /= float64(len(alt.Words))
+	}
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{alt}, Final: true}}}
+}
+
+func (b *AWSBackend) s3URL() string {
+	if b.S3Endpoint != "" {
+		return strings.TrimSuffix(b.S3Endpoint, "/") + "/" + b.Bucket
+	}
+	return "https://" + b.Bucket + ".s3." + b.Region + ".amazonaws.com"
+}
+
+// transcribe calls action of the Transcribe API with input, decoding its
+// output into out unless nil.
+func (b *AWSBackend) transcribe(ctx context.Context, action string, input, out any) error {
+	endpoint := b.TranscribeEndpoint
+	if endpoint == "" {
+		endpoint = "https://transcribe." + b.Region + ".amazonaws.com/"
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	header := http.Header{
+		"Content-Type": {"application/x-amz-json-1.1"},
+		"X-Amz-Target": {"Transcribe." + action},
+	}
+	return b.send(ctx, "POST", endpoint, "transcribe", header, body, out)
+}
+
+// send sends a request to service signed with Signature Version 4,
+// decoding the response into out unless nil.
+func (b *AWSBackend) send(ctx context.Context, method, url, service string, header http.Header, body []byte, out any) error {
+	r, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	if service == "s3" {
+		r.Header.Set("X-Amz-Content-Sha256", hashHex(body))
+	}
+	if b.SessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", b.SessionToken)
+	}
+	signV4(r, body, b.AccessKeyID, b.SecretAccessKey, b.Region, service, b.now().Now())
+	return b.do(r, out)
+}
+
+// fetch gets url unsigned, decoding it into out.
+func (b *AWSBackend) fetch(ctx context.Context, url string, out any) error {
+	if url == "" {
+		return errors.New("Transcription job has no transcript")
+	}
+	r, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	return b.do(r, out)
+}
+
+func (b *AWSBackend) do(r *http.Request, out any) error {
+	client := b.HTTPClient
+	if client == nil {
+		client = sharedClient
+	}
+	resp, err := client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("Reading response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return newAPIError(resp.StatusCode, respBody)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("Decoding response: %w", err)
+	}
+	return nil
+}
+
+func (b *AWSBackend) now() clock {
+	if b.clock == nil {
+		return realClock{}
+	}
+	return b.clock
+}
+
+// signV4 signs r, whose body is body, with AWS Signature Version 4,
+// covering its host and X-Amz-* headers.
+func signV4(r *http.Request, body []byte, keyID, secret, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := amzDate[:8]
+	r.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": r.URL.Host}
+	for k, v := range r.Header {
+		if k := strings.ToLower(k); strings.HasPrefix(k, "x-amz-") {
+			headers[k] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signed := strings.Join(names, ";")
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonical := strings.Join([]string{
+		r.Method,
+		path,
+		strings.ReplaceAll(r.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signed,
+		hashHex(body),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashHex([]byte(canonical))
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	for _, part := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	r.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+keyID+"/"+scope+", SignedHeaders="+signed+", Signature="+signature)
+}
+
+func hashHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
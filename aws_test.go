@@ -0,0 +1,109 @@
+package gorec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSignV4(t *testing.T) {
+	// The get-vanilla case of AWS's Signature Version 4 test suite.
+	r, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	signV4(r, nil, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "service", now)
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := r.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %s\nwant %s", got, want)
+	}
+}
+
+func TestAWSBackend(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	objects := make(map[string][]byte)
+	polls := 0
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/transcript.json" {
+			fmt.Fprint(w, `{"results": {
+				"transcripts": [{"transcript": "Hello, hi."}],
+				"items": [
+					{"type": "pronunciation", "start_time": "0.0", "end_time": "0.5", "alternatives": [{"confidence": "0.9", "content": "Hello"}]},
+					{"type": "punctuation", "alternatives": [{"confidence": "0.0", "content": ","}]},
+					{"type": "pronunciation", "start_time": "1.0", "end_time": "1.25", "alternatives": [{"confidence": "0.7", "content": "hi"}]}],
+				"speaker_labels": {"segments": [
+					{"items": [{"start_time": "0.0", "speaker_label": "spk_1"}]},
+					{"items": [{"start_time": "1.0", "speaker_label": "spk_0"}]}]}}}`)
+			return
+		}
+		if auth := r.Header.Get("Authorization"); !strings.Contains(auth, "Credential=AKID/") || r.Header.Get("X-Amz-Security-Token") != "session" {
+			t.Errorf("%s %s unsigned: %q", r.Method, r.URL.Path, auth)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if target := r.Header.Get("X-Amz-Target"); target != "" {
+			calls = append(calls, target)
+			var input map[string]any
+			json.Unmarshal(body, &input)
+			switch target {
+			case "Transcribe.StartTranscriptionJob":
+				uri := input["Media"].(map[string]any)["MediaFileUri"].(string)
+				if input["LanguageCode"] != "fr-FR" || input["MediaFormat"] != "wav" || !strings.HasPrefix(uri, "s3://audio/gorec/") {
+					t.Errorf("job %v", input)
+				}
+				if _, ok := objects["/audio"+strings.TrimPrefix(uri, "s3://audio")]; !ok {
+					t.Errorf("%s not uploaded", uri)
+				}
+			case "Transcribe.GetTranscriptionJob":
+				status := "IN_PROGRESS"
+				if polls++; polls == 2 {
+					status = "COMPLETED"
+				}
+				fmt.Fprintf(w, `{"TranscriptionJob": {"TranscriptionJobStatus": %q, "Transcript": {"TranscriptFileUri": "%s/transcript.json"}}}`, status, srv.URL)
+			}
+			return
+		}
+		calls = append(calls, r.Method+" s3")
+		if r.Header.Get("X-Amz-Content-Sha256") != hashHex(body) {
+			t.Error("S3 request without its payload hash")
+		}
+		switch r.Method {
+		case "PUT":
+			objects[r.URL.Path] = body
+		case "DELETE":
+			delete(objects, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	b := &AWSBackend{
+		AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session", Region: "eu-west-1", Bucket: "audio",
+		Speakers: 2, PollInterval: time.Millisecond, TranscribeEndpoint: srv.URL + "/", S3Endpoint: srv.URL,
+	}
+	h, err := ListenFile([]byte{1, 2}, "", WithBackend(b), WithLanguages(French))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Alternative.Transcript != "Hello, hi." || h.Alternative.Confidence < 0.79 || h.Alternative.Confidence > 0.81 {
+		t.Errorf("hypothesis %v", h)
+	}
+	if words := h.Alternative.Words; len(words) != 2 || words[0].Speaker != 1 || words[1].Speaker != 2 || words[1].End != 1250*time.Millisecond || words[1].Confidence != 0.7 {
+		t.Errorf("Words = %+v", words)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := "PUT s3,Transcribe.StartTranscriptionJob,Transcribe.GetTranscriptionJob,Transcribe.GetTranscriptionJob,Transcribe.DeleteTranscriptionJob,DELETE s3"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("calls %s\nwant %s", got, want)
+	}
+	if len(objects) != 0 {
+		t.Errorf("%d objects left in S3", len(objects))
+	}
+}
@@ -0,0 +1,272 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// AzureBackend is a Backend for Azure's Speech to Text REST API for short
+// audio, up to a minute of it. Key is the subscription key of the Speech
+// resource and Region where it lives, such as "westeurope". Longer audio
+// is transcribed with TranscribeBatch.
+//
+// Linear PCM is sent as WAV and Ogg Opus as is; Azure takes no FLAC.
+type AzureBackend struct {
+	Key    string
+	Region string
+
+	// Endpoint and BatchEndpoint override the short audio and batch
+	// transcription endpoints of Region. HTTPClient defaults to a default
+	// client.
+	Endpoint      string
+	BatchEndpoint string
+	HTTPClient    *http.Client
+
+	// PollInterval is how often TranscribeBatch checks on its job, ten
+	// seconds by default.
+	PollInterval time.Duration
+}
+
+type azureResponse struct {
+	RecognitionStatus string `json:"RecognitionStatus"`
+	NBest             []struct {
+		Confidence float64 `json:"Confidence"`
+		Display    string  `json:"Display"`
+	} `json:"NBest"`
+}
+
+// azureNoSpeech are the recognition statuses of audio Azure heard no
+// speech in.
+var azureNoSpeech = map[string]bool{"NoMatch": true, "InitialSilenceTimeout": true, "BabbleTimeout": true}
+
+func (b *AzureBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, params BackendParams) (*GoogleResponse, error) {
+	content, err := ioutil.ReadAll(audio)
+	if err != nil {
+		return nil, err
+	}
+	var contentType string
+	switch {
+	case isL16(params.ContentType):
+		rate := contentTypeRate(params.ContentType)
+		if rate <= 0 {
+			rate = defaultSampleRate
+		}
+		w := WAV{SampleRate: rate, BitsPerSample: 16, Channels: 1, Data: content}
+		content, contentType = w.Bytes(), fmt.Sprintf("audio/wav; codecs=audio/pcm; samplerate=%d", rate)
+	case cloudEncoding(params.ContentType) == "OGG_OPUS":
+		contentType = "audio/ogg; codecs=opus"
+	default:
+		return nil, fmt.Errorf("%w: Azure takes no %s", ErrUnsupportedFormat, params.ContentType)
+	}
+
+	endpoint := b.Endpoint
+	if endpoint == "" {
+		endpoint = "https://" + b.Region + ".stt.speech.microsoft.com/speech/recognition/conversation/cognitiveservices/v1"
+	}
+	q := url.Values{"language": {lang.Code()}, "format": {"detailed"}}
+	switch params.Profanity {
+	case ProfanityOff:
+		q.Set("profanity", "raw")
+	case ProfanityMask:
+		q.Set("profanity", "masked")
+	case ProfanityDrop:
+		q.Set("profanity", "removed")
+	}
+	var ar azureResponse
+	if err := b.do(ctx, "POST", endpoint+"?"+q.Encode(), content, contentType, &ar); err != nil {
+		return nil, err
+	}
+	if azureNoSpeech[ar.RecognitionStatus] {
+		return &GoogleResponse{}, nil
+	}
+	if ar.RecognitionStatus != "Success" {
+		return nil, fmt.Errorf("Azure recognition failed: %s", ar.RecognitionStatus)
+	}
+	r := Result{Final: true}
+	for i, nb := range ar.NBest {
+		if params.MaxAlternatives > 0 && i == params.MaxAlternatives {
+			break
+		}
+		r.Alternatives = append(r.Alternatives, Alternative{Transcript: nb.Display, Confidence: nb.Confidence})
+	}
+	return &GoogleResponse{Results: []Result{r}}, nil
+}
+
+type azureTranscription struct {
+	Self   string `json:"self"`
+	Status string `json:"status"`
+	Links  struct {
+		Files string `json:"files"`
+	} `json:"links"`
+	Properties struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"properties"`
+}
+
+type azureFiles struct {
+	Values []struct {
+		Kind  string `json:"kind"`
+		Links struct {
+			ContentURL string `json:"contentUrl"`
+		} `json:"links"`
+	} `json:"values"`
+}
+
+type azureBatchResult struct {
+	RecognizedPhrases []struct {
+		Channel int `json:"channel"`
+		NBest   []struct {
+			Confidence float64 `json:"confidence"`
+			Display    string  `json:"display"`
+			Words      []struct {
+				Word            string `json:"word"`
+				OffsetInTicks   int64  `json:"offsetInTicks"`
+				DurationInTicks int64  `json:"durationInTicks"`
+			} `json:"words"`
+		} `json:"nBest"`
+	} `json:"recognizedPhrases"`
+}
+
+// TranscribeBatch transcribes the audio at contentURL, such as an Azure
+// Blob Storage URL with a SAS token, with Azure's batch transcription API,
+// for audio too long for Recognize. It submits a job, polls it every
+// PollInterval until done and deletes it once its result is fetched.
+// Every phrase recognized is a Result of its own, with word timings.
+func (b *AzureBackend) TranscribeBatch(ctx context.Context, contentURL string, lang Language) (*GoogleResponse, error) {
+	endpoint := b.BatchEndpoint
+	if endpoint == "" {
+		endpoint = "https://" + b.Region + ".api.cognitive.microsoft.com/speechtotext/v3.1"
+	}
+	req, _ := json.Marshal(map[string]any{
+		"contentUrls": []string{contentURL},
+		"locale":      lang.Code(),
+		"displayName": "gorec",
+		"properties":  map[string]any{"wordLevelTimestampsEnabled": true},
+	})
+	var job azureTranscription
+	if err := b.do(ctx, "POST", endpoint+"/transcriptions", req, "application/json", &job); err != nil {
+		return nil, err
+	}
+	defer func() {
+		// Jobs are kept by Azure until deleted, counting against a quota.
+		cleanup, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		b.do(cleanup, "DELETE", job.Self, nil, "", nil)
+	}()
+
+	interval := b.PollInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	for job.Status != "Succeeded" {
+		if job.Status == "Failed" {
+			return nil, fmt.Errorf("Azure transcription failed: %s", job.Properties.Error.Message)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+		if err := b.do(ctx, "GET", job.Self, nil, "", &job); err != nil {
+			return nil, err
+		}
+	}
+
+	var files azureFiles
+	if err := b.do(ctx, "GET", job.Links.Files, nil, "", &files); err != nil {
+		return nil, err
+	}
+	gr := &GoogleResponse{}
+	for _, f := range files.Values {
+		if f.Kind != "Transcription" {
+			continue
+		}
+		var res azureBatchResult
+		if err := b.get(ctx, f.Links.ContentURL, &res); err != nil {
+			return nil, err
+		}
+		gr.Results = append(gr.Results, res.results()...)
+	}
+	return gr, nil
+}
+
+func (res *azureBatchResult) results() []Result {
+	var results []Result
+	for _, phrase := range res.RecognizedPhrases {
+		r := Result{Final: true, Channel: phrase.Channel}
+		for _, nb := range phrase.NBest {
+			alt := Alternative{Transcript: nb.Display, Confidence: nb.Confidence}
+			for _, w := range nb.Words {
+				// Ticks are 100 nanoseconds.
+				start := time.Duration(w.OffsetInTicks) * 100
+				alt.Words = append(alt.Words, Word{Word: w.Word, Start: start, End: start + time.Duration(w.DurationInTicks)*100})
+			}
+			r.Alternatives = append(r.Alternatives, alt)
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// do sends an authenticated request with body, decoding the response into
+// out unless nil.
+func (b *AzureBackend) do(ctx context.Context, method, u string, body []byte, contentType string, out any) error {
+	if u == "" {
+		return errors.New("No Azure URL to request")
+	}
+	r, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Ocp-Apim-Subscription-Key", b.Key)
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return b.send(r, out)
+}
+
+// get fetches u, a result file whose SAS token grants access by itself,
+// without the subscription key.
+func (b *AzureBackend) get(ctx context.Context, u string, out any) error {
+	r, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+	return b.send(r, out)
+}
+
+func (b *AzureBackend) send(r *http.Request, out any) error {
+	client := b.HTTPClient
+	if client == nil {
+		client = sharedClient
+	}
+	resp, err := client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("Reading response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return newAPIError(resp.StatusCode, respBody)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("Decoding response: %w", err)
+	}
+	return nil
+}
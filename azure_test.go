@@ -0,0 +1,101 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAzureBackend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Ocp-Apim-Subscription-Key"); got != "sub" {
+			t.Errorf("subscription key %q", got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "audio/wav; codecs=audio/pcm; samplerate=8000" {
+			t.Errorf("Content-Type %q", got)
+		}
+		q := r.URL.Query()
+		if q.Get("language") != "fr-FR" || q.Get("format") != "detailed" || q.Get("profanity") != "masked" {
+			t.Errorf("query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"RecognitionStatus": "Success", "NBest": [{"Confidence": 0.9, "Display": "Bonjour."}, {"Confidence": 0.4, "Display": "Bon jour."}]}`)
+	}))
+	defer srv.Close()
+
+	b := &AzureBackend{Key: "sub", Endpoint: srv.URL}
+	h, err := ListenFile([]byte{1, 2}, "", WithBackend(b), WithLanguages(French), WithSampleRate(8000), WithProfanityFilter(ProfanityMask))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Alternative.Transcript != "Bonjour." || h.Alternative.Confidence != 0.9 {
+		t.Errorf("hypothesis %v", h)
+	}
+}
+
+func TestAzureBackendNoMatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"RecognitionStatus": "InitialSilenceTimeout"}`)
+	}))
+	defer srv.Close()
+
+	_, err := ListenFile([]byte{1, 2}, "", WithBackend(&AzureBackend{Endpoint: srv.URL}), WithLanguages(French))
+	if !errors.Is(err, ErrNoSpeech) {
+		t.Errorf("err = %v, want ErrNoSpeech", err)
+	}
+}
+
+func TestAzureTranscribeBatch(t *testing.T) {
+	var polls, deleted int32
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("POST /transcriptions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Ocp-Apim-Subscription-Key") != "sub" {
+			t.Error("no subscription key")
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"self": "%s/transcriptions/1", "status": "NotStarted"}`, srv.URL)
+	})
+	mux.HandleFunc("GET /transcriptions/1", func(w http.ResponseWriter, r *http.Request) {
+		status := "Running"
+		if atomic.AddInt32(&polls, 1) == 2 {
+			status = "Succeeded"
+		}
+		fmt.Fprintf(w, `{"self": "%s/transcriptions/1", "status": %q, "links": {"files": "%s/transcriptions/1/files"}}`, srv.URL, status, srv.URL)
+	})
+	mux.HandleFunc("DELETE /transcriptions/1", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&deleted, 1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /transcriptions/1/files", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"values": [{"kind": "TranscriptionReport"}, {"kind": "Transcription", "links": {"contentUrl": "%s/result?sas=1"}}]}`, srv.URL)
+	})
+	mux.HandleFunc("GET /result", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Ocp-Apim-Subscription-Key") != "" {
+			t.Error("subscription key sent to the result file")
+		}
+		fmt.Fprint(w, `{"recognizedPhrases": [
+			{"channel": 0, "nBest": [{"confidence": 0.9, "display": "Hello.", "words": [{"word": "hello", "offsetInTicks": 10000000, "durationInTicks": 5000000}]}]},
+			{"channel": 1, "nBest": [{"confidence": 0.8, "display": "Hi."}]}]}`)
+	})
+
+	b := &AzureBackend{Key: "sub", BatchEndpoint: srv.URL, PollInterval: time.Millisecond}
+	gr, err := b.TranscribeBatch(context.Background(), "https://blob/audio.wav", English)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gr.Results) != 2 || gr.Results[1].Channel != 1 || gr.Results[0].Alternatives[0].Transcript != "Hello." {
+		t.Fatalf("results %+v", gr.Results)
+	}
+	if w := gr.Results[0].Alternatives[0].Words[0]; w.Start != time.Second || w.End != 1500*time.Millisecond {
+		t.Errorf("word %+v", w)
+	}
+	if polls, deleted := atomic.LoadInt32(&polls), atomic.LoadInt32(&deleted); polls != 2 || deleted != 1 {
+		t.Errorf("%d polls, %d deletions", polls, deleted)
+	}
+}
@@ -0,0 +1,158 @@
+package gorec
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// Backend recognizes audio in a single language, for recognizers other than
+// Google's, such as a self-hosted Whisper server. Its response uses the same
+// types as Google's so that alternative selection, thresholds and everything
+// built on them keep working unchanged.
+type Backend interface {
+	Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, params BackendParams) (*GoogleResponse, error)
+}
+
+// BackendParams are the settings of the Client a Backend should honour.
+type BackendParams struct {
+	ContentType     string
+	PhraseHints     []string
+	MaxAlternatives int
+	Profanity       ProfanityLevel
+}
+
+// WithBackend sends the recognition requests to b instead of Google. Rate
+// limiting, size checks and latency still apply; WithRawCapture has nothing
+// to capture and leaves Hypothesis.Raw empty.
+func WithBackend(b Backend) Option {
+	return func(c *config) { c.backend = b }
+}
+
+// GoogleBackend returns the Backend used by default, querying Google with
+// key and opts, for Backends that delegate to it to compare against or fall
+// back on Google. The Content-Type comes from the BackendParams.
+func GoogleBackend(key string, opts ...Option) Backend {
+	return googleBackend{NewClient(key, opts...)}
+}
+
+type googleBackend struct{ c *Client }
+
+func (g googleBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, params BackendParams) (*GoogleResponse, error) {
+	c := g.c.with([]Option{WithContentType(params.ContentType), WithProfanityFilter(params.Profanity)})
+	if params.MaxAlternatives > 0 {
+		c = c.with([]Option{WithMaxAlternatives(params.MaxAlternatives)})
+	}
+	raw, _, err := c.sendFile(ctx, audio, size, lang)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponse(raw)
+}
+
+func (c *Client) backendParams() BackendParams {
+	return BackendParams{ContentType: c.cfg.contentType, PhraseHints: c.cfg.phraseHints, MaxAlternatives: c.cfg.maxAlternatives, Profanity: c.cfg.profanity}
+}
+
+// fetchOnce queries the configured backend for lang, recording the latency
+// on h. raw is the response body when the backend is Google.
+func (c *Client) fetchOnce(ctx context.Context, audio io.Reader, size int64, lang Language, h *Hypothesis) (gr *GoogleResponse, raw []byte, err error) {
+	if c.cfg.limiter != nil {
+		if err := c.cfg.limiter.Wait(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+	ctx, span := c.startSpan(ctx, "gorec.request", "language", lang.StringCode())
+	defer func() {
+		span.SetAttributes("latency", h.Latency)
+		if status := c.status(err); status != 0 {
+			span.SetAttributes("status", status)
+		}
+		span.End(err)
+	}()
+	c.log(ctx, slog.LevelDebug, "sending request", "language", lang.StringCode(), "bytes", size)
+	if c.cfg.backend == nil {
+		raw, h.Latency, err = c.sendFile(ctx, audio, size, lang)
+		c.logResponse(ctx, lang, h.Latency, err)
+		if err != nil {
+			return nil, raw, err
+		}
+		gr, err = decodeResponse(raw)
+		return gr, raw, err
+	}
+	start := c.cfg.clock.Now()
+	gr, err = c.cfg.backend.Recognize(ctx, audio, size, lang, c.backendParams())
+	h.Latency = c.cfg.clock.Now().Sub(start)
+	c.logResponse(ctx, lang, h.Latency, err)
+	if err == nil && gr == nil {
+		gr = &GoogleResponse{}
+	}
+	return gr, nil, err
+}
+
+// MultiLanguageBackend is a Backend that can also recognize audio spoken in
+// any one of several languages with a single request, reporting which one
+// it heard.
+type MultiLanguageBackend interface {
+	Backend
+	RecognizeAny(ctx context.Context, audio io.Reader, size int64, langs []Language, params BackendParams) (*GoogleResponse, Language, error)
+}
+
+// WithSingleRequestMultiLang sends one request for all the queried languages
+// instead of one per language, when the Backend set with WithBackend is a
+// MultiLanguageBackend. Google's endpoint has no such mode, so with it, or
+// any other Backend, the languages are still queried one request each.
+func WithSingleRequestMultiLang(single bool) Option {
+	return func(c *config) { c.singleRequest = single }
+}
+
+func (c *Client) multiLanguageBackend() (MultiLanguageBackend, bool) {
+	if !c.cfg.singleRequest {
+		return nil, false
+	}
+	mb, ok := c.cfg.backend.(MultiLanguageBackend)
+	return mb, ok
+}
+
+// listenMulti queries all of languages with a single request to mb and
+// returns the hypothesis for the language it reports, none if deadline
+// passes first. The request is bounded by WithRequestTimeout like any other.
+func (c *Client) listenMulti(ctx context.Context, mb MultiLanguageBackend, r io.ReaderAt, size int64, languages []Language, fn func(Hypothesis), deadline <-chan time.Time) []Hypothesis {
+	ch := make(chan Hypothesis, 1)
+	go func() {
+		h := Hypothesis{Language: languages[0]}
+		h.Err = c.recognizeAny(ctx, mb, r, size, languages, &h)
+		ch <- h
+	}()
+	return c.gather(ctx, ch, 1, fn, deadline)
+}
+
+// recognizeAny is recognize for a MultiLanguageBackend, setting h.Language
+// to the language it answered in.
+func (c *Client) recognizeAny(ctx context.Context, mb MultiLanguageBackend, r io.ReaderAt, size int64, languages []Language, h *Hypothesis) error {
+	if c.cfg.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.requestTimeout)
+		defer cancel()
+	}
+	if c.cfg.limiter != nil {
+		if err := c.cfg.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	start := c.cfg.clock.Now()
+	gr, lang, err := mb.RecognizeAny(ctx, io.NewSectionReader(r, 0, size), size, languages, c.backendParams())
+	h.Latency = c.cfg.clock.Now().Sub(start)
+	if err == nil {
+		h.Language = lang
+		if gr == nil {
+			gr = &GoogleResponse{}
+		}
+		err = c.interpret(ctx, h, gr, nil)
+	}
+	if c.cfg.metrics != nil {
+		c.cfg.metrics.observe(h, err)
+	}
+	return err
+}
@@ -0,0 +1,236 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ClipError reports the clip a ListenClips call stopped at.
+type ClipError struct {
+	Index int
+	Err   error
+}
+
+func (e *ClipError) Error() string { return fmt.Sprintf("Clip %d: %v", e.Index, e.Err) }
+func (e *ClipError) Unwrap() error { return e.Err }
+
+func ListenClips(r io.Reader, clipSize int, key string, opts ...Option) ([]Hypothesis, error) {
+	return NewClient(key, opts...).ListenClips(r, clipSize)
+}
+
+func ListenClipsContext(ctx context.Context, r io.Reader, clipSize int, key string, opts ...Option) ([]Hypothesis, error) {
+	return NewClient(key, opts...).ListenClipsContext(ctx, r, clipSize)
+}
+
+// ListenClips splits r into consecutive clips of clipSize bytes and
+// recognizes each one in turn. A shorter last clip is recognized as well.
+// On failure it returns the hypotheses of the clips before the failing one
+// along with a *ClipError.
+func (c *Client) ListenClips(r io.Reader, clipSize int, opts ...Option) ([]Hypothesis, error) {
+	return c.ListenClipsContext(context.Background(), r, clipSize, opts...)
+}
+
+// ListenClipsContext is ListenClips stopping before the next clip, and
+// abandoning the requests of the current one, once ctx is done.
+func (c *Client) ListenClipsContext(ctx context.Context, r io.Reader, clipSize int, opts ...Option) ([]Hypothesis, error) {
+	c = c.with(opts)
+	hs, err := c.listenClips(ctx, r, clipSize, -1)
+	c.completed(err)
+	return hs, err
+}
+
+// listenClips is ListenClipsContext reporting total clips to the Hooks.
+func (c *Client) listenClips(ctx context.Context, r io.Reader, clipSize, total int) ([]Hypothesis, error) {
+	if clipSize <= 0 {
+		return nil, fmt.Errorf("Invalid clip size %d", clipSize)
+	}
+	var hs []Hypothesis
+	buf := make([]byte, clipSize)
+	for i := 0; ; i++ {
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF {
+			return hs, nil
+		}
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return hs, &ClipError{Index: i, Err: err}
+		}
+		if err := ctx.Err(); err != nil {
+			return hs, &ClipError{Index: i, Err: err}
+		}
+		c.chunkStarted(i, total)
+		h, herr := c.listen(ctx, buf[:n])
+		if herr != nil {
+			c.chunkDone(i, total, Hypothesis{Err: herr})
+			return hs, &ClipError{Index: i, Err: herr}
+		}
+		c.chunkDone(i, total, *h)
+		hs = append(hs, *h)
+		if err != nil {
+			return hs, nil
+		}
+	}
+}
+
+func RecognizeBatch(ctx context.Context, paths []string, workers int, key string, opts ...Option) (map[string]Hypothesis, error) {
+	return NewClient(key, opts...).RecognizeBatch(ctx, paths, workers)
+}
+
+// RecognizeBatch reads and recognizes the audio files at paths, at most
+// workers at a time. Files that fail are reported with Err set. Cancelling
+// ctx stops new files from being started and aborts those in flight; once
+// they have returned, RecognizeBatch returns the files that did complete
+// along with ctx.Err().
+func (c *Client) RecognizeBatch(ctx context.Context, paths []string, workers int, opts ...Option) (map[string]Hypothesis, error) {
+	c = c.with(opts)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	var mu sync.Mutex
+	results := make(map[string]Hypothesis, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				c.chunkStarted(i, len(paths))
+				h, err := c.recognizeFile(ctx, paths[i])
+				if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+					continue
+				}
+				c.chunkDone(i, len(paths), h)
+				mu.Lock()
+				results[paths[i]] = h
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for i := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	c.completed(ctx.Err())
+	return results, ctx.Err()
+}
+
+func (c *Client) recognizeFile(ctx context.Context, path string) (Hypothesis, error) {
+	audio, err := ReadAudioFileContext(ctx, path)
+	if err != nil {
+		return Hypothesis{Err: err, Source: path}, err
+	}
+	h, err := c.listen(ctx, audio)
+	if err != nil {
+		return Hypothesis{Err: err, Source: path}, err
+	}
+	h.Source = path
+	return *h, nil
+}
+
+// Job is a clip for RecognizeAll to recognize: Audio, with the Client's
+// options then Options. Name, such as the file the audio came from, is set
+// as the Source of its hypothesis.
+type Job struct {
+	Name    string
+	Audio   []byte
+	Options []Option
+}
+
+// JobResult is how the Job at Index of a RecognizeAll call fared: its
+// Hypothesis or, if it failed or was never started, Err.
+type JobResult struct {
+	Index      int
+	Job        Job
+	Hypothesis Hypothesis
+	Err        error
+}
+
+// JobErrors joins the errors of the results that failed, each as a
+// *ClipError holding its index, or returns nil if none did.
+func JobErrors(results []JobResult) error {
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, &ClipError{Index: r.Index, Err: r.Err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func RecognizeAll(ctx context.Context, jobs []Job, workers int, key string, opts ...Option) []JobResult {
+	return NewClient(key, opts...).RecognizeAll(ctx, jobs, workers)
+}
+
+// RecognizeAll recognizes jobs on a pool of at most workers at a time,
+// returning a result per job in the order of jobs; JobErrors sums up those
+// that failed. The requests of every worker wait on the Client's
+// WithLimiter, so one Limiter caps the rate of the whole pool. Cancelling
+// ctx aborts the jobs in flight and fails those not yet started with
+// ctx.Err().
+func (c *Client) RecognizeAll(ctx context.Context, jobs []Job, workers int, opts ...Option) []JobResult {
+	c = c.with(opts)
+	if workers <= 0 {
+		workers = 1
+	}
+	results := make([]JobResult, len(jobs))
+	for i, job := range jobs {
+		results[i] = JobResult{Index: i, Job: job}
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(jobs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i].Hypothesis, results[i].Err = c.recognizeJob(ctx, i, len(jobs), jobs[i])
+			}
+		}()
+	}
+	started := 0
+feed:
+	for ; started < len(jobs); started++ {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case next <- started:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+	for i := started; i < len(jobs); i++ {
+		results[i].Hypothesis = Hypothesis{Err: ctx.Err(), Source: jobs[i].Name}
+		results[i].Err = ctx.Err()
+	}
+	c.completed(JobErrors(results))
+	return results
+}
+
+func (c *Client) recognizeJob(ctx context.Context, i, total int, job Job) (Hypothesis, error) {
+	c.chunkStarted(i, total)
+	h, err := c.with(job.Options).listen(ctx, job.Audio)
+	if err != nil {
+		h = &Hypothesis{Err: err}
+	}
+	h.Source = job.Name
+	c.chunkDone(i, total, *h)
+	return *h, err
+}
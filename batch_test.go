@@ -0,0 +1,117 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingLimiter counts the requests that waited on it.
+type countingLimiter struct{ waits int32 }
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	atomic.AddInt32(&l.waits, 1)
+	return ctx.Err()
+}
+
+func TestRecognizeAll(t *testing.T) {
+	jobs := []Job{
+		{Name: "a", Audio: []byte("first")},
+		{Name: "b", Audio: []byte("fail")},
+		{Name: "c", Audio: []byte("third"), Options: []Option{WithNormalizer(Capitalize)}},
+		{Name: "d", Audio: []byte("fourth")},
+	}
+	l := &countingLimiter{}
+	c := NewClient("k", WithBackend(&echoBackend{}), WithLanguages(English), WithLimiter(l))
+	results := c.RecognizeAll(context.Background(), jobs, 3)
+	if len(results) != len(jobs) {
+		t.Fatalf("got %d results", len(results))
+	}
+	for i, want := range []string{"first", "", "Third", "fourth"} {
+		r := results[i]
+		if r.Index != i || r.Job.Name != jobs[i].Name || r.Hypothesis.Source != jobs[i].Name {
+			t.Errorf("result %d = %+v", i, r)
+		}
+		if r.Hypothesis.Alternative.Transcript != want {
+			t.Errorf("result %d transcript = %q, want %q", i, r.Hypothesis.Alternative.Transcript, want)
+		}
+	}
+	if results[1].Err == nil || results[1].Hypothesis.Err != results[1].Err {
+		t.Errorf("failed job = %+v", results[1])
+	}
+	var clipErr *ClipError
+	if err := JobErrors(results); !errors.As(err, &clipErr) || clipErr.Index != 1 {
+		t.Errorf("JobErrors = %v", err)
+	}
+	if atomic.LoadInt32(&l.waits) != int32(len(jobs)) {
+		t.Errorf("limiter waited %d times", l.waits)
+	}
+	if err := JobErrors(results[2:]); err != nil {
+		t.Errorf("JobErrors of successes = %v", err)
+	}
+}
+
+// gateBackend blocks every request until release is closed, tracking how
+// many are in flight at once.
+type gateBackend struct {
+	release           chan struct{}
+	inFlight, maxSeen int32
+}
+
+func (b *gateBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	n := atomic.AddInt32(&b.inFlight, 1)
+	defer atomic.AddInt32(&b.inFlight, -1)
+	for {
+		seen := atomic.LoadInt32(&b.maxSeen)
+		if n <= seen || atomic.CompareAndSwapInt32(&b.maxSeen, seen, n) {
+			break
+		}
+	}
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{{Transcript: "ok", Confidence: 0.9}}, Final: true}}}, nil
+}
+
+func TestRecognizeAllBoundsWorkers(t *testing.T) {
+	b := &gateBackend{release: make(chan struct{})}
+	jobs := make([]Job, 10)
+	for i := range jobs {
+		jobs[i] = Job{Name: fmt.Sprint(i), Audio: []byte{0, 0}}
+	}
+	done := make(chan []JobResult)
+	go func() {
+		done <- RecognizeAll(context.Background(), jobs, 3, "k", WithBackend(b), WithLanguages(English))
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(b.release)
+	results := <-done
+	if err := JobErrors(results); err != nil {
+		t.Fatal(err)
+	}
+	if b.maxSeen != 3 {
+		t.Errorf("%d requests in flight at once, want 3", b.maxSeen)
+	}
+}
+
+func TestRecognizeAllCancel(t *testing.T) {
+	b := &gateBackend{release: make(chan struct{})}
+	jobs := make([]Job, 5)
+	for i := range jobs {
+		jobs[i] = Job{Audio: []byte{0, 0}}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	results := NewClient("k", WithBackend(b), WithLanguages(English)).RecognizeAll(ctx, jobs, 2)
+	for i, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("job %d err = %v", i, r.Err)
+		}
+	}
+}
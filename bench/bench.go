@@ -0,0 +1,185 @@
+// Package bench measures how accurately and how fast gorec clients, each
+// perhaps with another Backend, recognize a labeled corpus, scoring their
+// transcripts by word and character error rate per language.
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/carlescere/gorec"
+)
+
+// Sample is a recording of the corpus with what was said in it.
+type Sample struct {
+	Path      string
+	Language  gorec.Language
+	Reference string
+}
+
+// audioExtensions are the recordings LoadCorpus picks.
+var audioExtensions = map[string]bool{".wav": true, ".flac": true, ".raw": true, ".pcm": true, ".l16": true}
+
+// LoadCorpus reads the corpus under dir, laid out as a directory per
+// language named by its code, such as fr-FR, holding the recordings, each
+// with its reference transcript in a text file of the same name:
+//
+//	corpus/fr-FR/greeting.wav
+//	corpus/fr-FR/greeting.txt
+func LoadCorpus(dir string) ([]Sample, error) {
+	var samples []Sample
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !audioExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		code, _, ok := strings.Cut(filepath.ToSlash(rel), "/")
+		if !ok {
+			return fmt.Errorf("%s is in no language directory", rel)
+		}
+		lang, err := gorec.ParseLanguage(code)
+		if err != nil {
+			return fmt.Errorf("Directory of %s: %w", rel, err)
+		}
+		ref, err := os.ReadFile(strings.TrimSuffix(path, filepath.Ext(path)) + ".txt")
+		if err != nil {
+			return fmt.Errorf("No reference for %s: %w", rel, err)
+		}
+		samples = append(samples, Sample{Path: path, Language: lang, Reference: strings.TrimSpace(string(ref))})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("No recordings under %s", dir)
+	}
+	return samples, nil
+}
+
+// System is a configuration to benchmark, such as a Client for Google's
+// v2 endpoint or one using a WhisperBackend.
+type System struct {
+	Name   string
+	Client *gorec.Client
+}
+
+// Result is how a System did on the samples of a language. WER and CER
+// are the edits needed to turn the transcripts into the references over
+// the words, or characters, of the references, the recognitions that
+// failed left out. Hearing no speech is no failure: it scores an empty
+// transcript.
+type Result struct {
+	System   string
+	Language gorec.Language
+	Samples  int
+	Failures int
+	WER, CER float64
+	// MeanLatency and P95Latency are the mean and 95th percentile of how
+	// long the recognitions took.
+	MeanLatency time.Duration
+	P95Latency  time.Duration
+}
+
+// Run recognizes every sample in its language with each system in turn,
+// one sample at a time, so that latencies compare. The results are by
+// system, in the order given, then by language code.
+func Run(ctx context.Context, samples []Sample, systems ...System) ([]Result, error) {
+	var results []Result
+	for _, sys := range systems {
+		type tally struct {
+			samples, failures int
+			wordEdits, words  int
+			charEdits, chars  int
+			latencies         []time.Duration
+		}
+		tallies := make(map[gorec.Language]*tally)
+		for _, s := range samples {
+			audio, err := gorec.ReadAudioFileContext(ctx, s.Path)
+			if err != nil {
+				return nil, err
+			}
+			t := tallies[s.Language]
+			if t == nil {
+				t = &tally{}
+				tallies[s.Language] = t
+			}
+			t.samples++
+			start := time.Now()
+			h, err := sys.Client.RecognizeContext(ctx, audio, s.Language)
+			elapsed := time.Since(start)
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			var transcript string
+			switch {
+			case err == nil:
+				transcript = h.Alternative.Transcript
+			case !errors.Is(err, gorec.ErrNoSpeech):
+				t.failures++
+				continue
+			}
+			t.latencies = append(t.latencies, elapsed)
+			edits, n := wordEdits(s.Reference, transcript)
+			t.wordEdits, t.words = t.wordEdits+edits, t.words+n
+			edits, n = charEdits(s.Reference, transcript)
+			t.charEdits, t.chars = t.charEdits+edits, t.chars+n
+		}
+		var byLang []Result
+		for lang, t := range tallies {
+			r := Result{System: sys.Name, Language: lang, Samples: t.samples, Failures: t.failures}
+			r.WER, r.CER = rate(t.wordEdits, t.words), rate(t.charEdits, t.chars)
+			r.MeanLatency, r.P95Latency = latencyStats(t.latencies)
+			byLang = append(byLang, r)
+		}
+		sort.Slice(byLang, func(i, j int) bool { return byLang[i].Language.StringCode() < byLang[j].Language.StringCode() })
+		results = append(results, byLang...)
+	}
+	return results, nil
+}
+
+func rate(edits, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return float64(edits) / float64(n)
+}
+
+func latencyStats(latencies []time.Duration) (mean, p95 time.Duration) {
+	if len(latencies) == 0 {
+		return 0, 0
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+	return total / time.Duration(len(sorted)), sorted[(len(sorted)*95+99)/100-1]
+}
+
+// WriteReport writes results to w as an aligned table.
+func WriteReport(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SYSTEM\tLANGUAGE\tSAMPLES\tFAILED\tWER\tCER\tMEAN LATENCY\tP95 LATENCY")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%.1f%%\t%.1f%%\t%s\t%s\n", r.System, r.Language.Code(), r.Samples, r.Failures,
+			100*r.WER, 100*r.CER, r.MeanLatency.Round(time.Millisecond), r.P95Latency.Round(time.Millisecond))
+	}
+	return tw.Flush()
+}
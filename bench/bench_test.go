@@ -0,0 +1,123 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/carlescere/gorec"
+)
+
+func TestWER(t *testing.T) {
+	for _, c := range []struct {
+		ref, hyp string
+		wer, cer float64
+	}{
+		{"Hello, world!", "hello world", 0, 0},
+		{"the cat sat", "the cat sat down", 1.0 / 3, 5.0 / 11},
+		{"the cat sat", "a cat", 2.0 / 3, 7.0 / 11},
+		{"don't stop", "dont stop", 0.5, 0.1},
+		{"", "anything", 0, 0},
+		{"bonjour", "", 1, 1},
+	} {
+		if got := WER(c.ref, c.hyp); math.Abs(got-c.wer) > 1e-9 {
+			t.Errorf("WER(%q, %q) = %v, want %v", c.ref, c.hyp, got, c.wer)
+		}
+		if got := CER(c.ref, c.hyp); math.Abs(got-c.cer) > 1e-9 {
+			t.Errorf("CER(%q, %q) = %v, want %v", c.ref, c.hyp, got, c.cer)
+		}
+	}
+}
+
+// loud returns a second of 16 kHz PCM loud enough to count as speech.
+func loud() []byte {
+	pcm := make([]byte, 32000)
+	for i := 0; i < len(pcm); i += 2 {
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(int16(2000*(i/2%2*2-1))))
+	}
+	return pcm
+}
+
+// frenchBackend hears "bonjour le monde" in French and nothing else.
+type frenchBackend struct{}
+
+func (frenchBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang gorec.Language, params gorec.BackendParams) (*gorec.GoogleResponse, error) {
+	if lang != gorec.French {
+		return &gorec.GoogleResponse{}, nil
+	}
+	return &gorec.GoogleResponse{Results: []gorec.Result{{
+		Alternatives: []gorec.Alternative{{Transcript: "bonjour le monde", Confidence: 0.9}},
+		Final:        true,
+	}}}, nil
+}
+
+func writeCorpus(t *testing.T) string {
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"fr-FR/a.raw": string(loud()),
+		"fr-FR/a.txt": "Bonjour tout le monde.\n",
+		"fr-FR/b.raw": string(loud()),
+		"fr-FR/b.txt": "bonjour le monde",
+		"en/c.raw":    string(loud()),
+		"en/c.txt":    "hello",
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	samples, err := LoadCorpus(writeCorpus(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 3 {
+		t.Fatalf("loaded %d samples", len(samples))
+	}
+	results, err := Run(context.Background(), samples, System{Name: "french", Client: gorec.NewClient("k", gorec.WithBackend(frenchBackend{}))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results %+v", results)
+	}
+	en, fr := results[0], results[1]
+	if en.Language != gorec.English || en.Samples != 1 || en.Failures != 0 || en.WER != 1 {
+		t.Errorf("English %+v", en)
+	}
+	if fr.Language != gorec.French || fr.Samples != 2 || math.Abs(fr.WER-1.0/7) > 1e-9 || fr.MeanLatency <= 0 || fr.P95Latency < fr.MeanLatency {
+		t.Errorf("French %+v", fr)
+	}
+	var report bytes.Buffer
+	if err := WriteReport(&report, results); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(report.String()), "\n"); len(lines) != 3 || !strings.Contains(lines[2], "fr-FR") || !strings.Contains(lines[2], "14.3%") {
+		t.Errorf("report %q", report.String())
+	}
+}
+
+func TestLoadCorpusErrors(t *testing.T) {
+	dir := writeCorpus(t)
+	if err := os.Remove(filepath.Join(dir, "en", "c.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCorpus(dir); err == nil {
+		t.Error("loaded a recording without a reference")
+	}
+	if _, err := LoadCorpus(t.TempDir()); err == nil {
+		t.Error("loaded an empty corpus")
+	}
+}
@@ -0,0 +1,71 @@
+package bench
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WER is the word error rate of hypothesis against reference: the words
+// substituted, inserted and deleted over the words of reference. Case and
+// punctuation are ignored.
+func WER(reference, hypothesis string) float64 {
+	return rate(wordEdits(reference, hypothesis))
+}
+
+// CER is WER by characters, the spaces between words included.
+func CER(reference, hypothesis string) float64 {
+	return rate(charEdits(reference, hypothesis))
+}
+
+// wordEdits returns the edits turning hypothesis into reference word by
+// word, and how many words reference has.
+func wordEdits(reference, hypothesis string) (edits, n int) {
+	ref, hyp := words(reference), words(hypothesis)
+	return editDistance(ref, hyp), len(ref)
+}
+
+// charEdits is wordEdits by characters.
+func charEdits(reference, hypothesis string) (edits, n int) {
+	ref := []rune(strings.Join(words(reference), " "))
+	hyp := []rune(strings.Join(words(hypothesis), " "))
+	return editDistance(ref, hyp), len(ref)
+}
+
+// words splits s into lowercase words, leaving out punctuation but for the
+// apostrophes within words, as in "don't".
+func words(s string) []string {
+	var out []string
+	for _, f := range strings.Fields(strings.ToLower(s)) {
+		f = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsNumber(r) || r == '\'' || r == '’' {
+				return r
+			}
+			return -1
+		}, f)
+		if f = strings.Trim(f, "'’"); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance[T comparable](a, b []T) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range a {
+		cur[0] = i + 1
+		for j := range b {
+			cost := 1
+			if a[i] == b[j] {
+				cost = 0
+			}
+			cur[j+1] = min(prev[j]+cost, prev[j+1]+1, cur[j]+1)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,76 @@
+package gorec
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// drainingBackend reads the whole audio of every request, as sending it
+// would, and answers each language alike.
+type drainingBackend struct{}
+
+func (drainingBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	if _, err := io.Copy(io.Discard, audio); err != nil {
+		return nil, err
+	}
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{{Transcript: "hi", Confidence: 0.5}}, Final: true}}}, nil
+}
+
+// benchmarkFile writes 8 MiB of audio to a temporary file.
+func benchmarkFile(b *testing.B) string {
+	path := filepath.Join(b.TempDir(), "large.raw")
+	if err := os.WriteFile(path, make([]byte, 8<<20), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	return path
+}
+
+func BenchmarkReadAudioFileListenFile(b *testing.B) {
+	path := benchmarkFile(b)
+	c := NewClient("k", WithBackend(drainingBackend{}))
+	defer c.Close()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		audio, err := ReadAudioFile(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := c.ListenFile(audio); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListenReaderAt(b *testing.B) {
+	path := benchmarkFile(b)
+	c := NewClient("k", WithBackend(drainingBackend{}))
+	defer c.Close()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f, err := os.Open(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		info, _ := f.Stat()
+		if _, err := c.ListenReaderAt(f, info.Size()); err != nil {
+			b.Fatal(err)
+		}
+		f.Close()
+	}
+}
+
+func BenchmarkListenPath(b *testing.B) {
+	path := benchmarkFile(b)
+	c := NewClient("k", WithBackend(drainingBackend{}))
+	defer c.Close()
+	b.SetBytes(8 << 20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.ListenPath(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
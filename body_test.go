@@ -0,0 +1,49 @@
+package gorec
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var errBodyAborted = errors.New("connection reset")
+
+// failingBody yields part of a response, then fails.
+type failingBody struct{ r io.Reader }
+
+func (b *failingBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err == io.EOF {
+		return n, errBodyAborted
+	}
+	return n, err
+}
+
+func (b *failingBody) Close() error { return nil }
+
+func TestBodyReadError(t *testing.T) {
+	client := &http.Client{Transport: rtFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: &failingBody{strings.NewReader(`{"result":[`)}}, nil
+	})}
+	_, err := Recognize([]byte{1, 2}, "k", English, WithHTTPClient(client))
+	if !errors.Is(err, errBodyAborted) || !strings.HasPrefix(err.Error(), "Reading response") {
+		t.Errorf("Recognize = %v, want a read error wrapping errBodyAborted", err)
+	}
+}
+
+func TestBodyTruncated(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte(`{"result":[`))
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer srv.Close()
+	_, err := Recognize([]byte{1, 2}, "k", English, WithLanguageEndpoint(map[Language]string{English: srv.URL + "/?lang=%s&key=%s"}))
+	if err == nil || !strings.Contains(err.Error(), "Reading response") {
+		t.Errorf("Recognize = %v, want a read error", err)
+	}
+}
@@ -0,0 +1,113 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// Breaker is a circuit breaker around Backend. After Threshold consecutive
+// failures, or any error wrapping ErrQuotaExceeded, it opens for Cooldown:
+// meanwhile requests go to Fallback if set, or fail at once with
+// ErrCircuitOpen, rather than each waiting for a backend that is down to
+// time out. Once the cooldown is over the next failure opens it again,
+// and a success closes it.
+//
+// Requests cancelled by their caller and audio the backend does not take
+// count neither as failures nor as successes. A Breaker is shared by every
+// language, and Clients, using it.
+type Breaker struct {
+	Backend  Backend
+	Fallback Backend
+
+	// Threshold defaults to 5 failures and Cooldown to 30 seconds.
+	Threshold int
+	Cooldown  time.Duration
+
+	// OnStateChange, if set, is called with true when the breaker opens
+	// and false when it closes again.
+	OnStateChange func(open bool)
+
+	clock clock
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+	until    time.Time
+}
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+func (b *Breaker) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, params BackendParams) (*GoogleResponse, error) {
+	if !b.allow() {
+		if b.Fallback != nil {
+			return b.Fallback.Recognize(ctx, audio, size, lang, params)
+		}
+		return nil, ErrCircuitOpen
+	}
+	gr, err := b.Backend.Recognize(ctx, audio, size, lang, params)
+	switch {
+	case ctx.Err() != nil, errors.Is(err, ErrUnsupportedFormat):
+	case err != nil:
+		b.failed(errors.Is(err, ErrQuotaExceeded))
+	default:
+		b.succeeded()
+	}
+	return gr, err
+}
+
+// Open reports whether the breaker is open, sending requests to Fallback.
+func (b *Breaker) Open() bool {
+	return !b.allow()
+}
+
+// allow reports whether a request may go to Backend.
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.open || !b.now().Before(b.until)
+}
+
+func (b *Breaker) failed(quota bool) {
+	b.mu.Lock()
+	b.failures++
+	threshold := b.Threshold
+	if threshold <= 0 {
+		threshold = defaultBreakerThreshold
+	}
+	opened := quota || b.failures >= threshold || b.open
+	wasOpen := b.open
+	if opened {
+		cooldown := b.Cooldown
+		if cooldown <= 0 {
+			cooldown = defaultBreakerCooldown
+		}
+		b.open, b.until = true, b.now().Add(cooldown)
+	}
+	b.mu.Unlock()
+	if opened && !wasOpen && b.OnStateChange != nil {
+		b.OnStateChange(true)
+	}
+}
+
+func (b *Breaker) succeeded() {
+	b.mu.Lock()
+	wasOpen := b.open
+	b.failures, b.open = 0, false
+	b.mu.Unlock()
+	if wasOpen && b.OnStateChange != nil {
+		b.OnStateChange(false)
+	}
+}
+
+func (b *Breaker) now() time.Time {
+	if b.clock == nil {
+		return time.Now()
+	}
+	return b.clock.Now()
+}
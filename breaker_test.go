@@ -0,0 +1,89 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// scriptedBackend fails with err while it is set, counting its calls.
+type scriptedBackend struct {
+	err   error
+	calls int
+}
+
+func (b *scriptedBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	b.calls++
+	if b.err != nil {
+		return nil, b.err
+	}
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{{Transcript: "primary", Confidence: 0.9}}, Final: true}}}, nil
+}
+
+func TestBreaker(t *testing.T) {
+	clk := newFakeClock()
+	primary := &scriptedBackend{err: errors.New("Down")}
+	var changes []bool
+	b := &Breaker{Backend: primary, Threshold: 2, Cooldown: time.Minute, clock: clk, OnStateChange: func(open bool) { changes = append(changes, open) }}
+	ctx := context.Background()
+	recognize := func() error {
+		_, err := b.Recognize(ctx, nil, 0, English, BackendParams{})
+		return err
+	}
+	recognize()
+	if b.Open() {
+		t.Fatal("opened after a single failure")
+	}
+	recognize()
+	if !b.Open() {
+		t.Fatal("still closed after Threshold failures")
+	}
+	if err := recognize(); !errors.Is(err, ErrCircuitOpen) || primary.calls != 2 {
+		t.Fatalf("open breaker returned %v after %d calls", err, primary.calls)
+	}
+
+	// A failure after the cooldown opens it again at once.
+	clk.Advance(time.Minute)
+	if b.Open() {
+		t.Fatal("still open after the cooldown")
+	}
+	recognize()
+	if !b.Open() || primary.calls != 3 {
+		t.Fatalf("open %v after %d calls", b.Open(), primary.calls)
+	}
+
+	clk.Advance(time.Minute)
+	primary.err = nil
+	if err := recognize(); err != nil || b.Open() {
+		t.Fatalf("recovered backend returned %v, open %v", err, b.Open())
+	}
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("state changes = %v", changes)
+	}
+}
+
+func TestBreakerQuotaAndFallback(t *testing.T) {
+	primary := &scriptedBackend{err: newAPIError(429, []byte(`{"error":{"status":"RESOURCE_EXHAUSTED"}}`))}
+	b := &Breaker{Backend: primary, Fallback: &echoBackend{}, clock: newFakeClock()}
+	c := NewClient("k", WithBackend(b), WithLanguages(English))
+	if _, err := c.ListenFile([]byte("hi")); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("err = %v, want ErrQuotaExceeded", err)
+	}
+	h, err := c.ListenFile([]byte("hi"))
+	if err != nil || h.Alternative.Transcript != "hi" || primary.calls != 1 {
+		t.Errorf("fallback = %v, %v after %d calls", h, err, primary.calls)
+	}
+}
+
+func TestBreakerIgnoresCancellation(t *testing.T) {
+	primary := &scriptedBackend{err: context.Canceled}
+	b := &Breaker{Backend: primary, Threshold: 1}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.Recognize(ctx, nil, 0, English, BackendParams{})
+	if b.Open() {
+		t.Error("a cancelled request opened the breaker")
+	}
+}
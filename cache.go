@@ -0,0 +1,96 @@
+package gorec
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// Cache stores the hypotheses recognized from audio, so that the same clip
+// recognized again is answered without any request. Keys are hex SHA-256
+// digests of the audio as sent, its content type and the languages queried.
+// Other options, such as WithConfidenceThreshold, are not part of the key:
+// Clients sharing a Cache should be configured alike. An implementation
+// backed by a shared store, such as Redis, may serialize hypotheses as JSON.
+type Cache interface {
+	Get(ctx context.Context, key string) (Hypothesis, bool)
+	Set(ctx context.Context, key string, h Hypothesis)
+}
+
+// WithCache answers from c the audio recognized before and stores there
+// what is newly recognized. Failures and Partial hypotheses are not cached.
+func WithCache(c Cache) Option {
+	return func(cfg *config) { cfg.cache = c }
+}
+
+// cacheKey is the key of audio, as sent, in a Cache.
+func (c *Client) cacheKey(audio []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(c.cfg.contentType))
+	for _, lang := range c.languages() {
+		sum.Write([]byte{0})
+		sum.Write([]byte(lang.StringCode()))
+	}
+	if c.cfg.translator != nil {
+		sum.Write([]byte("\x00translated to " + c.cfg.translateTo.StringCode()))
+	}
+	sum.Write([]byte{0})
+	sum.Write(audio)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// LRUCache is an in-memory Cache holding the most recently used hypotheses.
+// Safe for concurrent use.
+type LRUCache struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key string
+	h   Hypothesis
+}
+
+// NewLRUCache returns an LRUCache holding up to size hypotheses.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (l *LRUCache) Get(ctx context.Context, key string) (Hypothesis, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.entries[key]
+	if !ok {
+		return Hypothesis{}, false
+	}
+	l.order.MoveToFront(e)
+	return e.Value.(*lruEntry).h, true
+}
+
+func (l *LRUCache) Set(ctx context.Context, key string, h Hypothesis) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.entries[key]; ok {
+		e.Value.(*lruEntry).h = h
+		l.order.MoveToFront(e)
+		return
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key, h})
+	for l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns how many hypotheses l holds.
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
@@ -0,0 +1,51 @@
+package gorec
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	b := &echoBackend{}
+	cache := NewLRUCache(2)
+	c := NewClient("k", WithBackend(b), WithLanguages(English), WithCache(cache))
+	for i := 0; i < 3; i++ {
+		h, err := c.ListenFile([]byte("hello"))
+		if err != nil || h.Alternative.Transcript != "hello" {
+			t.Fatalf("ListenFile = %v, %v", h, err)
+		}
+	}
+	if b.calls != 1 {
+		t.Errorf("repeated clip made %d requests, want 1", b.calls)
+	}
+	if _, err := c.ListenFile([]byte("hello"), WithLanguages(English, French)); err != nil {
+		t.Fatal(err)
+	}
+	if b.calls != 3 {
+		t.Errorf("other languages made %d requests in all, want 3", b.calls)
+	}
+	if _, err := c.ListenFile([]byte("fail")); err == nil {
+		t.Fatal("ListenFile of a failing clip succeeded")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("cache holds %d hypotheses, want 2", cache.Len())
+	}
+}
+
+func TestLRUCacheEvicts(t *testing.T) {
+	ctx := context.Background()
+	l := NewLRUCache(2)
+	l.Set(ctx, "a", Hypothesis{Language: English})
+	l.Set(ctx, "b", Hypothesis{Language: French})
+	l.Get(ctx, "a")
+	l.Set(ctx, "c", Hypothesis{Language: Spanish})
+	if _, ok := l.Get(ctx, "b"); ok {
+		t.Error("least recently used entry kept")
+	}
+	if h, ok := l.Get(ctx, "a"); !ok || h.Language != English {
+		t.Errorf("Get(a) = %v, %v", h, ok)
+	}
+	if l.Len() != 2 {
+		t.Errorf("Len = %d", l.Len())
+	}
+}
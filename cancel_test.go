@@ -0,0 +1,150 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newBlockingServer answers French at once and holds every other request
+// until the client abandons it, reporting each abandoned request on
+// cancelled.
+func newBlockingServer(cancelled chan<- string) (*httptest.Server, map[Language]string) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := r.URL.Query().Get("lang")
+		if lang == "fr-fr" {
+			fmt.Fprint(w, `{"result":[{"alternative":[{"transcript":"bonjour","confidence":0.9}],"final":true}],"result_index":0}`)
+			return
+		}
+		// The server notices the client going away once the body is read.
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+		cancelled <- lang
+	}))
+	eps := map[Language]string{}
+	for _, l := range SupportedLanguages() {
+		eps[l] = srv.URL + "/?lang=%s&key=%s"
+	}
+	return srv, eps
+}
+
+func waitCancelled(t *testing.T, cancelled <-chan string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-cancelled:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d of %d in-flight requests were aborted", i, n)
+		}
+	}
+}
+
+func TestThresholdAbortsLosers(t *testing.T) {
+	cancelled := make(chan string, 6)
+	srv, eps := newBlockingServer(cancelled)
+	defer srv.Close()
+	h, err := ListenFile([]byte{1, 2}, "k", WithLanguageEndpoint(eps), WithConfidenceThreshold(0.8))
+	if err != nil || h.Language != French {
+		t.Fatal(h, err)
+	}
+	waitCancelled(t, cancelled, 5)
+}
+
+func TestCancelAbortsInFlight(t *testing.T) {
+	cancelled := make(chan string, 6)
+	srv, eps := newBlockingServer(cancelled)
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := ListenFileContext(ctx, []byte{1, 2}, "k", WithLanguageEndpoint(eps), WithLanguages(English, Spanish))
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("ListenFileContext = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ListenFileContext didn't return once ctx was cancelled")
+	}
+	waitCancelled(t, cancelled, 2)
+}
+
+// stallingBackend holds every request until it is cancelled, counting
+// those it was sent.
+type stallingBackend struct{ calls int32 }
+
+func (b *stallingBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	atomic.AddInt32(&b.calls, 1)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestTimeoutLeavesNoGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	b := &stallingBackend{}
+	tracer := &recordingTracer{}
+	_, err := ListenFile([]byte{1, 2}, "k", WithBackend(b), WithTimeout(20*time.Millisecond), WithMaxConcurrency(2), WithTracer(tracer))
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines left behind", n-before)
+	}
+	tracer.mu.Lock()
+	spans := 0
+	for _, s := range tracer.spans {
+		if s.name == "gorec.language" {
+			spans++
+		}
+	}
+	tracer.mu.Unlock()
+	if calls := atomic.LoadInt32(&b.calls); calls != 2 || spans != 2 {
+		t.Errorf("%d requests and %d spans after the timeout, want the 2 in flight", calls, spans)
+	}
+}
+
+func TestCancelledStreamLeavesNoGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewClient("k", WithBackend(&stallingBackend{}), WithLanguages(English), WithMaxDuration(10*time.Millisecond)).Stream(ctx)
+	s.Write(make([]byte, 2*bytesFor(10*time.Millisecond, defaultSampleRate)))
+	// The Stream is dropped without Close or reading its Results.
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines left behind", n-before)
+	}
+}
+
+func TestCancelledSessionLeavesNoGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewClient("k", WithBackend(&stallingBackend{}), WithLanguages(English)).NewSession(ctx)
+	s.Write(speechPCM(2*time.Second, [2]time.Duration{0, 200 * time.Millisecond}, [2]time.Duration{time.Second, 1200 * time.Millisecond}))
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines left behind", n-before)
+	}
+}
@@ -0,0 +1,133 @@
+// Package capture records audio from the microphone in the format gorec
+// sends by default, 16 kHz, 16-bit little-endian mono PCM, by running ALSA's
+// arecord or any other command writing that format to its standard output.
+package capture
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	sampleRate = 16000
+	frameSize  = sampleRate / 50 * 2 // 20ms
+
+	// silenceThreshold is the absolute sample value below which a frame
+	// counts as silent, the same as gorec.TrimSilence uses.
+	silenceThreshold = 500
+)
+
+// Recorder records with Command, arecord on the default device if empty.
+type Recorder struct {
+	Command []string
+}
+
+var arecord = []string{"arecord", "-q", "-t", "raw", "-f", "S16_LE", "-c", "1", "-r", "16000"}
+
+// Record records d of audio with the default Recorder.
+func Record(d time.Duration) ([]byte, error) {
+	return Recorder{}.Record(context.Background(), d)
+}
+
+// RecordUntilSilence records with the default Recorder until a second of
+// silence follows speech, for at most 15 seconds.
+func RecordUntilSilence() ([]byte, error) {
+	return Recorder{}.RecordUntilSilence(context.Background(), time.Second, 15*time.Second)
+}
+
+// Record records d of audio.
+func (r Recorder) Record(ctx context.Context, d time.Duration) ([]byte, error) {
+	audio := make([]byte, int(d*sampleRate/time.Second)*2)
+	err := r.run(ctx, func(src io.Reader) error {
+		_, err := io.ReadFull(src, audio)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return audio, nil
+}
+
+// RecordUntilSilence records until silence has followed speech for as long
+// as silence, or max has been recorded. Silence before anyone speaks doesn't
+// stop it.
+func (r Recorder) RecordUntilSilence(ctx context.Context, silence, max time.Duration) ([]byte, error) {
+	limit := int(max*sampleRate/time.Second) * 2
+	var audio []byte
+	err := r.run(ctx, func(src io.Reader) error {
+		frame := make([]byte, frameSize)
+		var spoke bool
+		var quiet time.Duration
+		for len(audio) < limit {
+			if _, err := io.ReadFull(src, frame); err != nil {
+				return err
+			}
+			audio = append(audio, frame...)
+			if loud(frame) {
+				spoke, quiet = true, 0
+				continue
+			}
+			quiet += 20 * time.Millisecond
+			if spoke && quiet >= silence {
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return audio, nil
+}
+
+// run starts the command, hands its output to read and stops it once read
+// returns.
+func (r Recorder) run(ctx context.Context, read func(io.Reader) error) error {
+	command := r.Command
+	if len(command) == 0 {
+		command = arecord
+	}
+	cmdCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, command[0], command[1:]...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	// Don't wait on children of the command still holding its output open.
+	cmd.WaitDelay = time.Second
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	readErr := read(stdout)
+	cancel()
+	cmd.Wait()
+	if readErr != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("%s stopped recording: %s", command[0], strings.TrimSpace(stderr.String()))
+		}
+		return readErr
+	}
+	return nil
+}
+
+func loud(frame []byte) bool {
+	for i := 0; i+1 < len(frame); i += 2 {
+		v := int(int16(binary.LittleEndian.Uint16(frame[i:])))
+		if v >= silenceThreshold || -v >= silenceThreshold {
+			return true
+		}
+	}
+	return false
+}
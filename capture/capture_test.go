@@ -0,0 +1,28 @@
+package capture
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRecord(t *testing.T) {
+	r := Recorder{Command: []string{"head", "-c", "100000", "/dev/zero"}}
+	b, err := r.Record(context.Background(), 100*time.Millisecond)
+	if err != nil || len(b) != 3200 {
+		t.Fatal(len(b), err)
+	}
+	if _, err := r.Record(context.Background(), 10*time.Second); err == nil {
+		t.Fatal("want error")
+	}
+	// all silence: runs until max
+	b, err = Recorder{Command: []string{"cat", "/dev/zero"}}.RecordUntilSilence(context.Background(), 100*time.Millisecond, 200*time.Millisecond)
+	if err != nil || len(b) != 6400 {
+		t.Fatal(len(b), err)
+	}
+	// loud then silence
+	b, err = Recorder{Command: []string{"sh", "-c", "head -c 640 /dev/urandom | tr '\\000' '\\377'; cat /dev/zero"}}.RecordUntilSilence(context.Background(), 100*time.Millisecond, 10*time.Second)
+	if err != nil || len(b) > 640*10 {
+		t.Fatal(len(b), err)
+	}
+}
@@ -0,0 +1,76 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"strings"
+)
+
+// ChunkMerger stitches the transcripts of consecutive chunks into one.
+type ChunkMerger func(parts []string) string
+
+// JoinChunks is the default ChunkMerger: it joins the parts with single
+// spaces and trims the result.
+func JoinChunks(parts []string) string {
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
+
+func ListenChunked(audio []byte, chunkSize int, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenChunked(audio, chunkSize)
+}
+
+func ListenChunkedContext(ctx context.Context, audio []byte, chunkSize int, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenChunkedContext(ctx, audio, chunkSize)
+}
+
+// ListenChunked recognizes audio too long for a single request by splitting
+// it into chunks of chunkSize bytes. The transcripts are merged with the
+// Client's ChunkMerger, the confidence is their average and the language is
+// the one most chunks were recognized in. Word timings reported by the
+// backend are made relative to the start of audio when it is linear PCM.
+func (c *Client) ListenChunked(audio []byte, chunkSize int, opts ...Option) (*Hypothesis, error) {
+	return c.ListenChunkedContext(context.Background(), audio, chunkSize, opts...)
+}
+
+// ListenChunkedContext is ListenChunked abandoning the chunks not
+// recognized yet once ctx is done.
+func (c *Client) ListenChunkedContext(ctx context.Context, audio []byte, chunkSize int, opts ...Option) (*Hypothesis, error) {
+	c = c.with(opts)
+	if len(audio) == 0 {
+		return nil, ErrEmptyAudio
+	}
+	total := -1
+	if chunkSize > 0 {
+		total = (len(audio) + chunkSize - 1) / chunkSize
+	}
+	hs, err := c.listenClips(ctx, bytes.NewReader(audio), chunkSize, total)
+	c.completed(err)
+	if err != nil {
+		return nil, err
+	}
+	if isL16(c.cfg.contentType) {
+		for i := range hs {
+			offset := DurationOf(audio[:i*chunkSize], c.sampleRate(), 1, 16)
+			hs[i].Alternative.Words = shiftWords(hs[i].Alternative.Words, offset)
+		}
+	}
+	return c.mergeChunks(hs), nil
+}
+
+func (c *Client) mergeChunks(hs []Hypothesis) *Hypothesis {
+	merged := &Hypothesis{}
+	parts := make([]string, len(hs))
+	votes := make(map[Language]int)
+	for i, h := range hs {
+		parts[i] = h.Alternative.Transcript
+		merged.Alternative.Confidence += h.Alternative.Confidence / float64(len(hs))
+		merged.Latency += h.Latency
+		merged.Alternative.Words = append(merged.Alternative.Words, h.Alternative.Words...)
+		votes[h.Language]++
+		if votes[h.Language] > votes[merged.Language] {
+			merged.Language = h.Language
+		}
+	}
+	merged.Alternative.Transcript = c.cfg.chunkMerger(parts)
+	return merged
+}
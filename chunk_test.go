@@ -0,0 +1,31 @@
+package gorec
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// timedBackend reports a single word timed at the start of every request.
+type timedBackend struct{}
+
+func (timedBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	alt := Alternative{Transcript: "hi", Confidence: 0.8, Words: []Word{{Word: "hi", Start: 0, End: 100 * time.Millisecond}}}
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{alt}, Final: true}}}, nil
+}
+
+func TestListenChunkedWordOffsets(t *testing.T) {
+	h, err := ListenChunked(make([]byte, 3*32000), 32000, "k", WithBackend(timedBackend{}), WithLanguages(English))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Alternative.Words) != 3 {
+		t.Fatalf("Words = %v", h.Alternative.Words)
+	}
+	for i, w := range h.Alternative.Words {
+		if start := time.Duration(i) * time.Second; w.Start != start || w.End != start+100*time.Millisecond || w.Estimated {
+			t.Errorf("word %d = %+v, want it at %v", i, w, start)
+		}
+	}
+}
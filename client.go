@@ -0,0 +1,468 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Client recognizes audio with a fixed key and set of options. Its
+// configuration never changes after NewClient, so a single Client is safe
+// for concurrent use by any number of goroutines. Options given to a method
+// apply over the Client's for that call only.
+type Client struct {
+	key string
+	cfg *config
+
+	closeOnce sync.Once
+	closers   []func()
+}
+
+func NewClient(key string, opts ...Option) *Client {
+	c := &Client{key: key, cfg: newConfig(opts)}
+	if t := c.cfg.transport; t != nil {
+		c.closers = append(c.closers, t.CloseIdleConnections)
+	}
+	return c
+}
+
+// Recognizer is the name Client is also known by.
+type Recognizer = Client
+
+// New is NewClient.
+func New(key string, opts ...Option) *Recognizer {
+	return NewClient(key, opts...)
+}
+
+// NewClientFromKeyFile reads the key from the file at path, as mounted by
+// container secret stores, ignoring surrounding whitespace.
+func NewClientFromKeyFile(path string, opts ...Option) (*Client, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key := strings.TrimSpace(string(b))
+	if key == "" {
+		return nil, fmt.Errorf("Empty key file %s", path)
+	}
+	return NewClient(key, opts...), nil
+}
+
+// with returns c with opts applied over its own for a single call, leaving
+// c untouched.
+func (c *Client) with(opts []Option) *Client {
+	if len(opts) == 0 {
+		return c
+	}
+	cfg := *c.cfg
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.transportChanged {
+		// Nothing closes a transport built for a single call, so it keeps
+		// no idle connections behind once the call is done.
+		cfg.buildTransport()
+		cfg.transport.DisableKeepAlives = true
+	}
+	return &Client{key: c.key, cfg: &cfg}
+}
+
+// Close releases the background resources owned by the Client. It is safe
+// to call more than once; the Client must not be used afterwards.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		for _, close := range c.closers {
+			close()
+		}
+	})
+	return nil
+}
+
+func (c *Client) ListenFile(audio []byte, opts ...Option) (*Hypothesis, error) {
+	return c.ListenFileContext(context.Background(), audio, opts...)
+}
+
+// ListenFileContext is ListenFile abandoning the requests in flight once ctx
+// is done. Every recognition method has such a Context variant.
+func (c *Client) ListenFileContext(ctx context.Context, audio []byte, opts ...Option) (*Hypothesis, error) {
+	c = c.with(opts)
+	return c.listen(ctx, audio)
+}
+
+// ListenBase64 decodes standard, padded base64 audio and recognizes it.
+func (c *Client) ListenBase64(b64 string, opts ...Option) (*Hypothesis, error) {
+	return c.ListenBase64Context(context.Background(), b64, opts...)
+}
+
+// ListenBase64Context is ListenBase64 abandoning the requests in flight
+// once ctx is done.
+func (c *Client) ListenBase64Context(ctx context.Context, b64 string, opts ...Option) (*Hypothesis, error) {
+	c = c.with(opts)
+	audio, err := base64.StdEncoding.Strict().DecodeString(b64)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid base64 audio: %w", err)
+	}
+	return c.listen(ctx, audio)
+}
+
+func (c *Client) listen(ctx context.Context, audio []byte) (*Hypothesis, error) {
+	c, audio, err := c.prepare(audio)
+	if err != nil {
+		return nil, err
+	}
+	if c.cfg.cache == nil {
+		return c.listenPrepared(ctx, audio)
+	}
+	key := c.cacheKey(audio)
+	if h, ok := c.cfg.cache.Get(ctx, key); ok {
+		return &h, nil
+	}
+	h, err := c.listenPrepared(ctx, audio)
+	if err == nil && !h.Partial {
+		c.cfg.cache.Set(ctx, key, *h)
+	}
+	return h, err
+}
+
+func (c *Client) Transcribe(audio []byte, opts ...Option) (text string, lang Language, confidence float64, err error) {
+	return c.TranscribeContext(context.Background(), audio, opts...)
+}
+
+// TranscribeContext is Transcribe abandoning the requests in flight once
+// ctx is done.
+func (c *Client) TranscribeContext(ctx context.Context, audio []byte, opts ...Option) (text string, lang Language, confidence float64, err error) {
+	c = c.with(opts)
+	h, err := c.listen(ctx, audio)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	return h.Alternative.Transcript, h.Language, h.Alternative.Confidence, nil
+}
+
+// ListenReaderAt recognizes the first size bytes of r, typically an *os.File.
+// Every language request reads its body straight from r, so unlike
+// ReadAudioFile followed by ListenFile the audio is never held in memory and
+// usage stays flat regardless of the file size.
+func (c *Client) ListenReaderAt(r io.ReaderAt, size int64, opts ...Option) (*Hypothesis, error) {
+	return c.ListenReaderAtContext(context.Background(), r, size, opts...)
+}
+
+// ListenReaderAtContext is ListenReaderAt abandoning the requests in flight,
+// and their reads of r, once ctx is done.
+func (c *Client) ListenReaderAtContext(ctx context.Context, r io.ReaderAt, size int64, opts ...Option) (*Hypothesis, error) {
+	c = c.with(opts)
+	return c.listenBest(ctx, r, size)
+}
+
+// ListenPath recognizes the audio file at path. Unless the audio must be
+// processed in memory first, as resampling, downmixing, a preprocessing
+// Pipeline and silence trimming require, the requests read straight from
+// the open file, or the samples of a WAV file, through ListenReaderAt, all
+// sharing the one file rather than a copy each, so a large file costs
+// no more memory than a small one instead of twice its size as with
+// ReadAudioFile followed by ListenFile.
+func (c *Client) ListenPath(path string, opts ...Option) (*Hypothesis, error) {
+	return c.ListenPathContext(context.Background(), path, opts...)
+}
+
+// ListenPathContext is ListenPath abandoning the reading of the file and
+// the requests in flight once ctx is done.
+func (c *Client) ListenPathContext(ctx context.Context, path string, opts ...Option) (*Hypothesis, error) {
+	c = c.with(opts)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	head := make([]byte, wavHeadSize)
+	n, _ := f.ReadAt(head, 0)
+	head = head[:n]
+	var r io.ReaderAt = f
+	size := info.Size()
+	whole := len(c.cfg.preprocess) > 0 || c.cfg.trimSilence || c.cfg.vad != nil || c.cfg.inputRate > 0 || c.cfg.inputChannels > 1
+	if !whole && isWAV(head) {
+		var ok bool
+		c, r, size, ok = c.wavSection(f, head, size)
+		whole = !ok
+	}
+	if whole {
+		audio, err := ReadAudioFileContext(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		h, err := c.listen(ctx, audio)
+		if h != nil {
+			h.Source = path
+		}
+		return h, err
+	}
+	if err := c.checkFormat(head); err != nil {
+		return nil, err
+	}
+	c, err = c.forAudio(head)
+	if err != nil {
+		return nil, err
+	}
+	h, err := c.listenBest(ctx, r, size)
+	if h != nil {
+		h.Source = path
+	}
+	return h, err
+}
+
+// wavHeadSize is how much of a file ListenPath reads to find the samples
+// of a WAV file, enough for the metadata chunks before them.
+const wavHeadSize = 4 << 10
+
+// wavSection returns the samples of the WAV file f of the given size,
+// whose header is in head, as a section of f along with c declaring their
+// rate. It returns false if the samples must be read whole to be decoded:
+// when they are not mono, need resampling, or the header runs past head.
+func (c *Client) wavSection(f io.ReaderAt, head []byte, size int64) (*Client, io.ReaderAt, int64, bool) {
+	if !isL16(c.cfg.contentType) {
+		return c, nil, 0, false
+	}
+	w, offset, n, err := wavHeader(head, false)
+	if err != nil {
+		return c, nil, 0, false
+	}
+	if w.SampleRate != c.sampleRate() {
+		if !c.cfg.keepRate {
+			return c, nil, 0, false
+		}
+		c = c.with([]Option{WithSampleRate(w.SampleRate)})
+	}
+	data := min(int64(n), size-int64(offset))
+	return c, io.NewSectionReader(f, int64(offset), data), data, true
+}
+
+// ListenFileAll returns the hypothesis of every language that produced a
+// result. Languages that failed are left out unless WithIncludeErrors is set.
+func (c *Client) ListenFileAll(audio []byte, opts ...Option) (map[Language]Hypothesis, error) {
+	return c.ListenFileAllContext(context.Background(), audio, opts...)
+}
+
+// ListenFileAllContext is ListenFileAll abandoning the requests in flight
+// once ctx is done.
+func (c *Client) ListenFileAllContext(ctx context.Context, audio []byte, opts ...Option) (map[Language]Hypothesis, error) {
+	c = c.with(opts)
+	c, audio, err := c.prepare(audio)
+	if err != nil {
+		return nil, err
+	}
+	hs, err := c.listenAll(ctx, bytes.NewReader(audio), int64(len(audio)), nil)
+	if err != nil {
+		return nil, err
+	}
+	all := make(map[Language]Hypothesis)
+	for _, h := range hs {
+		if h.Err == nil || c.cfg.includeErrors {
+			all[h.Language] = h
+		}
+	}
+	if len(all) == 0 {
+		return nil, newSummaryError(c.languages(), hs)
+	}
+	return all, nil
+}
+
+// ListenFileFunc calls fn with the hypothesis of every language, failed ones
+// included, from the calling goroutine. By default fn sees them as soon as
+// they arrive; with WithInOrder they are held back until every language
+// before them in SupportedLanguages has been delivered, at the cost of a slow
+// language delaying all those after it.
+func (c *Client) ListenFileFunc(audio []byte, fn func(Hypothesis), opts ...Option) error {
+	return c.ListenFileFuncContext(context.Background(), audio, fn, opts...)
+}
+
+// ListenFileFuncContext is ListenFileFunc abandoning the requests in flight
+// once ctx is done; fn is not called for the languages they were for.
+func (c *Client) ListenFileFuncContext(ctx context.Context, audio []byte, fn func(Hypothesis), opts ...Option) error {
+	c = c.with(opts)
+	c, audio, err := c.prepare(audio)
+	if err != nil {
+		return err
+	}
+	if !c.cfg.inOrder {
+		_, err := c.listenAll(ctx, bytes.NewReader(audio), int64(len(audio)), fn)
+		return err
+	}
+	o := &orderedEmitter{order: c.languages(), pending: make(map[Language]Hypothesis), fn: fn}
+	_, err = c.listenAll(ctx, bytes.NewReader(audio), int64(len(audio)), o.emit)
+	o.flush()
+	return err
+}
+
+type orderedEmitter struct {
+	order   []Language
+	next    int
+	pending map[Language]Hypothesis
+	fn      func(Hypothesis)
+}
+
+func (o *orderedEmitter) emit(h Hypothesis) {
+	o.pending[h.Language] = h
+	for o.next < len(o.order) {
+		h, ok := o.pending[o.order[o.next]]
+		if !ok {
+			return
+		}
+		o.fn(h)
+		delete(o.pending, h.Language)
+		o.next++
+	}
+}
+
+// flush delivers what is left, in order, skipping languages that never answered.
+func (o *orderedEmitter) flush() {
+	for ; o.next < len(o.order); o.next++ {
+		if h, ok := o.pending[o.order[o.next]]; ok {
+			o.fn(h)
+		}
+	}
+}
+
+func (c *Client) Recognize(audio []byte, lang Language, opts ...Option) (*Hypothesis, error) {
+	return c.RecognizeContext(context.Background(), audio, lang, opts...)
+}
+
+// RecognizeContext is Recognize abandoning its request once ctx is done.
+func (c *Client) RecognizeContext(ctx context.Context, audio []byte, lang Language, opts ...Option) (*Hypothesis, error) {
+	c = c.with(opts)
+	c, audio, err := c.prepare(audio)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	h, err := c.recognize(ctx, bytes.NewReader(audio), int64(len(audio)), lang)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// BuildRequest returns the request Recognize would send for audio, without
+// sending it.
+func (c *Client) BuildRequest(audio []byte, lang Language, opts ...Option) (*http.Request, error) {
+	c = c.with(opts)
+	c, audio, err := c.prepare(audio)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkSize(int64(len(audio))); err != nil {
+		return nil, err
+	}
+	key, err := c.apiKey()
+	if err != nil {
+		return nil, err
+	}
+	return c.newRequest(context.Background(), bytes.NewReader(audio), int64(len(audio)), lang, key)
+}
+
+// Languages returns the languages the Client queries, in order, as set with
+// WithLanguages or SupportedLanguages otherwise. The slice is a copy.
+func (c *Client) Languages() []Language {
+	return c.languages()
+}
+
+// languages returns a copy of the languages the Client queries, in order.
+func (c *Client) languages() []Language {
+	if c.cfg.languages == nil {
+		return SupportedLanguages()
+	}
+	return append([]Language(nil), c.cfg.languages...)
+}
+
+// sampleRate returns the rate the Client declares for the audio it sends.
+func (c *Client) sampleRate() int {
+	if rate := contentTypeRate(c.cfg.contentType); rate > 0 {
+		return rate
+	}
+	return defaultSampleRate
+}
+
+// withTimeout derives a cancellable context from ctx, bounded by the
+// Client's timeout if it has one.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.cfg.timeout > 0 {
+		return context.WithTimeout(ctx, c.cfg.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// prepare applies the Client's audio processing to audio before it is sent,
+// returning the Client to send it with.
+func (c *Client) prepare(audio []byte) (*Client, []byte, error) {
+	if err := c.checkFormat(audio); err != nil {
+		return nil, nil, err
+	}
+	audio, err := c.cfg.preprocess.Run(audio)
+	if err != nil {
+		return nil, nil, err
+	}
+	fc, err := c.forAudio(audio)
+	if err != nil {
+		return nil, nil, err
+	}
+	if fc != c {
+		return fc, audio, nil
+	}
+	rate, channels := c.cfg.inputRate, c.cfg.inputChannels
+	if isWAV(audio) && isL16(c.cfg.contentType) {
+		w, err := parseWAV(audio, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		audio, rate, channels = w.Data, w.SampleRate, w.Channels
+	}
+	if channels > 1 {
+		audio = Downmix(audio, channels)
+	}
+	if rate > 0 && rate != c.sampleRate() {
+		if c.cfg.keepRate {
+			c = c.with([]Option{WithSampleRate(rate)})
+		} else {
+			audio = Resample(audio, rate, c.sampleRate())
+		}
+	}
+	if c.cfg.vad != nil {
+		v := *c.cfg.vad
+		if v.SampleRate == 0 {
+			v.SampleRate = c.sampleRate()
+		}
+		audio = v.Trim(audio)
+	} else if c.cfg.trimSilence {
+		audio = trimSilence(audio, c.cfg.leadPadding, c.sampleRate())
+	}
+	return c, audio, nil
+}
+
+// forAudio returns c declaring the format of the audio starting with head,
+// when it is FLAC or Ogg Opus and no Content-Type was chosen. Audio
+// DetectFormat knows but calls cannot send, such as MP3, fails wrapping
+// ErrUnsupportedFormat then, while WAV files are left to be parsed.
+func (c *Client) forAudio(head []byte) (*Client, error) {
+	if c.cfg.contentType != ContentType || isWAV(head) {
+		return c, nil
+	}
+	f, err := DetectFormat(head)
+	if err != nil {
+		return nil, err
+	}
+	if f.Encoding == FLAC || f.Encoding == OggOpus {
+		return c.with([]Option{WithContentType(f.ContentType())}), nil
+	}
+	return c, nil
+}
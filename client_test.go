@@ -0,0 +1,89 @@
+package gorec
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEmptyAudio(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.raw")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient("k", WithBackend(coverageBackend{}))
+	defer c.Close()
+	for _, audio := range [][]byte{nil, {}} {
+		calls := map[string]func() error{
+			"ListenFile": func() error {
+				_, err := c.ListenFile(audio)
+				return err
+			},
+			"ListenFileAll": func() error {
+				_, err := c.ListenFileAll(audio)
+				return err
+			},
+			"ListenFileFunc": func() error {
+				return c.ListenFileFunc(audio, func(Hypothesis) {})
+			},
+			"ListenFileDetailed": func() error {
+				_, err := c.ListenFileDetailed(audio)
+				return err
+			},
+			"ListenChunked": func() error {
+				_, err := c.ListenChunked(audio, 2)
+				return err
+			},
+			"LanguageRanking": func() error {
+				_, err := c.LanguageRanking(audio)
+				return err
+			},
+			"Recognize": func() error {
+				_, err := c.Recognize(audio, English)
+				return err
+			},
+			"Transcribe": func() error {
+				_, _, _, err := c.Transcribe(audio)
+				return err
+			},
+			"ListenReaderAt": func() error {
+				_, err := c.ListenReaderAt(bytes.NewReader(audio), int64(len(audio)))
+				return err
+			},
+			"ListenBase64": func() error {
+				_, err := c.ListenBase64("")
+				return err
+			},
+			"ListenPath": func() error {
+				_, err := c.ListenPath(empty)
+				return err
+			},
+		}
+		for name, call := range calls {
+			if err := call(); err != ErrEmptyAudio {
+				t.Errorf("%s(%#v) = %v, want ErrEmptyAudio", name, audio, err)
+			}
+		}
+	}
+}
+
+func TestNoLanguages(t *testing.T) {
+	c := NewClient("k", WithBackend(coverageBackend{}), WithLanguages())
+	defer c.Close()
+	if _, err := c.ListenFile([]byte{1, 2}); err != ErrNoLanguages {
+		t.Errorf("ListenFile = %v, want ErrNoLanguages", err)
+	}
+	if _, err := c.ListenFileAll([]byte{1, 2}); err != ErrNoLanguages {
+		t.Errorf("ListenFileAll = %v, want ErrNoLanguages", err)
+	}
+	if _, err := c.LanguageRanking([]byte{1, 2}); err != ErrNoLanguages {
+		t.Errorf("LanguageRanking = %v, want ErrNoLanguages", err)
+	}
+	if err := c.ListenFileFunc([]byte{1, 2}, func(Hypothesis) {}); err != ErrNoLanguages {
+		t.Errorf("ListenFileFunc = %v, want ErrNoLanguages", err)
+	}
+	if _, err := ListenFile([]byte{1, 2}, "k", WithBackend(coverageBackend{}), WithLanguages(English), WithLanguages()); err != ErrNoLanguages {
+		t.Errorf("ListenFile with languages cleared = %v, want ErrNoLanguages", err)
+	}
+}
@@ -0,0 +1,19 @@
+package gorec
+
+import "time"
+
+// clock is the source of time for timeouts, backoff and latency, so tests
+// can substitute a fake one through withClock.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func withClock(clk clock) Option {
+	return func(c *config) { c.clock = clk }
+}
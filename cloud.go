@@ -0,0 +1,350 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CloudEndpoint is the synchronous recognition method of the official Cloud
+// Speech-to-Text v1 REST API.
+const CloudEndpoint = "https://speech.googleapis.com/v1/speech:recognize"
+
+const cloudScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// TokenSource supplies OAuth2 access tokens, such as those of a service
+// account from ServiceAccount or golang.org/x/oauth2.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// CloudBackend is a Backend for the official Cloud Speech-to-Text v1 API. It
+// authenticates with Tokens when set and with the API key Key otherwise.
+// Word timings and channels are reported in Alternative.Words and
+// Result.Channel.
+type CloudBackend struct {
+	Key    string
+	Tokens TokenSource
+
+	// Endpoint defaults to CloudEndpoint and HTTPClient to a default client.
+	Endpoint   string
+	HTTPClient *http.Client
+
+	// Channels, when above one, has each channel of the audio recognized
+	// separately.
+	Channels int
+
+	// Speakers, when above one, has the API tell apart up to that many
+	// speakers, labelling each of the Words with its Speaker.
+	Speakers int
+}
+
+type cloudRequest struct {
+	Config struct {
+		Encoding               string               `json:"encoding,omitempty"`
+		SampleRateHertz        int                  `json:"sampleRateHertz,omitempty"`
+		LanguageCode           string               `json:"languageCode"`
+		MaxAlternatives        int                  `json:"maxAlternatives"`
+		EnableWordOffsets      bool                 `json:"enableWordTimeOffsets"`
+		SpeechContexts         []cloudSpeechContext `json:"speechContexts,omitempty"`
+		AudioChannelCount      int                  `json:"audioChannelCount,omitempty"`
+		SeparateRecognitionPer bool                 `json:"enableSeparateRecognitionPerChannel,omitempty"`
+		DiarizationConfig      *cloudDiarization    `json:"diarizationConfig,omitempty"`
+		ProfanityFilter        bool                 `json:"profanityFilter,omitempty"`
+	} `json:"config"`
+	Audio struct {
+		Content []byte `json:"content"`
+	} `json:"audio"`
+}
+
+type cloudDiarization struct {
+	EnableSpeakerDiarization bool `json:"enableSpeakerDiarization"`
+	MinSpeakerCount          int  `json:"minSpeakerCount"`
+	MaxSpeakerCount          int  `json:"maxSpeakerCount"`
+}
+
+type cloudSpeechContext struct {
+	Phrases []string `json:"phrases"`
+}
+
+type cloudResponse struct {
+	Results []struct {
+		Alternatives []struct {
+			Transcript string  `json:"transcript"`
+			Confidence float64 `json:"confidence"`
+			Words      []struct {
+				Word       string `json:"word"`
+				StartTime  string `json:"startTime"`
+				EndTime    string `json:"endTime"`
+				SpeakerTag int    `json:"speakerTag"`
+			} `json:"words"`
+		} `json:"alternatives"`
+		ChannelTag int `json:"channelTag"`
+	} `json:"results"`
+}
+
+func (b *CloudBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, params BackendParams) (*GoogleResponse, error) {
+	content, err := ioutil.ReadAll(audio)
+	if err != nil {
+		return nil, err
+	}
+	var req cloudRequest
+	req.Config.Encoding = cloudEncoding(params.ContentType)
+	req.Config.SampleRateHertz = contentTypeRate(params.ContentType)
+	req.Config.LanguageCode = lang.Code()
+	req.Config.MaxAlternatives = params.MaxAlternatives
+	if req.Config.MaxAlternatives <= 0 {
+		req.Config.MaxAlternatives = 5
+	}
+	req.Config.EnableWordOffsets = true
+	if len(params.PhraseHints) > 0 {
+		req.Config.SpeechContexts = []cloudSpeechContext{{Phrases: params.PhraseHints}}
+	}
+	if b.Channels > 1 {
+		req.Config.AudioChannelCount = b.Channels
+		req.Config.SeparateRecognitionPer = true
+	}
+	if b.Speakers > 1 {
+		req.Config.DiarizationConfig = &cloudDiarization{EnableSpeakerDiarization: true, MinSpeakerCount: 1, MaxSpeakerCount: b.Speakers}
+	}
+	req.Config.ProfanityFilter = params.Profanity == ProfanityMask || params.Profanity == ProfanityDrop
+	req.Audio.Content = content
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := b.Endpoint
+	if endpoint == "" {
+		endpoint = CloudEndpoint
+	}
+	r, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	client := b.HTTPClient
+	if client == nil {
+		client = sharedClient
+	}
+	if b.Tokens != nil {
+		token, err := fetchToken(ctx, b.Tokens, client)
+		if err != nil {
+			return nil, fmt.Errorf("Getting an access token: %w", err)
+		}
+		r.Header.Set("Authorization", "Bearer "+token)
+	} else {
+		r.URL.RawQuery = url.Values{"key": {b.Key}}.Encode()
+	}
+
+	resp, err := client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Reading response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, newAPIError(resp.StatusCode, respBody)
+	}
+	var cr cloudResponse
+	if err := json.Unmarshal(respBody, &cr); err != nil {
+		return nil, fmt.Errorf("Decoding response: %w", err)
+	}
+	return cr.googleResponse(), nil
+}
+
+// googleResponse maps cr onto the types Google's v2 endpoint decodes to.
+// Every v1 result is final.
+func (cr *cloudResponse) googleResponse() *GoogleResponse {
+	gr := &GoogleResponse{}
+	for _, res := range cr.Results {
+		r := Result{Final: true, Channel: res.ChannelTag}
+		for _, a := range res.Alternatives {
+			alt := Alternative{Transcript: a.Transcript, Confidence: a.Confidence}
+			for _, w := range a.Words {
+				start, _ := time.ParseDuration(w.StartTime)
+				end, _ := time.ParseDuration(w.EndTime)
+				alt.Words = append(alt.Words, Word{Word: w.Word, Start: start, End: end, Speaker: w.SpeakerTag})
+			}
+			r.Alternatives = append(r.Alternatives, alt)
+		}
+		gr.Results = append(gr.Results, r)
+	}
+	return gr
+}
+
+// cloudEncoding names the v1 encoding of audio sent as contentType, leaving
+// it empty for the API to detect when unknown.
+func cloudEncoding(contentType string) string {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	switch strings.TrimSpace(mediaType) {
+	case "audio/l16":
+		return "LINEAR16"
+	case "audio/x-flac", "audio/flac":
+		return "FLAC"
+	case "audio/ogg":
+		return "OGG_OPUS"
+	}
+	return ""
+}
+
+// bcp47 turns a code such as "en-us" or "cmn-hans-cn" into its BCP-47
+// casing, "en-US" or "cmn-Hans-CN", the form v1 expects.
+func bcp47(code string) string {
+	tags := strings.Split(code, "-")
+	for i, tag := range tags[1:] {
+		switch len(tag) {
+		case 4:
+			// A script, such as Hans.
+			tags[i+1] = strings.ToUpper(tag[:1]) + strings.ToLower(tag[1:])
+		case 2, 3:
+			tags[i+1] = strings.ToUpper(tag)
+		}
+	}
+	return strings.Join(tags, "-")
+}
+
+// ServiceAccount returns a TokenSource for the service account whose JSON
+// key file contents are jsonKey. Tokens are cached until shortly before they
+// expire. Used as CloudBackend.Tokens, it fetches them with the backend's
+// HTTPClient.
+func ServiceAccount(jsonKey []byte) (TokenSource, error) {
+	var key struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(jsonKey, &key); err != nil {
+		return nil, fmt.Errorf("Invalid service account key: %w", err)
+	}
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, errors.New("Invalid service account key: no PEM private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Invalid service account key: %w", err)
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("Invalid service account key: not an RSA key")
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &serviceAccount{email: key.ClientEmail, key: rsaKey, tokenURI: key.TokenURI, clock: realClock{}}, nil
+}
+
+// clientTokenSource is a TokenSource that can fetch its tokens with a given
+// client, such as CloudBackend's HTTPClient.
+type clientTokenSource interface {
+	tokenWith(ctx context.Context, client *http.Client) (string, error)
+}
+
+func fetchToken(ctx context.Context, ts TokenSource, client *http.Client) (string, error) {
+	if cts, ok := ts.(clientTokenSource); ok {
+		return cts.tokenWith(ctx, client)
+	}
+	return ts.Token(ctx)
+}
+
+type serviceAccount struct {
+	email    string
+	key      *rsa.PrivateKey
+	tokenURI string
+	clock    clock
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (sa *serviceAccount) Token(ctx context.Context) (string, error) {
+	return sa.tokenWith(ctx, sharedClient)
+}
+
+func (sa *serviceAccount) tokenWith(ctx context.Context, client *http.Client) (string, error) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+	now := sa.clock.Now()
+	if sa.token != "" && now.Before(sa.expires) {
+		return sa.token, nil
+	}
+	assertion, err := sa.assertion(now)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	r, err := http.NewRequestWithContext(ctx, "POST", sa.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := client.Do(r)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode/100 != 2 {
+		return "", newAPIError(resp.StatusCode, body)
+	}
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return "", err
+	}
+	sa.token = tok.AccessToken
+	sa.expires = now.Add(time.Duration(tok.ExpiresIn)*time.Second - time.Minute)
+	return sa.token, nil
+}
+
+// assertion returns the signed JWT exchanged for an access token.
+func (sa *serviceAccount) assertion(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   sa.email,
+		"scope": cloudScope,
+		"aud":   sa.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, sa.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
@@ -0,0 +1,126 @@
+package gorec
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTokenServer serves access tokens at /token and checks the bearer token
+// of recognition requests everywhere else.
+func newTokenServer(t *testing.T, body *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			r.ParseForm()
+			if strings.Count(r.Form.Get("assertion"), ".") != 2 {
+				t.Errorf("assertion %q is not a JWT", r.Form.Get("assertion"))
+			}
+			fmt.Fprint(w, `{"access_token":"tok","expires_in":3600}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		b, _ := io.ReadAll(r.Body)
+		*body = string(b)
+		fmt.Fprint(w, `{"results":[{"alternatives":[{"transcript":"hello world","confidence":0.9,"words":[{"word":"hello","startTime":"0s","endTime":"0.500s"}]}],"channelTag":1}]}`)
+	}))
+}
+
+func serviceAccountKey(t *testing.T, tokenURI string) []byte {
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(k)
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	js, _ := json.Marshal(map[string]string{"client_email": "a@b", "private_key": pemKey, "token_uri": tokenURI})
+	return js
+}
+
+func TestCloudBackend(t *testing.T) {
+	var body string
+	srv := newTokenServer(t, &body)
+	defer srv.Close()
+	ts, err := ServiceAccount(serviceAccountKey(t, srv.URL+"/token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &CloudBackend{Tokens: ts, Endpoint: srv.URL + "/v1"}
+	h, err := Recognize([]byte{1, 2}, "", English, WithBackend(b))
+	if err != nil || h.Alternative.Transcript != "hello world" || h.Alternative.Words[0].End != 500*time.Millisecond {
+		t.Fatal(h, err)
+	}
+	for _, want := range []string{`"languageCode":"en-GB"`, `"LINEAR16"`, `"sampleRateHertz":16000`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("request body %s lacks %s", body, want)
+		}
+	}
+}
+
+type countingTransport struct {
+	requests int32
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&c.requests, 1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestServiceAccountUsesBackendClient(t *testing.T) {
+	var body string
+	srv := newTokenServer(t, &body)
+	defer srv.Close()
+	ts, err := ServiceAccount(serviceAccountKey(t, srv.URL+"/token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := newFakeClock()
+	ts.(*serviceAccount).clock = clk
+	transport := &countingTransport{}
+	b := &CloudBackend{Tokens: ts, Endpoint: srv.URL + "/v1", HTTPClient: &http.Client{Transport: transport}}
+	for i := 0; i < 2; i++ {
+		if _, err := Recognize([]byte{1, 2}, "", English, WithBackend(b)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// One token request, cached for the second recognition.
+	if n := atomic.LoadInt32(&transport.requests); n != 3 {
+		t.Errorf("HTTPClient sent %d requests, want 3", n)
+	}
+	clk.Advance(time.Hour)
+	if _, err := Recognize([]byte{1, 2}, "", English, WithBackend(b)); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&transport.requests); n != 5 {
+		t.Errorf("HTTPClient sent %d requests after the token expired, want 5", n)
+	}
+}
+
+func TestCloudBackendSpeakers(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		fmt.Fprint(w, `{"results":[{"alternatives":[{"transcript":"hi there","words":[{"word":"hi","speakerTag":1},{"word":"there","speakerTag":2}]}]}]}`)
+	}))
+	defer srv.Close()
+	b := &CloudBackend{Key: "k", Endpoint: srv.URL, Speakers: 2}
+	h, err := Recognize([]byte{1, 2}, "", English, WithBackend(b))
+	if err != nil || len(h.Alternative.Words) != 2 || h.Alternative.Words[0].Speaker != 1 || h.Alternative.Words[1].Speaker != 2 {
+		t.Fatal(h, err)
+	}
+	if !strings.Contains(body, `"diarizationConfig":{"enableSpeakerDiarization":true,"minSpeakerCount":1,"maxSpeakerCount":2}`) {
+		t.Errorf("request body %s doesn't ask for diarization", body)
+	}
+}
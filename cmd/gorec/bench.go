@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/carlescere/gorec"
+	"github.com/carlescere/gorec/bench"
+)
+
+func runBench(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	key := fs.String("key", envKey(), "Google API `key`, $GOREC_KEY or $GOREC_API_KEY by default")
+	systems := fs.String("systems", "google", "comma-separated `systems` to compare: google, whisper, whispercpp and vosk")
+	endpoint := fs.String("endpoint", "", "endpoint `template` instead of Google's")
+	whisperKey := fs.String("whisper-key", os.Getenv("OPENAI_API_KEY"), "OpenAI API `key` for whisper, $OPENAI_API_KEY by default")
+	whisperModel := fs.String("whisper-model", "", "model `file` for whispercpp")
+	vosk := fs.String("vosk", "", "comma-separated `code=URL` pairs naming the Vosk server of each language for vosk")
+	dirs, err := parseInterspersed(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(dirs) != 1 {
+		fmt.Fprintln(stderr, "gorec bench: exactly one corpus directory expected")
+		return exitUsage
+	}
+	var opts []gorec.Option
+	if *endpoint != "" {
+		opts = append(opts, gorec.WithEndpoint(*endpoint))
+	}
+	var compared []bench.System
+	for _, name := range strings.Split(*systems, ",") {
+		name = strings.TrimSpace(name)
+		var b gorec.Backend
+		switch name {
+		case "google":
+			if *key == "" {
+				fmt.Fprintln(stderr, "gorec bench: no API key for google; pass -key or set GOREC_KEY")
+				return exitUsage
+			}
+		case "whisper":
+			if *whisperKey == "" {
+				fmt.Fprintln(stderr, "gorec bench: no API key for whisper; pass -whisper-key or set OPENAI_API_KEY")
+				return exitUsage
+			}
+			b = &gorec.WhisperBackend{Key: *whisperKey}
+		case "whispercpp":
+			if *whisperModel == "" {
+				fmt.Fprintln(stderr, "gorec bench: no model for whispercpp; pass -whisper-model")
+				return exitUsage
+			}
+			b = &gorec.WhisperCppBackend{Model: *whisperModel}
+		case "vosk":
+			if *vosk == "" {
+				fmt.Fprintln(stderr, "gorec bench: no server for vosk; pass -vosk")
+				return exitUsage
+			}
+			servers := make(map[gorec.Language]string)
+			for _, pair := range strings.Split(*vosk, ",") {
+				code, url, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				lang, err := gorec.ParseLanguage(code)
+				if !ok || err != nil {
+					fmt.Fprintf(stderr, "gorec bench: invalid Vosk server %q\n", pair)
+					return exitUsage
+				}
+				servers[lang] = url
+			}
+			b = &gorec.VoskBackend{Servers: servers}
+		default:
+			fmt.Fprintf(stderr, "gorec bench: unknown system %q\n", name)
+			return exitUsage
+		}
+		sysOpts := opts
+		if b != nil {
+			sysOpts = append(sysOpts[:len(sysOpts):len(sysOpts)], gorec.WithBackend(b))
+		}
+		compared = append(compared, bench.System{Name: name, Client: gorec.NewClient(*key, sysOpts...)})
+	}
+
+	samples, err := bench.LoadCorpus(dirs[0])
+	if err != nil {
+		fmt.Fprintln(stderr, "gorec bench:", err)
+		return exitFailed
+	}
+	results, err := bench.Run(context.Background(), samples, compared...)
+	if err == nil {
+		err = bench.WriteReport(stdout, results)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "gorec bench:", err)
+		return exitFailed
+	}
+	return exitOK
+}
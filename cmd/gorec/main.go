@@ -0,0 +1,192 @@
+// Command gorec transcribes audio files with the Google speech API.
+//
+//	gorec transcribe [flags] file.wav
+//
+// The file may be "-" to read standard input. The exit code is 0 when
+// something was recognized, 1 when recognition failed, 2 on a usage error
+// and 3 when the service worked but heard no speech.
+//
+//	gorec bench [flags] corpus
+//
+// runs a labeled corpus, laid out as bench.LoadCorpus reads it, through
+// Google's v2 endpoint and any other backends given, reporting their error
+// rates and latency per language.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/carlescere/gorec"
+	"github.com/carlescere/gorec/export"
+)
+
+const (
+	exitOK = iota
+	exitFailed
+	exitUsage
+	exitNoSpeech
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "transcribe":
+			return transcribe(args[1:], stdin, stdout, stderr)
+		case "bench":
+			return runBench(args[1:], stdout, stderr)
+		}
+	}
+	fmt.Fprintln(stderr, "usage: gorec transcribe [flags] file\n       gorec bench [flags] corpus")
+	return exitUsage
+}
+
+func transcribe(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("transcribe", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	key := fs.String("key", envKey(), "API `key`, $GOREC_KEY or $GOREC_API_KEY by default")
+	config := fs.String("config", "", "JSON config `file` with settings the flags override")
+	lang := fs.String("lang", "auto", "comma-separated language `codes` to try, or auto for the defaults")
+	format := fs.String("format", "text", "output `format`: text, json, srt or vtt")
+	rate := fs.Int("rate", 0, "sample `rate` of raw PCM input, if not 16000")
+	long := fs.Bool("long", false, "split audio longer than 15 seconds into chunks")
+	timeout := fs.Duration("timeout", 0, "give up after `duration`")
+	endpoint := fs.String("endpoint", "", "endpoint `template` instead of Google's")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(files) != 1 {
+		fmt.Fprintln(stderr, "gorec transcribe: exactly one file expected")
+		return exitUsage
+	}
+	opts := []gorec.Option{}
+	if *config != "" {
+		cfg, err := gorec.ReadConfig(*config)
+		if err == nil {
+			opts, err = cfg.Options()
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "gorec transcribe:", err)
+			return exitUsage
+		}
+		if *key == "" {
+			*key = cfg.Key
+		}
+	}
+	if *key == "" {
+		fmt.Fprintln(stderr, "gorec transcribe: no API key; pass -key, set GOREC_KEY or give a -config with one")
+		return exitUsage
+	}
+
+	if *lang != "auto" {
+		var langs []gorec.Language
+		for _, code := range strings.Split(*lang, ",") {
+			l, err := gorec.ParseLanguage(code)
+			if err != nil {
+				fmt.Fprintln(stderr, "gorec transcribe:", err)
+				return exitUsage
+			}
+			langs = append(langs, l)
+		}
+		opts = append(opts, gorec.WithLanguages(langs...))
+	}
+	if *rate > 0 {
+		opts = append(opts, gorec.WithInputSampleRate(*rate))
+	}
+	if *timeout > 0 {
+		opts = append(opts, gorec.WithTimeout(*timeout))
+	}
+	if *endpoint != "" {
+		opts = append(opts, gorec.WithEndpoint(*endpoint))
+	}
+	switch *format {
+	case "text", "json":
+	case "srt", "vtt":
+		// Subtitles need word timings, which only chunked recognition has.
+		*long = true
+	default:
+		fmt.Fprintf(stderr, "gorec transcribe: unknown format %q\n", *format)
+		return exitUsage
+	}
+
+	audio, err := readInput(files[0], stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, "gorec transcribe:", err)
+		return exitFailed
+	}
+	c := gorec.NewClient(*key, opts...)
+	defer c.Close()
+	var h *gorec.Hypothesis
+	if *long {
+		h, err = c.ListenLong(audio)
+	} else {
+		h, err = c.ListenFile(audio)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "gorec transcribe:", err)
+		if errors.Is(err, gorec.ErrNoSpeech) {
+			return exitNoSpeech
+		}
+		return exitFailed
+	}
+	if err := write(stdout, *format, h); err != nil {
+		fmt.Fprintln(stderr, "gorec transcribe:", err)
+		return exitFailed
+	}
+	return exitOK
+}
+
+func envKey() string {
+	if key := os.Getenv("GOREC_KEY"); key != "" {
+		return key
+	}
+	return os.Getenv("GOREC_API_KEY")
+}
+
+// parseInterspersed parses args with fs, allowing flags after the file
+// names, and returns the file names.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var files []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return files, nil
+		}
+		files = append(files, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func readInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return gorec.ReadAudioFile(path)
+}
+
+func write(w io.Writer, format string, h *gorec.Hypothesis) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(h)
+	case "srt":
+		return export.WriteSRT(w, export.Cues(h.Alternative.Words, export.Options{}))
+	case "vtt":
+		return export.WriteVTT(w, export.Cues(h.Alternative.Words, export.Options{}))
+	}
+	_, err := fmt.Fprintln(w, h.Alternative.Transcript)
+	return err
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newServer(body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("lang") != "fr-fr" {
+			fmt.Fprint(w, `{"result":[]}`)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+}
+
+// loud returns a second of 16 kHz PCM loud enough to count as speech.
+func loud() []byte {
+	pcm := make([]byte, 32000)
+	for i := 0; i < len(pcm); i += 2 {
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(int16(2000*(i/2%2*2-1))))
+	}
+	return pcm
+}
+
+func TestTranscribe(t *testing.T) {
+	srv := newServer(`{"result":[{"alternative":[{"transcript":"bonjour tout le monde","confidence":0.9}],"final":true}],"result_index":0}`)
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "a.raw")
+	if err := os.WriteFile(path, loud(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	endpoint := srv.URL + "/?lang=%s&key=%s"
+	config := filepath.Join(t.TempDir(), "gorec.json")
+	if err := os.WriteFile(config, []byte(fmt.Sprintf(`{"key": "k", "endpoint": %q, "languages": ["fr"]}`, endpoint)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		args  []string
+		stdin []byte
+		want  string
+	}{
+		{[]string{"transcribe", path, "-key", "k", "-endpoint", endpoint}, nil, "bonjour tout le monde\n"},
+		{[]string{"transcribe", "-key", "k", "-endpoint", endpoint, "-lang", "fr,es", "-"}, loud(), "bonjour tout le monde\n"},
+		{[]string{"transcribe", path, "-key", "k", "-endpoint", endpoint, "-format", "json"}, nil, `"transcript": "bonjour tout le monde"`},
+		{[]string{"transcribe", path, "-key", "k", "-endpoint", endpoint, "-format", "srt"}, nil, "1\n00:00:00,000 --> 00:00:01,000\nbonjour tout le monde\n"},
+		{[]string{"transcribe", path, "-config", config}, nil, "bonjour tout le monde\n"},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := run(c.args, bytes.NewReader(c.stdin), &stdout, &stderr); code != exitOK || !strings.Contains(stdout.String(), c.want) {
+			t.Errorf("%q exited %d with %q, %q; want %q", c.args, code, stdout.String(), stderr.String(), c.want)
+		}
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	srv := newServer(`{"result":[]}`)
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "a.raw")
+	if err := os.WriteFile(path, loud(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	endpoint := srv.URL + "/?lang=%s&key=%s"
+	for _, c := range []struct {
+		args []string
+		want int
+	}{
+		{nil, exitUsage},
+		{[]string{"transcribe"}, exitUsage},
+		{[]string{"transcribe", path, "-key", "k", "-format", "xml"}, exitUsage},
+		{[]string{"transcribe", path, "-key", "k", "-lang", "xx-yy"}, exitUsage},
+		{[]string{"transcribe", filepath.Join(t.TempDir(), "missing"), "-key", "k"}, exitFailed},
+		{[]string{"transcribe", path, "-key", "k", "-endpoint", endpoint}, exitNoSpeech},
+	} {
+		if code := run(c.args, nil, &bytes.Buffer{}, &bytes.Buffer{}); code != c.want {
+			t.Errorf("%q exited %d, want %d", c.args, code, c.want)
+		}
+	}
+}
+
+func TestBench(t *testing.T) {
+	srv := newServer(`{"result":[{"alternative":[{"transcript":"bonjour tout le monde","confidence":0.9}],"final":true}],"result_index":0}`)
+	defer srv.Close()
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "fr"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fr", "a.raw"), loud(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fr", "a.txt"), []byte("Bonjour le monde"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"bench", dir, "-key", "k", "-endpoint", srv.URL + "/?lang=%s&key=%s"}, nil, &stdout, &stderr); code != exitOK || !strings.Contains(stdout.String(), "33.3%") {
+		t.Errorf("bench exited %d with %q, %q", code, stdout.String(), stderr.String())
+	}
+	for _, args := range [][]string{{"bench"}, {"bench", dir, "-key", "k", "-systems", "nope"}, {"bench", dir, "-systems", "whisper", "-whisper-key", ""}} {
+		if code := run(args, nil, &bytes.Buffer{}, &bytes.Buffer{}); code != exitUsage {
+			t.Errorf("%q exited %d, want %d", args, code, exitUsage)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package gorec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the settings of a Client as read from a JSON file by
+// ReadConfig or from the environment by EnvConfig. Durations are strings
+// such as "30s", languages codes such as "en-US" or "es".
+//
+//	{"key": "...", "languages": ["en-US", "es"], "timeout": "30s"}
+type Config struct {
+	Key                 string   `json:"key"`
+	Languages           []string `json:"languages,omitempty"`
+	Timeout             string   `json:"timeout,omitempty"`
+	RequestTimeout      string   `json:"request_timeout,omitempty"`
+	Endpoint            string   `json:"endpoint,omitempty"`
+	SampleRate          int      `json:"sample_rate,omitempty"`
+	ConfidenceThreshold float64  `json:"confidence_threshold,omitempty"`
+	MaxConcurrency      int      `json:"max_concurrency,omitempty"`
+}
+
+// ReadConfig reads the JSON config file at path. Unknown fields are
+// rejected, so that a misspelt setting isn't silently ignored.
+func ReadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("Invalid config file %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// EnvConfig reads the config from the environment: the key from
+// GOREC_API_KEY, comma-separated languages from GOREC_LANGS, the timeout
+// from GOREC_TIMEOUT, the endpoint from GOREC_ENDPOINT and the sample rate
+// of raw PCM from GOREC_SAMPLE_RATE.
+func EnvConfig() (*Config, error) {
+	cfg := &Config{
+		Key:      os.Getenv("GOREC_API_KEY"),
+		Timeout:  os.Getenv("GOREC_TIMEOUT"),
+		Endpoint: os.Getenv("GOREC_ENDPOINT"),
+	}
+	if langs := os.Getenv("GOREC_LANGS"); langs != "" {
+		for _, code := range strings.Split(langs, ",") {
+			cfg.Languages = append(cfg.Languages, strings.TrimSpace(code))
+		}
+	}
+	if rate := os.Getenv("GOREC_SAMPLE_RATE"); rate != "" {
+		n, err := strconv.Atoi(rate)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid GOREC_SAMPLE_RATE: %w", err)
+		}
+		cfg.SampleRate = n
+	}
+	return cfg, nil
+}
+
+// Options returns the options cfg sets, failing on an invalid setting.
+func (cfg *Config) Options() ([]Option, error) {
+	var opts []Option
+	if len(cfg.Languages) > 0 {
+		var langs []Language
+		for _, code := range cfg.Languages {
+			lang, err := ParseLanguage(code)
+			if err != nil {
+				return nil, err
+			}
+			langs = append(langs, lang)
+		}
+		opts = append(opts, WithLanguages(langs...))
+	}
+	for _, d := range []struct {
+		name, value string
+		option      func(time.Duration) Option
+	}{
+		{"timeout", cfg.Timeout, WithTimeout},
+		{"request_timeout", cfg.RequestTimeout, WithRequestTimeout},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s: %w", d.name, err)
+		}
+		opts = append(opts, d.option(v))
+	}
+	if cfg.Endpoint != "" {
+		opts = append(opts, WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.SampleRate < 0 {
+		return nil, fmt.Errorf("Invalid sample_rate %d", cfg.SampleRate)
+	}
+	if cfg.SampleRate > 0 {
+		opts = append(opts, WithInputSampleRate(cfg.SampleRate))
+	}
+	if cfg.ConfidenceThreshold != 0 {
+		opts = append(opts, WithConfidenceThreshold(cfg.ConfidenceThreshold))
+	}
+	if cfg.MaxConcurrency != 0 {
+		opts = append(opts, WithMaxConcurrency(cfg.MaxConcurrency))
+	}
+	return opts, nil
+}
+
+// NewClient returns a Client with the key and options of cfg, followed by
+// opts.
+func (cfg *Config) NewClient(opts ...Option) (*Client, error) {
+	if cfg.Key == "" {
+		return nil, errors.New("No API key configured")
+	}
+	cfgOpts, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(cfg.Key, append(cfgOpts, opts...)...), nil
+}
+
+// LoadConfig returns a Client configured by the JSON file at path, as read
+// by ReadConfig, and opts.
+func LoadConfig(path string, opts ...Option) (*Client, error) {
+	cfg, err := ReadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.NewClient(opts...)
+}
+
+// FromEnv returns a Client configured by the environment, as read by
+// EnvConfig, and opts.
+func FromEnv(opts ...Option) (*Client, error) {
+	cfg, err := EnvConfig()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.NewClient(opts...)
+}
@@ -0,0 +1,51 @@
+package gorec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gorec.json")
+	os.WriteFile(path, []byte(`{"key": "k", "languages": ["en-US", "es"], "timeout": "30s", "request_timeout": "5s", "sample_rate": 8000}`), 0o644)
+	c, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	us, _ := LocaleFromCode("en-US")
+	if want := []Language{us, Spanish}; !reflect.DeepEqual(c.Languages(), want) {
+		t.Errorf("Languages = %v, want %v", c.Languages(), want)
+	}
+	if c.key != "k" || c.cfg.timeout != 30*time.Second || c.cfg.requestTimeout != 5*time.Second || c.cfg.inputRate != 8000 {
+		t.Errorf("key %q, timeouts %v %v, rate %d", c.key, c.cfg.timeout, c.cfg.requestTimeout, c.cfg.inputRate)
+	}
+
+	for body, want := range map[string]string{
+		`{"key": "k", "langs": ["en"]}`:     "unknown field",
+		`{"key": "k", "timeout": "soon"}`:   "Invalid timeout",
+		`{"key": "k", "languages": ["xx"]}`: "Unknown language",
+		`{"languages": ["en"]}`:             "No API key",
+	} {
+		os.WriteFile(path, []byte(body), 0o644)
+		if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadConfig of %s = %v, want an error containing %q", body, err, want)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("GOREC_API_KEY", "env-key")
+	t.Setenv("GOREC_LANGS", "fr, de")
+	t.Setenv("GOREC_TIMEOUT", "2s")
+	c, err := FromEnv(WithMaxConcurrency(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Language{French, German}; c.key != "env-key" || c.cfg.timeout != 2*time.Second || c.cfg.maxConcurrency != 2 || !reflect.DeepEqual(c.Languages(), want) {
+		t.Errorf("key %q, timeout %v, concurrency %d, languages %v", c.key, c.cfg.timeout, c.cfg.maxConcurrency, c.Languages())
+	}
+}
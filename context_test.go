@@ -0,0 +1,79 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPackageContextVariants(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	audio := []byte{1, 2, 3, 4}
+	opt := WithBackend(coverageBackend{})
+	calls := map[string]func() error{
+		"ListenBase64Context": func() error {
+			_, err := ListenBase64Context(ctx, "AQIDBA==", "k", opt)
+			return err
+		},
+		"ListenClipsContext": func() error {
+			_, err := ListenClipsContext(ctx, bytes.NewReader(audio), 2, "k", opt)
+			return err
+		},
+		"ListenChunkedContext": func() error {
+			_, err := ListenChunkedContext(ctx, audio, 2, "k", opt)
+			return err
+		},
+		"ListenFileDetailedContext": func() error {
+			_, err := ListenFileDetailedContext(ctx, audio, "k", opt)
+			return err
+		},
+		"LanguageRankingContext": func() error {
+			_, err := LanguageRankingContext(ctx, audio, "k", opt)
+			return err
+		},
+		"ListenReaderContext": func() error {
+			_, err := ListenReaderContext(ctx, bytes.NewReader(audio), "k", opt)
+			return err
+		},
+		"ListenLiveContext": func() error {
+			_, err := ListenLiveContext(ctx, bytes.NewReader(audio), "k", opt)
+			return err
+		},
+		"DetectLanguageContext": func() error {
+			_, _, err := DetectLanguageContext(ctx, audio, "k", opt)
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s with a cancelled context = %v", name, err)
+		}
+	}
+}
+
+func TestCancelledContextSendsNothing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	transport := &countingTransport{}
+	c := NewClient("k", WithHTTPClient(&http.Client{Transport: transport}))
+	defer c.Close()
+	if _, err := c.ListenFileContext(ctx, []byte{1, 2}); err != context.Canceled {
+		t.Errorf("ListenFileContext = %v", err)
+	}
+	if _, err := c.ListenFileAllContext(ctx, []byte{1, 2}); err != context.Canceled {
+		t.Errorf("ListenFileAllContext = %v", err)
+	}
+	if _, err := c.RecognizeContext(ctx, []byte{1, 2}, English); !errors.Is(err, context.Canceled) {
+		t.Errorf("RecognizeContext = %v", err)
+	}
+	if _, err := c.ListenReaderAtContext(ctx, bytes.NewReader([]byte{1, 2}), 2); err != context.Canceled {
+		t.Errorf("ListenReaderAtContext = %v", err)
+	}
+	if n := atomic.LoadInt32(&transport.requests); n != 0 {
+		t.Errorf("%d requests sent with a cancelled context", n)
+	}
+}
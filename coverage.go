@@ -0,0 +1,52 @@
+package gorec
+
+import "sync"
+
+// Coverage sorts the queried languages by outcome: a transcript, a successful
+// response without any, an error, or no answer before the call returned.
+// Calls sharing a Coverage each replace the report whole; read it with
+// Snapshot while they may still be running.
+type Coverage struct {
+	Recognized []Language
+	Empty      []Language
+	Failed     []Language
+	Unanswered []Language
+
+	mu sync.Mutex
+}
+
+// Snapshot returns a copy of the latest report.
+func (cov *Coverage) Snapshot() Coverage {
+	cov.mu.Lock()
+	defer cov.mu.Unlock()
+	return Coverage{
+		Recognized: cov.Recognized,
+		Empty:      cov.Empty,
+		Failed:     cov.Failed,
+		Unanswered: cov.Unanswered,
+	}
+}
+
+func (cov *Coverage) fill(languages []Language, hs []Hypothesis) {
+	var recognized, empty, failed, unanswered []Language
+	answered := make(map[Language]bool, len(hs))
+	for _, h := range hs {
+		answered[h.Language] = true
+		switch {
+		case h.Err == nil && h.Alternative.Transcript != "":
+			recognized = append(recognized, h.Language)
+		case h.Err == nil || h.Err == ErrNoSpeech:
+			empty = append(empty, h.Language)
+		default:
+			failed = append(failed, h.Language)
+		}
+	}
+	for _, lang := range languages {
+		if !answered[lang] {
+			unanswered = append(unanswered, lang)
+		}
+	}
+	cov.mu.Lock()
+	defer cov.mu.Unlock()
+	cov.Recognized, cov.Empty, cov.Failed, cov.Unanswered = recognized, empty, failed, unanswered
+}
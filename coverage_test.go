@@ -0,0 +1,42 @@
+package gorec
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+)
+
+type coverageBackend struct{}
+
+func (coverageBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	if lang == German {
+		return &GoogleResponse{}, nil
+	}
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{{Transcript: "hi", Confidence: 0.5}}, Final: true}}}, nil
+}
+
+func TestCoverageConcurrent(t *testing.T) {
+	var cov Coverage
+	c := NewClient("k", WithBackend(coverageBackend{}), WithCoverage(&cov))
+	defer c.Close()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := c.ListenFile([]byte{1, 2}); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			cov.Snapshot()
+		}()
+	}
+	wg.Wait()
+	got := cov.Snapshot()
+	if len(got.Recognized) != 5 || len(got.Empty) != 1 || got.Empty[0] != German {
+		t.Errorf("Snapshot() = %v recognized, %v empty", got.Recognized, got.Empty)
+	}
+}
@@ -0,0 +1,67 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Decoder converts an audio file of any format it knows, such as MP3 or Ogg
+// Opus, into 16 kHz, 16-bit mono linear PCM, the format sent by default.
+type Decoder interface {
+	Decode(ctx context.Context, path string) ([]byte, error)
+}
+
+// FFmpeg is a Decoder running the ffmpeg command. Binary is the command to
+// run, "ffmpeg" from the PATH if empty.
+type FFmpeg struct {
+	Binary string
+}
+
+// ConvertToPCM decodes the audio file at path with FFmpeg.
+func ConvertToPCM(path string) ([]byte, error) {
+	return FFmpeg{}.Decode(context.Background(), path)
+}
+
+func (f FFmpeg) Decode(ctx context.Context, path string) ([]byte, error) {
+	return f.run(ctx, path, nil)
+}
+
+// Convert decodes audio in any format ffmpeg recognizes, to be used as a
+// Pipeline stage.
+func (f FFmpeg) Convert(audio []byte) ([]byte, error) {
+	return f.run(context.Background(), "pipe:0", audio)
+}
+
+func (f FFmpeg) run(ctx context.Context, input string, stdin []byte) ([]byte, error) {
+	binary := f.Binary
+	if binary == "" {
+		binary = "ffmpeg"
+	}
+	args := []string{"-hide_banner", "-loglevel", "error"}
+	if stdin == nil {
+		args = append(args, "-nostdin")
+	}
+	args = append(args, "-i", input,
+		"-f", "s16le", "-acodec", "pcm_s16le", "-ac", "1", "-ar", strconv.Itoa(defaultSampleRate),
+		"pipe:1")
+	cmd := exec.CommandContext(ctx, binary, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("ffmpeg: %s", strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
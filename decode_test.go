@@ -0,0 +1,70 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeFFmpeg writes a program that records its arguments and standard input
+// in dir and outputs "pcm".
+func fakeFFmpeg(t *testing.T, dir string) string {
+	script := `#!/bin/sh
+echo "$@" > ` + dir + `/args
+cat > ` + dir + `/stdin
+printf pcm
+`
+	path := filepath.Join(dir, "ffmpeg")
+	if err := os.WriteFile(path, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFFmpegDecode(t *testing.T) {
+	dir := t.TempDir()
+	f := FFmpeg{Binary: fakeFFmpeg(t, dir)}
+	pcm, err := f.Decode(context.Background(), "in.mp3")
+	if err != nil || string(pcm) != "pcm" {
+		t.Fatalf("Decode = %q, %v", pcm, err)
+	}
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if want := "-hide_banner -loglevel error -nostdin -i in.mp3 -f s16le -acodec pcm_s16le -ac 1 -ar 16000 pipe:1\n"; string(args) != want {
+		t.Errorf("args %q, want %q", args, want)
+	}
+}
+
+func TestFFmpegConvert(t *testing.T) {
+	dir := t.TempDir()
+	f := FFmpeg{Binary: fakeFFmpeg(t, dir)}
+	pcm, err := f.Convert([]byte("ID3 mp3 data"))
+	if err != nil || string(pcm) != "pcm" {
+		t.Fatalf("Convert = %q, %v", pcm, err)
+	}
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if want := "-hide_banner -loglevel error -i pipe:0 -f s16le -acodec pcm_s16le -ac 1 -ar 16000 pipe:1\n"; string(args) != want {
+		t.Errorf("args %q, want %q", args, want)
+	}
+	if stdin, _ := os.ReadFile(filepath.Join(dir, "stdin")); string(stdin) != "ID3 mp3 data" {
+		t.Errorf("stdin %q", stdin)
+	}
+}
+
+func TestFFmpegErrors(t *testing.T) {
+	dir := t.TempDir()
+	missing := FFmpeg{Binary: filepath.Join(dir, "missing")}
+	if _, err := missing.Decode(context.Background(), "in.mp3"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing binary: err = %v", err)
+	}
+
+	failing := filepath.Join(dir, "failing")
+	os.WriteFile(failing, []byte("#!/bin/sh\necho 'in.mp3: Invalid data found when processing input' >&2\nexit 1\n"), 0700)
+	_, err := FFmpeg{Binary: failing}.Decode(context.Background(), "in.mp3")
+	if err == nil || !strings.Contains(err.Error(), "Invalid data found") {
+		t.Errorf("failing binary: err = %v", err)
+	}
+}
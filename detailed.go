@@ -0,0 +1,89 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"time"
+)
+
+// DetailedResult is the winning Hypothesis together with everything Google
+// returned for its language.
+type DetailedResult struct {
+	Hypothesis   Hypothesis    `json:"hypothesis"`
+	Alternatives []Alternative `json:"alternatives"`
+	Final        bool          `json:"final"`
+	Duration     time.Duration `json:"duration"`
+
+	// ResultIndex is the position in the response's Results of the result
+	// Alternatives and Final come from. StreamResultIndex is the
+	// result_index Google's streaming responses carry, the index of their
+	// first result in the whole stream, and 0 in unary responses.
+	ResultIndex       int `json:"result_index"`
+	StreamResultIndex int `json:"stream_result_index"`
+
+	// SampleRate is the rate declared to Google in the Content-Type, zero if
+	// none was. A rate that doesn't match the audio yields garbage transcripts.
+	SampleRate int `json:"sample_rate"`
+
+	// AlternativeIndex is the position of the chosen alternative within
+	// Alternatives. WasTopAlternative reports whether it is the one Google
+	// ranked first, the first alternative of the first result.
+	AlternativeIndex  int  `json:"alternative_index"`
+	WasTopAlternative bool `json:"was_top_alternative"`
+
+	// RawTranscript is the transcript as Google returned it, and
+	// NormalizedTranscript the one the Client's Normalizer made of it. They
+	// are the same when no Normalizer is set.
+	RawTranscript        string `json:"raw_transcript"`
+	NormalizedTranscript string `json:"normalized_transcript"`
+
+	// Segments has the best alternative of every result in the response,
+	// not only of the one chosen.
+	Segments []Segment `json:"segments"`
+}
+
+func ListenFileDetailed(audio []byte, key string, opts ...Option) (*DetailedResult, error) {
+	return NewClient(key, opts...).ListenFileDetailed(audio)
+}
+
+func ListenFileDetailedContext(ctx context.Context, audio []byte, key string, opts ...Option) (*DetailedResult, error) {
+	return NewClient(key, opts...).ListenFileDetailedContext(ctx, audio)
+}
+
+func (c *Client) ListenFileDetailed(audio []byte, opts ...Option) (*DetailedResult, error) {
+	return c.ListenFileDetailedContext(context.Background(), audio, opts...)
+}
+
+// ListenFileDetailedContext is ListenFileDetailed abandoning the requests in
+// flight once ctx is done.
+func (c *Client) ListenFileDetailedContext(ctx context.Context, audio []byte, opts ...Option) (*DetailedResult, error) {
+	c = c.with(opts)
+	c, audio, err := c.prepare(audio)
+	if err != nil {
+		return nil, err
+	}
+	start := c.cfg.clock.Now()
+	h, err := c.listenBest(ctx, bytes.NewReader(audio), int64(len(audio)))
+	if err != nil {
+		return nil, err
+	}
+	d := &DetailedResult{
+		Hypothesis:           *h,
+		Duration:             c.cfg.clock.Now().Sub(start),
+		SampleRate:           contentTypeRate(c.cfg.contentType),
+		RawTranscript:        h.rawTranscript,
+		NormalizedTranscript: h.Alternative.Transcript,
+	}
+	if gr := h.response; gr != nil {
+		d.Segments = gr.Segments()
+	}
+	if gr := h.response; gr != nil && h.result >= 0 {
+		d.Alternatives = gr.Results[h.result].Alternatives
+		d.Final = gr.Results[h.result].Final
+		d.ResultIndex = h.result
+		d.StreamResultIndex = gr.ResultIndex
+		d.AlternativeIndex = h.alternative
+		d.WasTopAlternative = h.result == 0 && h.alternative == 0
+	}
+	return d, nil
+}
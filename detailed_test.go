@@ -0,0 +1,53 @@
+package gorec
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+type mixedCaseBackend struct{}
+
+func (mixedCaseBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{{Transcript: "Hello World", Confidence: 0.8}}, Final: true}}}, nil
+}
+
+func TestDetailedTranscripts(t *testing.T) {
+	d, err := ListenFileDetailed([]byte{1, 2}, "k", WithBackend(mixedCaseBackend{}), WithNormalizer(strings.ToLower))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.RawTranscript != "Hello World" || d.NormalizedTranscript != "hello world" {
+		t.Errorf("with a normalizer: raw %q, normalized %q", d.RawTranscript, d.NormalizedTranscript)
+	}
+	d, err = ListenFileDetailed([]byte{1, 2}, "k", WithBackend(mixedCaseBackend{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.RawTranscript != "Hello World" || d.NormalizedTranscript != "Hello World" {
+		t.Errorf("without a normalizer: raw %q, normalized %q", d.RawTranscript, d.NormalizedTranscript)
+	}
+}
+
+type twoResultsBackend struct{}
+
+func (twoResultsBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	return &GoogleResponse{ResultIndex: 3, Results: []Result{
+		{Alternatives: []Alternative{{Transcript: "hello", Confidence: 0.4}}, Final: true},
+		{Alternatives: []Alternative{{Transcript: "hello world", Confidence: 0.9}}, Final: true},
+	}}, nil
+}
+
+func TestDetailedResultIndex(t *testing.T) {
+	d, err := ListenFileDetailed([]byte{1, 2}, "k", WithBackend(twoResultsBackend{}), WithLanguages(English))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Hypothesis.Alternative.Transcript != "hello world" || d.ResultIndex != 1 || d.Alternatives[0].Transcript != "hello world" {
+		t.Errorf("won %q from result %d with alternatives %v, want result 1", d.Hypothesis.Alternative.Transcript, d.ResultIndex, d.Alternatives)
+	}
+	if d.StreamResultIndex != 3 || d.WasTopAlternative {
+		t.Errorf("StreamResultIndex %d, WasTopAlternative %v", d.StreamResultIndex, d.WasTopAlternative)
+	}
+}
@@ -0,0 +1,127 @@
+package gorec
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// SplitChannels deinterleaves 16-bit PCM of the given number of channels
+// into one mono PCM per channel. A trailing partial frame is dropped.
+func SplitChannels(pcm []byte, channels int) [][]byte {
+	if channels < 1 {
+		return nil
+	}
+	frames := len(pcm) / (2 * channels)
+	out := make([][]byte, channels)
+	for ch := range out {
+		out[ch] = make([]byte, 2*frames)
+		for i := 0; i < frames; i++ {
+			binary.LittleEndian.PutUint16(out[ch][2*i:], binary.LittleEndian.Uint16(pcm[2*(i*channels+ch):]))
+		}
+	}
+	return out
+}
+
+// Downmix mixes 16-bit PCM of the given number of interleaved channels
+// down to mono, averaging each frame. A trailing partial frame is dropped.
+func Downmix(pcm []byte, channels int) []byte {
+	if channels <= 1 {
+		return pcm
+	}
+	frames := len(pcm) / (2 * channels)
+	out := make([]byte, 2*frames)
+	for i := 0; i < frames; i++ {
+		var sum int
+		for ch := 0; ch < channels; ch++ {
+			sum += int(int16(binary.LittleEndian.Uint16(pcm[2*(i*channels+ch):])))
+		}
+		binary.LittleEndian.PutUint16(out[2*i:], uint16(int16(sum/channels)))
+	}
+	return out
+}
+
+func ListenChannels(audio []byte, channels int, key string, opts ...Option) ([]Hypothesis, error) {
+	return NewClient(key, opts...).ListenChannels(audio, channels)
+}
+
+func ListenChannelsContext(ctx context.Context, audio []byte, channels int, key string, opts ...Option) ([]Hypothesis, error) {
+	return NewClient(key, opts...).ListenChannelsContext(ctx, audio, channels)
+}
+
+// ListenChannels tells speakers apart in recordings that have each of them
+// on a channel of their own, such as stereo call recordings. audio is a WAV
+// file, whose header gives the channels, or interleaved 16-bit PCM of the
+// given number of channels, or of WithInputChannels if channels is zero.
+// Every channel is recognized with ListenLong, all in parallel, and the
+// Words of its hypothesis are labelled with the channel, counted from 1, as
+// their Speaker. Channels where nobody spoke have ErrNoSpeech as their Err.
+// Use SpeakerWords to interleave the words.
+func (c *Client) ListenChannels(audio []byte, channels int, opts ...Option) ([]Hypothesis, error) {
+	return c.ListenChannelsContext(context.Background(), audio, channels, opts...)
+}
+
+// ListenChannelsContext is ListenChannels abandoning the requests in flight
+// once ctx is done.
+func (c *Client) ListenChannelsContext(ctx context.Context, audio []byte, channels int, opts ...Option) ([]Hypothesis, error) {
+	c = c.with(opts)
+	if len(audio) == 0 {
+		return nil, ErrEmptyAudio
+	}
+	if isWAV(audio) {
+		w, err := parseWAV(audio, true)
+		if err != nil {
+			return nil, err
+		}
+		audio, channels = w.Data, w.Channels
+		c = c.with([]Option{WithInputSampleRate(w.SampleRate)})
+	}
+	if channels == 0 {
+		channels = c.cfg.inputChannels
+	}
+	if channels < 1 {
+		return nil, fmt.Errorf("Invalid channel count %d", channels)
+	}
+	parts := SplitChannels(audio, channels)
+	// The parts are mono, whatever WithInputChannels said of audio.
+	c = c.with([]Option{WithInputChannels(1)})
+	hs := make([]Hypothesis, channels)
+	errs := make([]error, channels)
+	var wg sync.WaitGroup
+	for i, part := range parts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h, err := c.ListenLongContext(ctx, part)
+			if err != nil {
+				hs[i], errs[i] = Hypothesis{Err: err}, err
+				return
+			}
+			for j := range h.Alternative.Words {
+				h.Alternative.Words[j].Speaker = i + 1
+			}
+			hs[i] = *h
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil && !errors.Is(err, ErrNoSpeech) {
+			return nil, fmt.Errorf("Channel %d: %w", i+1, err)
+		}
+	}
+	return hs, nil
+}
+
+// SpeakerWords interleaves the Words of hs by when they start, as a
+// conversation transcript.
+func SpeakerWords(hs []Hypothesis) []Word {
+	var words []Word
+	for _, h := range hs {
+		words = append(words, h.Alternative.Words...)
+	}
+	sort.SliceStable(words, func(i, j int) bool { return words[i].Start < words[j].Start })
+	return words
+}
@@ -0,0 +1,82 @@
+package gorec
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// interleave makes stereo PCM of two mono ones of the same length.
+func interleave(left, right []byte) []byte {
+	var b bytes.Buffer
+	for i := 0; i+1 < len(left); i += 2 {
+		b.Write(left[i : i+2])
+		b.Write(right[i : i+2])
+	}
+	return b.Bytes()
+}
+
+func TestSplitChannels(t *testing.T) {
+	got := SplitChannels([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9}, 2)
+	if len(got) != 2 || !bytes.Equal(got[0], []byte{1, 2, 5, 6}) || !bytes.Equal(got[1], []byte{3, 4, 7, 8}) {
+		t.Errorf("SplitChannels = %v", got)
+	}
+}
+
+func TestDownmix(t *testing.T) {
+	stereo := []byte{0x10, 0x00, 0x30, 0x00, 0x00, 0x80, 0x00, 0x80, 9}
+	if got := Downmix(stereo, 2); !bytes.Equal(got, []byte{0x20, 0x00, 0x00, 0x80}) {
+		t.Errorf("Downmix = %v", got)
+	}
+	if got := Downmix(stereo, 1); !bytes.Equal(got, stereo) {
+		t.Errorf("Downmix of mono = %v", got)
+	}
+}
+
+func TestListenFileDownmixes(t *testing.T) {
+	left := speechPCM(3*time.Second, [2]time.Duration{0, 3 * time.Second})
+	stereo := interleave(left, left)
+	for name, c := range map[string]struct {
+		audio []byte
+		opts  []Option
+	}{
+		"PCM": {stereo, []Option{WithInputChannels(2)}},
+		"WAV": {wav(1, 2, defaultSampleRate, 16, stereo), nil},
+	} {
+		h, err := ListenFile(c.audio, "k", append(c.opts, WithBackend(durationBackend{}), WithLanguages(English))...)
+		if err != nil || h.Alternative.Transcript != "s3" {
+			t.Errorf("%s: ListenFile = %v, %v", name, h, err)
+		}
+	}
+	hs, err := ListenChannels(stereo, 0, "k", WithInputChannels(2), WithBackend(durationBackend{}), WithLanguages(English))
+	if err != nil || len(hs) != 2 || hs[0].Alternative.Transcript != "s3" {
+		t.Errorf("ListenChannels with WithInputChannels = %v, %v", hs, err)
+	}
+}
+
+func TestListenChannels(t *testing.T) {
+	left := speechPCM(6*time.Second, [2]time.Duration{0, 2 * time.Second})
+	right := speechPCM(6*time.Second, [2]time.Duration{3 * time.Second, 4 * time.Second})
+	for name, audio := range map[string][]byte{
+		"PCM": interleave(left, right),
+		"WAV": wav(1, 2, defaultSampleRate, 16, interleave(left, right)),
+	} {
+		hs, err := ListenChannels(audio, 2, "k", WithBackend(durationBackend{}), WithLanguages(English))
+		if err != nil || len(hs) != 2 {
+			t.Fatalf("%s: ListenChannels = %v, %v", name, hs, err)
+		}
+		words := SpeakerWords([]Hypothesis{hs[1], hs[0]})
+		if len(words) != 2 || words[0].Word != "s2" || words[0].Speaker != 1 || words[1].Word != "s1" || words[1].Speaker != 2 {
+			t.Errorf("%s: SpeakerWords = %+v", name, words)
+		}
+	}
+}
+
+func TestListenChannelsSilentChannel(t *testing.T) {
+	left := speechPCM(time.Second, [2]time.Duration{0, time.Second})
+	hs, err := ListenChannels(interleave(left, make([]byte, len(left))), 2, "k", WithBackend(durationBackend{}), WithLanguages(English))
+	if err != nil || hs[0].Err != nil || !errors.Is(hs[1].Err, ErrNoSpeech) {
+		t.Errorf("ListenChannels = %v, %v", hs, err)
+	}
+}
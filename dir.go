@@ -0,0 +1,75 @@
+package gorec
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// defaultDirWorkers is how many files ListenDir recognizes at once unless
+// WithMaxConcurrency says otherwise.
+const defaultDirWorkers = 4
+
+// audioExtensions are the files ListenDir picks without WithFilePattern.
+var audioExtensions = map[string]bool{".wav": true, ".flac": true, ".raw": true, ".pcm": true, ".l16": true}
+
+func ListenDir(ctx context.Context, dir string, key string, opts ...Option) (map[string]Hypothesis, error) {
+	return NewClient(key, opts...).ListenDir(ctx, dir)
+}
+
+// ListenDir recognizes every audio file under dir, its subdirectories
+// included, at most WithMaxConcurrency files at a time, four by default.
+// Files are picked by their extension, .wav, .flac, .raw, .pcm or .l16,
+// unless WithFilePattern is given. The hypotheses are keyed by the path of
+// their file relative to dir; those of the files that failed have Err set.
+//
+// Cancelling ctx stops new files from being started and aborts those in
+// flight, returning the files that did complete along with ctx.Err(). To
+// resume, pass what a previous call returned to WithResume: the files it
+// recognized are kept rather than recognized again.
+func (c *Client) ListenDir(ctx context.Context, dir string, opts ...Option) (map[string]Hypothesis, error) {
+	c = c.with(opts)
+	results := make(map[string]Hypothesis)
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !c.matchesFile(d.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if h, ok := c.cfg.resume[rel]; ok && h.Err == nil {
+			results[rel] = h
+			return nil
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return results, err
+	}
+	workers := c.cfg.maxConcurrency
+	if workers <= 0 {
+		workers = defaultDirWorkers
+	}
+	recognized, err := c.RecognizeBatch(ctx, paths, workers)
+	for path, h := range recognized {
+		rel, _ := filepath.Rel(dir, path)
+		results[rel] = h
+	}
+	return results, err
+}
+
+// matchesFile reports whether ListenDir recognizes the file called name.
+func (c *Client) matchesFile(name string) bool {
+	if c.cfg.filePattern != "" {
+		ok, _ := filepath.Match(c.cfg.filePattern, name)
+		return ok
+	}
+	return audioExtensions[strings.ToLower(filepath.Ext(name))]
+}
@@ -0,0 +1,81 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// echoBackend transcribes audio as its bytes, failing on "fail".
+type echoBackend struct {
+	calls int32
+}
+
+func (b *echoBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	atomic.AddInt32(&b.calls, 1)
+	content, err := io.ReadAll(audio)
+	if err != nil {
+		return nil, err
+	}
+	if string(content) == "fail" {
+		return nil, errors.New("Backend failed")
+	}
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{{Transcript: string(content), Confidence: 0.9}}, Final: true}}}, nil
+}
+
+func TestListenDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.raw":          "first",
+		"sub/b.PCM":      "second",
+		"sub/deep/c.l16": "fail",
+		"notes.txt":      "skipped",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b := &echoBackend{}
+	c := NewClient("k", WithBackend(b), WithLanguages(English), WithIncludeErrors(true))
+	res, err := c.ListenDir(context.Background(), dir, WithMaxConcurrency(2))
+	if err != nil || len(res) != 3 {
+		t.Fatalf("ListenDir = %v, %v", res, err)
+	}
+	if h := res["a.raw"]; h.Alternative.Transcript != "first" || h.Source != filepath.Join(dir, "a.raw") || h.Duration != 2*time.Second/16000 {
+		t.Errorf("a.raw = %+v", h)
+	}
+	if got := res[filepath.Join("sub", "b.PCM")].Alternative.Transcript; got != "second" {
+		t.Errorf("sub/b.PCM = %q", got)
+	}
+	failed := filepath.Join("sub", "deep", "c.l16")
+	if res[failed].Err == nil {
+		t.Errorf("%s did not fail: %v", failed, res[failed])
+	}
+
+	// Resuming retries the failed file only.
+	os.WriteFile(filepath.Join(dir, failed), []byte("third"), 0644)
+	b.calls = 0
+	res, err = c.ListenDir(context.Background(), dir, WithResume(res))
+	if err != nil || len(res) != 3 || res[failed].Alternative.Transcript != "third" || res["a.raw"].Alternative.Transcript != "first" {
+		t.Fatalf("resumed ListenDir = %v, %v", res, err)
+	}
+	if b.calls != 1 {
+		t.Errorf("resuming made %d requests, want 1", b.calls)
+	}
+
+	res, err = c.ListenDir(context.Background(), dir, WithFilePattern("*.txt"))
+	if err != nil || len(res) != 1 || res["notes.txt"].Alternative.Transcript != "skipped" {
+		t.Errorf("ListenDir with a pattern = %v, %v", res, err)
+	}
+	if _, err := c.ListenDir(context.Background(), filepath.Join(dir, "missing")); err == nil {
+		t.Error("ListenDir of a missing directory succeeded")
+	}
+}
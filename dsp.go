@@ -0,0 +1,210 @@
+package gorec
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// errOddPCM is the error of stages given a partial 16-bit sample.
+var errOddPCM = errors.New("PCM of odd length")
+
+// samples decodes 16-bit little-endian PCM into samples between -1 and 1.
+func samples(pcm []byte) ([]float64, error) {
+	if len(pcm)%2 != 0 {
+		return nil, errOddPCM
+	}
+	s := make([]float64, len(pcm)/2)
+	for i := range s {
+		s[i] = float64(int16(binary.LittleEndian.Uint16(pcm[2*i:]))) / 32768
+	}
+	return s, nil
+}
+
+// encodePCM is the inverse of samples, clipping what is out of range.
+func encodePCM(s []float64) []byte {
+	pcm := make([]byte, 2*len(s))
+	for i, v := range s {
+		v = math.Round(v * 32768)
+		v = math.Max(-32768, math.Min(32767, v))
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(int16(v)))
+	}
+	return pcm
+}
+
+// processPCM runs process on the samples of audio, 16-bit mono PCM, raw or
+// in a WAV file, as the stages run before WAV files are parsed.
+func processPCM(audio []byte, process func([]float64) []float64) ([]byte, error) {
+	if isWAV(audio) {
+		w, err := ParseWAV(audio)
+		if err != nil {
+			return nil, err
+		}
+		if w.Data, err = processPCM(w.Data, process); err != nil {
+			return nil, err
+		}
+		return w.Bytes(), nil
+	}
+	s, err := samples(audio)
+	if err != nil {
+		return nil, err
+	}
+	return encodePCM(process(s)), nil
+}
+
+// RemoveDC is a Stage removing the constant offset some microphones add
+// to 16-bit PCM, centering it on zero.
+func RemoveDC(audio []byte) ([]byte, error) {
+	return processPCM(audio, func(s []float64) []float64 {
+		var mean float64
+		for _, v := range s {
+			mean += v / float64(len(s))
+		}
+		for i := range s {
+			s[i] -= mean
+		}
+		return s
+	})
+}
+
+// maxGain is the most NormalizeGain amplifies, 30 dB, so that silence is
+// not raised into noise.
+const maxGain = 31.6
+
+// NormalizeGain returns a Stage scaling 16-bit PCM for its loudest sample
+// to peak at peakDB decibels relative to full scale, such as -1. Quiet
+// recordings are amplified by at most 30 dB; silence is left alone.
+func NormalizeGain(peakDB float64) Stage {
+	target := math.Pow(10, peakDB/20)
+	return func(audio []byte) ([]byte, error) {
+		return processPCM(audio, func(s []float64) []float64 {
+			var peak float64
+			for _, v := range s {
+				peak = math.Max(peak, math.Abs(v))
+			}
+			if peak == 0 {
+				return s
+			}
+			gain := math.Min(target/peak, maxGain)
+			for i := range s {
+				s[i] *= gain
+			}
+			return s
+		})
+	}
+}
+
+// The frames ReduceNoise analyses, in samples, overlapping by half.
+const (
+	noiseFrame = 512
+	noiseHop   = noiseFrame / 2
+)
+
+// ReduceNoise is a Stage reducing steady background noise, such as hum or
+// hiss, in 16-bit PCM by spectral subtraction: the spectrum of the
+// quietest tenth of the audio is taken as the noise's and subtracted from
+// every frame. Audio shorter than a few frames is left alone.
+func ReduceNoise(audio []byte) ([]byte, error) {
+	return processPCM(audio, reduceNoise)
+}
+
+func reduceNoise(s []float64) []float64 {
+	n := len(s)
+	frames := (n+noiseHop-1)/noiseHop + 1
+	if frames < 8 {
+		return s
+	}
+	// The signal is padded by a hop on either side so that every sample
+	// falls in two frames, whose windows add up to one.
+	padded := make([]float64, (frames+1)*noiseHop)
+	copy(padded[noiseHop:], s)
+	window := make([]float64, noiseFrame)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/noiseFrame)
+	}
+
+	spectra := make([][]complex128, frames)
+	energy := make([]float64, frames)
+	for f := range spectra {
+		x := make([]complex128, noiseFrame)
+		for i := range x {
+			x[i] = complex(padded[f*noiseHop+i]*window[i], 0)
+		}
+		fft(x, false)
+		for _, c := range x {
+			energy[f] += real(c)*real(c) + imag(c)*imag(c)
+		}
+		spectra[f] = x
+	}
+
+	quietest := make([]int, frames)
+	for i := range quietest {
+		quietest[i] = i
+	}
+	sort.Slice(quietest, func(i, j int) bool { return energy[quietest[i]] < energy[quietest[j]] })
+	quietest = quietest[:max(1, frames/10)]
+	noise := make([]float64, noiseFrame)
+	for _, f := range quietest {
+		for i, c := range spectra[f] {
+			noise[i] += cmplx.Abs(c) / float64(len(quietest))
+		}
+	}
+
+	// Subtracting a little more than the noise clears what is left of it,
+	// and keeping a floor of each bin avoids the warbling of empty bins.
+	const overSubtract, floor = 1.5, 0.05
+	out := make([]float64, len(padded))
+	for f, x := range spectra {
+		for i, c := range x {
+			mag := cmplx.Abs(c)
+			if mag == 0 {
+				continue
+			}
+			kept := math.Max(mag-overSubtract*noise[i], floor*mag)
+			x[i] = c * complex(kept/mag, 0)
+		}
+		fft(x, true)
+		for i, c := range x {
+			out[f*noiseHop+i] += real(c)
+		}
+	}
+	return out[noiseHop : noiseHop+n]
+}
+
+// fft transforms x in place, its length a power of two, or transforms it
+// back if inverse.
+func fft(x []complex128, inverse bool) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], x[start+k+size/2]*w
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+	if inverse {
+		for i := range x {
+			x[i] /= complex(float64(n), 0)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package gorec
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// tone returns n samples at 16 kHz of offset and white noise, with a 440 Hz
+// sine of amplitude over the second half.
+func tone(n int, amplitude, offset, noise float64, rng *rand.Rand) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = offset
+		if noise > 0 {
+			s[i] += noise * rng.NormFloat64()
+		}
+		if i >= n/2 {
+			s[i] += amplitude * math.Sin(2*math.Pi*440*float64(i)/16000)
+		}
+	}
+	return s
+}
+
+func rmsOf(s []float64) float64 {
+	var sum float64
+	for _, v := range s {
+		sum += v * v
+	}
+	return math.Sqrt(sum / float64(len(s)))
+}
+
+func TestRemoveDC(t *testing.T) {
+	out, err := RemoveDC(encodePCM(tone(16000, 0.3, 0.2, 0, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := samples(out)
+	var mean float64
+	for _, v := range s {
+		mean += v / float64(len(s))
+	}
+	if math.Abs(mean) > 1e-3 {
+		t.Errorf("mean %v after RemoveDC", mean)
+	}
+}
+
+func TestNormalizeGain(t *testing.T) {
+	out, err := NormalizeGain(-1)(encodePCM(tone(16000, 0.05, 0, 0, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := samples(out)
+	var peak float64
+	for _, v := range s {
+		peak = math.Max(peak, math.Abs(v))
+	}
+	if want := math.Pow(10, -1.0/20); math.Abs(peak-want) > 1e-3 {
+		t.Errorf("peak %v, want %v", peak, want)
+	}
+
+	quiet := encodePCM(tone(1600, 0.0001, 0, 0, nil))
+	out, _ = NormalizeGain(0)(quiet)
+	s, _ = samples(out)
+	if got := rmsOf(s) / rmsOf(mustSamples(t, quiet)); got > maxGain*1.01 {
+		t.Errorf("amplified quiet audio %vx", got)
+	}
+	silence := make([]byte, 100)
+	if out, err := NormalizeGain(-1)(silence); err != nil || string(out) != string(silence) {
+		t.Errorf("NormalizeGain changed silence: %v, %v", out, err)
+	}
+}
+
+func mustSamples(t *testing.T, pcm []byte) []float64 {
+	s, err := samples(pcm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestReduceNoise(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	n := 32000
+	in := tone(n, 0.5, 0, 0.05, rng)
+	out, err := ReduceNoise(encodePCM(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := mustSamples(t, out)
+	if len(s) != n {
+		t.Fatalf("%d samples out, %d in", len(s), n)
+	}
+	if before, after := rmsOf(in[:n/2]), rmsOf(s[:n/2]); after > before/4 {
+		t.Errorf("noise RMS %v after, %v before", after, before)
+	}
+	if before, after := rmsOf(in[n/2:]), rmsOf(s[n/2:]); after < before*0.8 {
+		t.Errorf("speech RMS %v after, %v before", after, before)
+	}
+
+	short := encodePCM(in[:300])
+	if out, err := ReduceNoise(short); err != nil || string(out) != string(short) {
+		t.Errorf("ReduceNoise changed short audio: %v", err)
+	}
+}
+
+func TestFFT(t *testing.T) {
+	x := []complex128{1, 2, 3, 4, 0, -1, 0.5, 2}
+	y := append([]complex128(nil), x...)
+	fft(y, false)
+	if math.Abs(real(y[0])-11.5) > 1e-9 {
+		t.Errorf("DC bin %v, want 11.5", y[0])
+	}
+	fft(y, true)
+	for i := range x {
+		if math.Abs(real(y[i])-real(x[i])) > 1e-9 || math.Abs(imag(y[i])) > 1e-9 {
+			t.Errorf("round trip %v, want %v", y, x)
+			break
+		}
+	}
+}
+
+func TestPreprocess(t *testing.T) {
+	pcm := encodePCM(tone(16000, 0.05, 0.1, 0, nil))
+	out, err := Preprocess(pcm, RemoveDC, NormalizeGain(-3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := mustSamples(t, out)
+	var peak, mean float64
+	for _, v := range s {
+		peak = math.Max(peak, math.Abs(v))
+		mean += v / float64(len(s))
+	}
+	if math.Abs(mean) > 1e-3 || math.Abs(peak-math.Pow(10, -3.0/20)) > 1e-3 {
+		t.Errorf("mean %v and peak %v", mean, peak)
+	}
+	if _, err := Preprocess([]byte{1, 2, 3}, RemoveDC); err == nil {
+		t.Error("preprocessed PCM of odd length")
+	}
+}
+
+func TestStagesKeepWAV(t *testing.T) {
+	w := &WAV{SampleRate: 8000, BitsPerSample: 16, Channels: 1, Data: encodePCM(tone(8000, 0.05, 0.1, 0, nil))}
+	out, err := Preprocess(w.Bytes(), RemoveDC, NormalizeGain(-1), ReduceNoise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseWAV(out)
+	if err != nil || got.SampleRate != 8000 || len(got.Data) != len(w.Data) {
+		t.Errorf("preprocessed WAV %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,233 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Ensemble is a Backend sending the same audio to every one of Backends at
+// once, such as Google and a WhisperBackend, and merging their
+// transcripts. Each is aligned word by word with the most confident one,
+// and every word is then voted on, weighing each backend by its
+// confidence: the merged alternative's Confidence is the share of the
+// votes its words won on average. The words the backends disagreed on are
+// reported in Result.Disagreements, and from there in
+// Hypothesis.Disagreements, for a person to review.
+//
+// Words only the less confident backends heard are left out. Backends that
+// fail or hear nothing get no vote; the Ensemble fails only if all of them
+// fail.
+type Ensemble struct {
+	Backends []Backend
+}
+
+// Disagreement is a word of a merged transcript the backends of an
+// Ensemble did not agree on. Word is its index among the words of the
+// transcript, where it would have been if the vote dropped it. Heard holds
+// what each backend heard there, in the order of Ensemble.Backends, empty
+// for those that heard no word there, and Chosen the word the vote kept,
+// empty if it dropped it.
+type Disagreement struct {
+	Word   int      `json:"word"`
+	Chosen string   `json:"chosen"`
+	Heard  []string `json:"heard"`
+}
+
+// ensembleVote is the transcript one backend of an Ensemble heard.
+type ensembleVote struct {
+	alt    Alternative
+	words  []string
+	weight float64
+	err    error
+}
+
+func (e *Ensemble) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, params BackendParams) (*GoogleResponse, error) {
+	if len(e.Backends) == 0 {
+		return nil, errors.New("Ensemble has no backends")
+	}
+	b, err := io.ReadAll(audio)
+	if err != nil {
+		return nil, err
+	}
+	votes := make([]ensembleVote, len(e.Backends))
+	var wg sync.WaitGroup
+	for i, backend := range e.Backends {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gr, err := backend.Recognize(ctx, bytes.NewReader(b), int64(len(b)), lang, params)
+			votes[i] = newEnsembleVote(gr, err)
+		}()
+	}
+	wg.Wait()
+
+	pivot := -1
+	var errs []error
+	for i, v := range votes {
+		switch {
+		case v.err != nil:
+			errs = append(errs, v.err)
+		case v.votes() && (pivot < 0 || v.weight > votes[pivot].weight):
+			pivot = i
+		}
+	}
+	if len(errs) == len(votes) {
+		return nil, errors.Join(errs...)
+	}
+	if pivot < 0 {
+		return &GoogleResponse{}, nil
+	}
+	return &GoogleResponse{Results: []Result{mergeVotes(votes, pivot)}}, nil
+}
+
+func newEnsembleVote(gr *GoogleResponse, err error) ensembleVote {
+	if err != nil {
+		return ensembleVote{err: err}
+	}
+	alt := SelectAlternative(gr, true)
+	if alt == nil {
+		return ensembleVote{}
+	}
+	weight := alt.Confidence
+	if weight <= 0 {
+		weight = noConfidenceWeight
+	}
+	return ensembleVote{alt: *alt, words: strings.Fields(alt.Transcript), weight: weight}
+}
+
+// votes reports whether the backend of v heard anything to vote with.
+func (v ensembleVote) votes() bool {
+	return v.err == nil && len(v.words) > 0
+}
+
+// noConfidenceWeight is the weight of the vote of a backend reporting no
+// confidence, as Whisper does.
+const noConfidenceWeight = 0.5
+
+// mergeVotes votes on every word of the transcript of votes[pivot], the
+// others aligned with it.
+func mergeVotes(votes []ensembleVote, pivot int) Result {
+	p := votes[pivot]
+	heard := make([][]string, len(votes))
+	for i, v := range votes {
+		switch {
+		case i == pivot:
+			heard[i] = p.words
+		case v.votes():
+			heard[i] = alignWords(p.words, v.words)
+		}
+	}
+	timed := len(p.alt.Words) == len(p.words)
+	var merged Alternative
+	var kept []string
+	var disagreements []Disagreement
+	var agreement float64
+	for slot := range p.words {
+		weights := map[string]float64{}
+		var total float64
+		unanimous := true
+		for i, v := range votes {
+			if !v.votes() {
+				continue
+			}
+			word := heard[i][slot]
+			key := wordKey(word)
+			weights[key] += v.weight
+			total += v.weight
+			unanimous = unanimous && key == wordKey(p.words[slot])
+		}
+		best := wordKey(p.words[slot])
+		for key, w := range weights {
+			if w > weights[best] {
+				best = key
+			}
+		}
+		agreement += weights[best] / total
+		var chosen string
+		for i := range votes {
+			if votes[i].votes() && wordKey(heard[i][slot]) == best {
+				chosen = heard[i][slot]
+				break
+			}
+		}
+		if !unanimous {
+			d := Disagreement{Word: len(kept), Chosen: chosen, Heard: make([]string, len(votes))}
+			for i := range votes {
+				if heard[i] != nil {
+					d.Heard[i] = heard[i][slot]
+				}
+			}
+			disagreements = append(disagreements, d)
+		}
+		if chosen == "" {
+			continue
+		}
+		kept = append(kept, chosen)
+		if timed {
+			w := p.alt.Words[slot]
+			w.Word = chosen
+			merged.Words = append(merged.Words, w)
+		}
+	}
+	merged.Transcript = strings.Join(kept, " ")
+	merged.Confidence = agreement / float64(len(p.words))
+	return Result{Alternatives: []Alternative{merged}, Final: true, Disagreements: disagreements}
+}
+
+// alignWords aligns words with pivot by edit distance, returning the word
+// of words matched or substituted at each position of pivot, empty where
+// words has none.
+func alignWords(pivot, words []string) []string {
+	n, m := len(pivot), len(words)
+	// cost[i][j] is the distance between pivot[i:] and words[j:].
+	cost := make([][]int, n+1)
+	for i := range cost {
+		cost[i] = make([]int, m+1)
+	}
+	for i := n; i >= 0; i-- {
+		for j := m; j >= 0; j-- {
+			switch {
+			case i == n:
+				cost[i][j] = m - j
+			case j == m:
+				cost[i][j] = n - i
+			default:
+				sub := cost[i+1][j+1]
+				if wordKey(pivot[i]) != wordKey(words[j]) {
+					sub++
+				}
+				cost[i][j] = min(sub, cost[i+1][j]+1, cost[i][j+1]+1)
+			}
+		}
+	}
+	aligned := make([]string, n)
+	for i, j := 0, 0; i < n; {
+		switch {
+		case j < m && cost[i][j] == cost[i+1][j+1]+boolInt(wordKey(pivot[i]) != wordKey(words[j])):
+			aligned[i] = words[j]
+			i, j = i+1, j+1
+		case cost[i][j] == cost[i+1][j]+1:
+			i++
+		default:
+			j++
+		}
+	}
+	return aligned
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// wordKey is word as votes compare it, ignoring case and punctuation.
+func wordKey(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) }))
+}
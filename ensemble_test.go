@@ -0,0 +1,78 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fixedBackend hears transcript with confidence, or fails with err.
+type fixedBackend struct {
+	transcript string
+	confidence float64
+	err        error
+}
+
+func (b fixedBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	if _, err := io.ReadAll(audio); err != nil {
+		return nil, err
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{{Transcript: b.transcript, Confidence: b.confidence}}, Final: true}}}, nil
+}
+
+func TestAlignWords(t *testing.T) {
+	pivot := []string{"the", "cat", "sat", "on", "the", "mat"}
+	got := alignWords(pivot, []string{"The", "cat", "sat", "the", "hat"})
+	if want := []string{"The", "cat", "sat", "", "the", "hat"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("alignWords = %q, want %q", got, want)
+	}
+}
+
+func TestEnsemble(t *testing.T) {
+	e := &Ensemble{Backends: []Backend{
+		fixedBackend{transcript: "turn of the lights please", confidence: 0.9},
+		fixedBackend{transcript: "turn off the lights", confidence: 0.8},
+		fixedBackend{transcript: "Turn off the lights.", confidence: 0.7},
+		fixedBackend{err: errors.New("Down")},
+	}}
+	c := NewClient("k", WithBackend(e), WithLanguages(English))
+	h, err := c.ListenFile([]byte{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Alternative.Transcript != "turn off the lights" {
+		t.Errorf("merged transcript = %q", h.Alternative.Transcript)
+	}
+	// "of" won 1.5 of 2.4 and "please" was dropped by 1.5 to 0.9.
+	if want := (3 + 1.5/2.4 + 1.5/2.4) / 5; math.Abs(h.Alternative.Confidence-want) > 1e-9 {
+		t.Errorf("confidence = %v, want %v", h.Alternative.Confidence, want)
+	}
+	want := []Disagreement{
+		{Word: 1, Chosen: "off", Heard: []string{"of", "off", "off", ""}},
+		{Word: 4, Chosen: "", Heard: []string{"please", "", "", ""}},
+	}
+	if !reflect.DeepEqual(h.Disagreements, want) {
+		t.Errorf("disagreements = %+v, want %+v", h.Disagreements, want)
+	}
+}
+
+func TestEnsembleFailures(t *testing.T) {
+	down := errors.New("Down")
+	e := &Ensemble{Backends: []Backend{fixedBackend{err: down}, fixedBackend{err: ErrQuotaExceeded}}}
+	_, err := e.Recognize(context.Background(), strings.NewReader(""), 0, English, BackendParams{})
+	if !errors.Is(err, down) || !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("err = %v", err)
+	}
+	e = &Ensemble{Backends: []Backend{fixedBackend{}, fixedBackend{transcript: "hello", confidence: 0.2}}}
+	gr, err := e.Recognize(context.Background(), strings.NewReader(""), 0, English, BackendParams{})
+	if err != nil || len(gr.Results) != 1 || gr.Results[0].Alternatives[0].Transcript != "hello" {
+		t.Errorf("a backend hearing nothing = %+v, %v", gr, err)
+	}
+}
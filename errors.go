@@ -0,0 +1,159 @@
+package gorec
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrEmptyAudio        = errors.New("Empty audio")
+	ErrAudioTooLarge     = errors.New("Audio too large")
+	ErrNotOggOpus        = errors.New("Not an Ogg Opus stream")
+	ErrNotFLAC           = errors.New("Not a FLAC stream")
+	ErrNotWAV            = errors.New("Not a WAV file")
+	ErrUnsupportedFormat = errors.New("Unsupported audio format")
+	ErrUnknownLanguage   = errors.New("Unknown language")
+	ErrNoLanguages       = errors.New("No languages to query")
+	ErrQuotaExceeded     = errors.New("Quota exceeded")
+	ErrUnauthorized      = errors.New("Key not authorized for the Speech API")
+	ErrNoSpeech          = errors.New("No speech recognized")
+	ErrTimeout           = errors.New("Timed out")
+	ErrNetwork           = errors.New("Google unreachable")
+	ErrRedirect          = errors.New("Unexpected redirect")
+	ErrAmbiguous         = errors.New("No hypothesis clearly best")
+	ErrCircuitOpen       = errors.New("Backend circuit open")
+	ErrFormatMismatch    = errors.New("Audio format mismatch")
+
+	// ErrAllLanguagesFailed is matched by the *SummaryError of a call in
+	// which no language succeeded, as opposed to one WithStrict failed
+	// because some did not.
+	ErrAllLanguagesFailed = errors.New("All languages failed")
+)
+
+// Aliases of the errors above, for callers that know them by these names.
+var (
+	ErrNoSpeechDetected = ErrNoSpeech
+	ErrUnsupportedAudio = ErrUnsupportedFormat
+)
+
+// APIError is returned when Google answers with a non-2xx status. When the
+// status and body identify the cause it wraps ErrQuotaExceeded or
+// ErrUnauthorized, so callers can use errors.Is to tell them apart.
+type APIError struct {
+	StatusCode int
+	Body       string
+	kind       error
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("Google responded %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.kind != nil {
+		msg += ": " + e.kind.Error()
+	}
+	return msg
+}
+
+func (e *APIError) Unwrap() error { return e.kind }
+
+var (
+	quotaReasons        = []string{"quota", "ratelimitexceeded", "dailylimitexceeded", "resource_exhausted"}
+	unauthorizedReasons = []string{"keyinvalid", "api key not valid", "accessnotconfigured", "permission_denied", "has not been used", "is disabled"}
+)
+
+func newAPIError(status int, body []byte) *APIError {
+	e := &APIError{StatusCode: status, Body: string(body)}
+	lower := strings.ToLower(e.Body)
+	switch {
+	case status == http.StatusTooManyRequests || containsAny(lower, quotaReasons):
+		e.kind = ErrQuotaExceeded
+	case status == http.StatusUnauthorized || containsAny(lower, unauthorizedReasons):
+		e.kind = ErrUnauthorized
+	}
+	return e
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// SummaryError is returned when no language yielded a usable hypothesis. It
+// tells how every queried language fared: Status holds nil for those that
+// succeeded and the error of the others, ErrTimeout if they never answered.
+type SummaryError struct {
+	QueriedCount int
+	SuccessCount int
+	Status       map[Language]error
+}
+
+func newSummaryError(languages []Language, hs []Hypothesis) *SummaryError {
+	e := &SummaryError{QueriedCount: len(languages), Status: make(map[Language]error, len(languages))}
+	for _, lang := range languages {
+		e.Status[lang] = ErrTimeout
+	}
+	for _, h := range hs {
+		e.Status[h.Language] = h.Err
+		if h.Err == nil {
+			e.SuccessCount++
+		}
+	}
+	return e
+}
+
+// Error reads like "No response: queried 6, 0 succeeded, 4 timed out, 2 got
+// 403 Forbidden".
+func (e *SummaryError) Error() string {
+	counts := make(map[string]int)
+	for _, err := range e.Status {
+		if err != nil {
+			counts[describe(err)]++
+		}
+	}
+	outcomes := make([]string, 0, len(counts))
+	for outcome, n := range counts {
+		outcomes = append(outcomes, fmt.Sprintf("%d %s", n, outcome))
+	}
+	sort.Strings(outcomes)
+	msg := fmt.Sprintf("No response: queried %d, %d succeeded", e.QueriedCount, e.SuccessCount)
+	if len(outcomes) > 0 {
+		msg += ", " + strings.Join(outcomes, ", ")
+	}
+	return msg
+}
+
+// Is reports whether target is ErrAllLanguagesFailed and no language
+// succeeded.
+func (e *SummaryError) Is(target error) bool {
+	return target == ErrAllLanguagesFailed && e.SuccessCount == 0
+}
+
+// Unwrap exposes the per-language errors to errors.Is and errors.As.
+func (e *SummaryError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Status {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func describe(err error) string {
+	var apiErr *APIError
+	switch {
+	case errors.Is(err, ErrTimeout), isTimeout(err):
+		return "timed out"
+	case errors.Is(err, ErrNoSpeech):
+		return "heard no speech"
+	case errors.As(err, &apiErr):
+		return fmt.Sprintf("got %d %s", apiErr.StatusCode, http.StatusText(apiErr.StatusCode))
+	}
+	return "failed with " + err.Error()
+}
@@ -0,0 +1,52 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type netTimeout struct{}
+
+func (netTimeout) Error() string   { return "i/o timeout" }
+func (netTimeout) Timeout() bool   { return true }
+func (netTimeout) Temporary() bool { return true }
+
+func TestDescribe(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		want string
+	}{
+		{ErrTimeout, "timed out"},
+		{context.DeadlineExceeded, "timed out"},
+		{fmt.Errorf("Post: %w", context.DeadlineExceeded), "timed out"},
+		{netTimeout{}, "timed out"},
+		{ErrNoSpeech, "heard no speech"},
+		{&APIError{StatusCode: 503}, "got 503 Service Unavailable"},
+		{errors.New("boom"), "failed with boom"},
+	} {
+		if got := describe(c.err); got != c.want {
+			t.Errorf("describe(%v) = %q, want %q", c.err, got, c.want)
+		}
+	}
+}
+
+func TestAllLanguagesFailed(t *testing.T) {
+	failed := newSummaryError([]Language{English, French}, []Hypothesis{
+		{Language: English, Err: &APIError{StatusCode: 429, kind: ErrQuotaExceeded}},
+	})
+	if !errors.Is(failed, ErrAllLanguagesFailed) || !errors.Is(failed, ErrQuotaExceeded) {
+		t.Errorf("%v does not match both ErrAllLanguagesFailed and ErrQuotaExceeded", failed)
+	}
+	strict := newSummaryError([]Language{English, French}, []Hypothesis{
+		{Language: English},
+		{Language: French, Err: ErrNoSpeech},
+	})
+	if errors.Is(strict, ErrAllLanguagesFailed) || !errors.Is(strict, ErrNoSpeechDetected) {
+		t.Errorf("%v matches ErrAllLanguagesFailed, or not ErrNoSpeechDetected", strict)
+	}
+	if !errors.Is(fmt.Errorf("%w: 8-bit", ErrUnsupportedFormat), ErrUnsupportedAudio) {
+		t.Error("ErrUnsupportedAudio is not ErrUnsupportedFormat")
+	}
+}
@@ -0,0 +1,109 @@
+// Package export turns timed transcripts, such as the Words of a
+// gorec.ListenLong hypothesis, into SRT or WebVTT subtitles, and saves
+// batches of results as JSON, JSONL or CSV.
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/carlescere/gorec"
+)
+
+// Options shapes the cues words are grouped into.
+type Options struct {
+	// MaxLineLength is the most characters on a line of a cue, 42 if zero.
+	// A longer word gets a line of its own.
+	MaxLineLength int
+
+	// MaxLines is the most lines in a cue, 2 if zero.
+	MaxLines int
+
+	// MaxCueDuration is the longest a cue stays on screen, 5 seconds if
+	// zero.
+	MaxCueDuration time.Duration
+}
+
+// Cue is a subtitle shown from Start to End.
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Lines []string
+}
+
+// Cues groups words into cues in order, starting a new cue whenever the
+// next word would break a limit of opts.
+func Cues(words []gorec.Word, opts Options) []Cue {
+	opts = opts.withDefaults()
+	var cues []Cue
+	var cur *Cue
+	for _, w := range words {
+		if cur != nil && !cur.fits(w, opts) {
+			cues = append(cues, *cur)
+			cur = nil
+		}
+		if cur == nil {
+			cur = &Cue{Start: w.Start, Lines: []string{w.Word}}
+		} else if last := &cur.Lines[len(cur.Lines)-1]; len(*last)+1+len(w.Word) <= opts.MaxLineLength {
+			*last += " " + w.Word
+		} else {
+			cur.Lines = append(cur.Lines, w.Word)
+		}
+		cur.End = w.End
+	}
+	if cur != nil {
+		cues = append(cues, *cur)
+	}
+	return cues
+}
+
+// fits reports whether w can be added to c within opts.
+func (c *Cue) fits(w gorec.Word, opts Options) bool {
+	if w.End-c.Start > opts.MaxCueDuration {
+		return false
+	}
+	last := c.Lines[len(c.Lines)-1]
+	return len(last)+1+len(w.Word) <= opts.MaxLineLength || len(c.Lines) < opts.MaxLines
+}
+
+func (opts Options) withDefaults() Options {
+	if opts.MaxLineLength <= 0 {
+		opts.MaxLineLength = 42
+	}
+	if opts.MaxLines <= 0 {
+		opts.MaxLines = 2
+	}
+	if opts.MaxCueDuration <= 0 {
+		opts.MaxCueDuration = 5 * time.Second
+	}
+	return opts
+}
+
+// WriteSRT writes cues to w as a SubRip file.
+func WriteSRT(w io.Writer, cues []Cue) error {
+	bw := bufio.NewWriter(w)
+	for i, c := range cues {
+		fmt.Fprintf(bw, "%d\n%s --> %s\n%s\n\n", i+1, timestamp(c.Start, ','), timestamp(c.End, ','), strings.Join(c.Lines, "\n"))
+	}
+	return bw.Flush()
+}
+
+// WriteVTT writes cues to w as a WebVTT file.
+func WriteVTT(w io.Writer, cues []Cue) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("WEBVTT\n\n")
+	for _, c := range cues {
+		fmt.Fprintf(bw, "%s --> %s\n%s\n\n", timestamp(c.Start, '.'), timestamp(c.End, '.'), strings.Join(c.Lines, "\n"))
+	}
+	return bw.Flush()
+}
+
+// timestamp formats d as hours, minutes, seconds and milliseconds, the
+// latter after sep.
+func timestamp(d time.Duration, sep byte) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
@@ -0,0 +1,55 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/carlescere/gorec"
+)
+
+func words(spec ...string) []gorec.Word {
+	var ws []gorec.Word
+	for i, w := range spec {
+		start := time.Duration(i) * 500 * time.Millisecond
+		ws = append(ws, gorec.Word{Word: w, Start: start, End: start + 400*time.Millisecond})
+	}
+	return ws
+}
+
+func TestCues(t *testing.T) {
+	cues := Cues(words("one", "two", "three", "four", "five", "six"), Options{MaxLineLength: 9, MaxLines: 2})
+	if len(cues) != 2 {
+		t.Fatalf("Cues = %+v", cues)
+	}
+	if got := cues[0].Lines; len(got) != 2 || got[0] != "one two" || got[1] != "three" {
+		t.Errorf("first cue lines = %q", got)
+	}
+	if cues[0].Start != 0 || cues[0].End != 1400*time.Millisecond || cues[1].Start != 1500*time.Millisecond {
+		t.Errorf("cues timed %v-%v, %v", cues[0].Start, cues[0].End, cues[1].Start)
+	}
+	if cues := Cues(words("a", "b", "c", "d"), Options{MaxCueDuration: time.Second}); len(cues) != 2 {
+		t.Errorf("a one-second limit split 1.9 seconds of words into %d cues, want 2", len(cues))
+	}
+}
+
+func TestWriteSRT(t *testing.T) {
+	var buf bytes.Buffer
+	cues := []Cue{{Start: 1500 * time.Millisecond, End: 3723004 * time.Millisecond, Lines: []string{"hello", "world"}}}
+	if err := WriteSRT(&buf, cues); err != nil {
+		t.Fatal(err)
+	}
+	if want := "1\n00:00:01,500 --> 01:02:03,004\nhello\nworld\n\n"; buf.String() != want {
+		t.Errorf("WriteSRT wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteVTT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteVTT(&buf, []Cue{{Start: 0, End: time.Second, Lines: []string{"hi"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "WEBVTT\n\n00:00:00.000 --> 00:00:01.000\nhi\n\n"; buf.String() != want {
+		t.Errorf("WriteVTT wrote %q, want %q", buf.String(), want)
+	}
+}
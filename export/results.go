@@ -0,0 +1,92 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/carlescere/gorec"
+)
+
+// Format is a file format SaveResults writes.
+type Format int
+
+const (
+	// JSON is a single array holding every result.
+	JSON Format = iota
+	// JSONL is a JSON object per line, one per result.
+	JSONL
+	// CSV has a header row then a row per result.
+	CSV
+)
+
+// resultColumns are the header of CSV, and the keys of JSON and JSONL but
+// for error, left out of results that did not fail.
+var resultColumns = []string{"file", "language", "transcript", "confidence", "duration", "error"}
+
+// record is a result as SaveResults writes it: the language by its code
+// and the duration in seconds.
+type record struct {
+	File       string  `json:"file"`
+	Language   string  `json:"language"`
+	Transcript string  `json:"transcript"`
+	Confidence float64 `json:"confidence"`
+	Duration   float64 `json:"duration"`
+	Error      string  `json:"error,omitempty"`
+}
+
+func newRecord(h gorec.Hypothesis) record {
+	r := record{File: h.Source, Duration: h.Duration.Seconds()}
+	if h.Err != nil {
+		r.Error = h.Err.Error()
+		return r
+	}
+	r.Language = h.Language.StringCode()
+	r.Transcript = h.Alternative.Transcript
+	r.Confidence = h.Alternative.Confidence
+	return r
+}
+
+// SaveResults writes results to w in format, each with the file it was
+// recognized from, its language code, transcript, confidence, the
+// duration of its audio in seconds and, for those that failed, its error,
+// such as the hypotheses of gorec.RecognizeBatch.
+func SaveResults(w io.Writer, format Format, results ...gorec.Hypothesis) error {
+	switch format {
+	case JSON:
+		records := make([]record, len(results))
+		for i, h := range results {
+			records[i] = newRecord(h)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case JSONL:
+		enc := json.NewEncoder(w)
+		for _, h := range results {
+			if err := enc.Encode(newRecord(h)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSV:
+		cw := csv.NewWriter(w)
+		cw.Write(resultColumns)
+		for _, h := range results {
+			r := newRecord(h)
+			cw.Write([]string{
+				r.File,
+				r.Language,
+				r.Transcript,
+				strconv.FormatFloat(r.Confidence, 'f', -1, 64),
+				strconv.FormatFloat(r.Duration, 'f', -1, 64),
+				r.Error,
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("Unknown format %d", format)
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carlescere/gorec"
+)
+
+func results() []gorec.Hypothesis {
+	return []gorec.Hypothesis{
+		{Source: "a.wav", Language: gorec.French, Alternative: gorec.Alternative{Transcript: "bonjour, \"toi\"", Confidence: 0.9}, Duration: 1500 * time.Millisecond},
+		{Source: "b.wav", Err: errors.New("No speech detected")},
+	}
+}
+
+func TestSaveResultsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := SaveResults(&buf, CSV, results()...); err != nil {
+		t.Fatal(err)
+	}
+	want := "file,language,transcript,confidence,duration,error\n" +
+		"a.wav,fr-fr,\"bonjour, \"\"toi\"\"\",0.9,1.5,\n" +
+		"b.wav,,,0,0,No speech detected\n"
+	if buf.String() != want {
+		t.Errorf("CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestSaveResultsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := SaveResults(&buf, JSON, results()...); err != nil {
+		t.Fatal(err)
+	}
+	var records []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0]["language"] != "fr-fr" || records[0]["duration"] != 1.5 || records[1]["error"] != "No speech detected" {
+		t.Errorf("JSON = %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := SaveResults(&buf, JSONL, results()...); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"transcript":"bonjour, \"toi\""`) {
+		t.Errorf("JSONL = %s", buf.String())
+	}
+	if err := SaveResults(&buf, Format(9)); err == nil {
+		t.Error("saved in an unknown format")
+	}
+}
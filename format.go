@@ -0,0 +1,267 @@
+package gorec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Encoding is how the audio given to a call is encoded.
+type Encoding int
+
+const (
+	// L16 is 16-bit little-endian linear PCM, raw or in a WAV file.
+	L16 Encoding = iota
+	FLAC
+	OggOpus
+
+	// The encodings DetectFormat recognizes but calls cannot send.
+	MP3
+	OggVorbis
+	AMR
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case L16:
+		return "linear PCM"
+	case FLAC:
+		return "FLAC"
+	case OggOpus:
+		return "Ogg Opus"
+	case MP3:
+		return "MP3"
+	case OggVorbis:
+		return "Ogg Vorbis"
+	case AMR:
+		return "AMR"
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// supported reports whether audio encoded with e can be sent.
+func (e Encoding) supported() bool {
+	return e == L16 || e == FLAC || e == OggOpus
+}
+
+// AudioFormat describes the audio given to a call, such as
+// AudioFormat{SampleRate: 8000} for telephony audio. SampleRate defaults to
+// 16 kHz, or 48 kHz for Ogg Opus, and Channels to mono.
+type AudioFormat struct {
+	Encoding   Encoding
+	SampleRate int
+	Channels   int
+}
+
+func (f AudioFormat) rate() int {
+	switch {
+	case f.SampleRate > 0:
+		return f.SampleRate
+	case f.Encoding == OggOpus:
+		return opusDecodeRate
+	}
+	return defaultSampleRate
+}
+
+func (f AudioFormat) channels() int {
+	return max(f.Channels, 1)
+}
+
+// ContentType returns the Content-Type audio in f is sent with. Linear PCM
+// of several channels is downmixed first, so it is sent as mono.
+func (f AudioFormat) ContentType() string {
+	switch f.Encoding {
+	case FLAC:
+		return FLACContentType(f.rate())
+	case OggOpus:
+		return OggOpusContentType(f.rate())
+	}
+	return withRate(ContentType, f.rate())
+}
+
+func (f AudioFormat) String() string {
+	return fmt.Sprintf("%d Hz %d-channel %s", f.rate(), f.channels(), f.Encoding)
+}
+
+// WithAudioFormat declares the format of the audio, sending it with the
+// Content-Type f builds rather than ContentType and downmixing linear PCM
+// of several channels. The format the header of a WAV, FLAC or Ogg Opus
+// file gives must agree with f, or the call fails wrapping
+// ErrFormatMismatch instead of Google hearing noise; raw PCM is taken on
+// trust. Passed to a single call, it applies to that call only.
+func WithAudioFormat(f AudioFormat) Option {
+	return func(c *config) {
+		c.format = &f
+		c.contentType = f.ContentType()
+		c.inputRate = 0
+		if f.Encoding == L16 {
+			c.inputChannels = f.channels()
+		}
+	}
+}
+
+// DetectFormat identifies the audio starting with audio by its header,
+// reading the sample rate and channels from it: WAV, FLAC and Ogg Opus,
+// which calls can send, or MP3, Ogg Vorbis and AMR, for which it fails
+// wrapping ErrUnsupportedFormat along with the format. Anything else is
+// taken as raw PCM, which has no header to say more, and reported as L16
+// with SampleRate and Channels zero. A WAV file of samples other than
+// 16-bit PCM, or whose header is broken, fails with the reason.
+func DetectFormat(audio []byte) (AudioFormat, error) {
+	f, ok := sniff(audio)
+	switch {
+	case isWAV(audio):
+		w, _, _, err := wavHeader(audio, true)
+		if err != nil {
+			return AudioFormat{}, err
+		}
+		return AudioFormat{Encoding: L16, SampleRate: w.SampleRate, Channels: w.Channels}, nil
+	case bytes.HasPrefix(audio, []byte("OggS")) && !ok:
+		return AudioFormat{}, fmt.Errorf("%w: Ogg stream of an unknown codec", ErrUnsupportedFormat)
+	case !ok:
+		return AudioFormat{Encoding: L16}, nil
+	case !f.Encoding.supported():
+		return f, fmt.Errorf("%w: %s", ErrUnsupportedFormat, f.Encoding)
+	}
+	return f, nil
+}
+
+// sniff recognizes the compressed formats DetectFormat knows by their
+// magic bytes.
+func sniff(audio []byte) (AudioFormat, bool) {
+	if rate, err := FLACSampleRate(audio); err == nil {
+		return AudioFormat{Encoding: FLAC, SampleRate: rate, Channels: int(audio[20]>>1&7) + 1}, true
+	}
+	if packet, ok := oggPacket(audio); ok {
+		if rate, channels, ok := opusHead(packet); ok {
+			return AudioFormat{Encoding: OggOpus, SampleRate: rate, Channels: channels}, true
+		}
+		if len(packet) >= 16 && string(packet[:7]) == "\x01vorbis" {
+			return AudioFormat{Encoding: OggVorbis, SampleRate: int(binary.LittleEndian.Uint32(packet[12:16])), Channels: int(packet[11])}, true
+		}
+		return AudioFormat{}, false
+	}
+	// Multichannel AMR follows its magic with 4 bytes ending in the
+	// channel count.
+	switch {
+	case bytes.HasPrefix(audio, []byte("#!AMR\n")):
+		return AudioFormat{Encoding: AMR, SampleRate: 8000, Channels: 1}, true
+	case bytes.HasPrefix(audio, []byte("#!AMR-WB\n")):
+		return AudioFormat{Encoding: AMR, SampleRate: 16000, Channels: 1}, true
+	case len(audio) >= 16 && bytes.HasPrefix(audio, []byte("#!AMR_MC1.0\n")):
+		return AudioFormat{Encoding: AMR, SampleRate: 8000, Channels: int(audio[15] & 0xf)}, true
+	case len(audio) >= 19 && bytes.HasPrefix(audio, []byte("#!AMR-WB_MC1.0\n")):
+		return AudioFormat{Encoding: AMR, SampleRate: 16000, Channels: int(audio[18] & 0xf)}, true
+	}
+	return sniffMP3(audio)
+}
+
+// oggPacket returns the first packet of an Ogg stream, or as much of it as
+// its first page holds.
+func oggPacket(ogg []byte) ([]byte, bool) {
+	// Ogg page header: "OggS", 22 bytes of fields, then the segment count
+	// and the segment table.
+	if len(ogg) < 27 || string(ogg[:4]) != "OggS" || 27+int(ogg[26]) > len(ogg) {
+		return nil, false
+	}
+	return ogg[27+int(ogg[26]):], true
+}
+
+// The bitrates in kbit/s of MPEG-1 and MPEG-2 Layer III frames by index,
+// and their sample rates by version and index.
+var (
+	mp3Bitrates = [2][15]int{
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	}
+	mp3Rates = map[byte][3]int{
+		3: {44100, 48000, 32000},
+		2: {22050, 24000, 16000},
+		0: {11025, 12000, 8000},
+	}
+)
+
+// sniffMP3 recognizes MP3 audio by an ID3v2 tag or, since raw PCM can
+// start with what looks like a frame header, by two frames in a row.
+func sniffMP3(audio []byte) (AudioFormat, bool) {
+	if len(audio) >= 10 && string(audio[:3]) == "ID3" {
+		// The tag size is a 28-bit integer of 7-bit bytes, not counting
+		// the header, nor the footer its flags may announce.
+		size := 10 + (int(audio[6]&0x7f)<<21 | int(audio[7]&0x7f)<<14 | int(audio[8]&0x7f)<<7 | int(audio[9]&0x7f))
+		if audio[5]&0x10 != 0 {
+			size += 10
+		}
+		f := AudioFormat{Encoding: MP3}
+		if size < len(audio) {
+			if frame, _, ok := mp3Frame(audio[size:]); ok {
+				f = frame
+			}
+		}
+		return f, true
+	}
+	f, n, ok := mp3Frame(audio)
+	if !ok {
+		return AudioFormat{}, false
+	}
+	if _, _, ok := mp3Frame(audio[min(n, len(audio)):]); !ok {
+		return AudioFormat{}, false
+	}
+	return f, true
+}
+
+// mp3Frame parses the header of the MPEG Layer III frame starting audio,
+// returning its format and the length of the frame. The header starts with
+// 11 set bits of sync and has 01 in its layer bits for Layer III.
+func mp3Frame(audio []byte) (AudioFormat, int, bool) {
+	if len(audio) < 4 || audio[0] != 0xff || audio[1]&0xe0 != 0xe0 || audio[1]>>1&3 != 1 {
+		return AudioFormat{}, 0, false
+	}
+	version, bitrate, rate := audio[1]>>3&3, int(audio[2]>>4), int(audio[2]>>2&3)
+	rates, ok := mp3Rates[version]
+	if !ok || bitrate == 0 || bitrate == 15 || rate == 3 {
+		return AudioFormat{}, 0, false
+	}
+	f := AudioFormat{Encoding: MP3, SampleRate: rates[rate], Channels: 2}
+	if audio[3]>>6 == 3 {
+		f.Channels = 1
+	}
+	// MPEG-1 frames hold 1152 samples, the others 576: an eighth of that
+	// many bits per sample at the bitrate.
+	perSample, table := 144, 0
+	if version != 3 {
+		perSample, table = 72, 1
+	}
+	n := perSample*mp3Bitrates[table][bitrate]*1000/f.SampleRate + int(audio[2]>>1&1)
+	return f, n, true
+}
+
+// checkFormat fails if the audio starting with head contradicts the format
+// the Client was given, if any.
+func (c *Client) checkFormat(head []byte) error {
+	f := c.cfg.format
+	if f == nil {
+		return nil
+	}
+	if !f.Encoding.supported() {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, f.Encoding)
+	}
+	got, err := DetectFormat(head)
+	switch {
+	case errors.Is(err, ErrUnsupportedFormat) && !isWAV(head):
+		return fmt.Errorf("%w: declared %s: %w", ErrFormatMismatch, f, err)
+	case err != nil:
+		return err
+	case got.Encoding == L16 && got.SampleRate == 0:
+		// Raw PCM.
+		if f.Encoding != L16 {
+			return fmt.Errorf("%w: declared %s, audio is not %s", ErrFormatMismatch, f, f.Encoding)
+		}
+		return nil
+	}
+	// The channels of compressed audio only matter if f declares them.
+	if got.Encoding != f.Encoding || got.rate() != f.rate() || (f.Encoding == L16 || f.Channels > 0) && got.channels() != f.channels() {
+		return fmt.Errorf("%w: declared %s, audio is %s", ErrFormatMismatch, f, got)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package gorec
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAudioFormatContentType(t *testing.T) {
+	for f, want := range map[AudioFormat]string{
+		{}:                                     ContentType,
+		{SampleRate: 8000, Channels: 2}:        "audio/l16; rate=8000;",
+		{Encoding: FLAC, SampleRate: 44100}:    "audio/x-flac; rate=44100;",
+		{Encoding: OggOpus}:                    "audio/ogg; codecs=opus; rate=48000;",
+		{Encoding: OggOpus, SampleRate: 16000}: "audio/ogg; codecs=opus; rate=16000;",
+	} {
+		if got := f.ContentType(); got != want {
+			t.Errorf("%v.ContentType() = %q, want %q", f, got, want)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	// STREAMINFO with the 20-bit rate 44100 after 10 bytes of sizes, then
+	// the channels less one, here 1.
+	flac := append([]byte("fLaC\x00\x00\x00\x22"), make([]byte, 10)...)
+	flac = append(flac, 0x0a, 0xc4, 0x42)
+	vorbis := make([]byte, 27, 64)
+	copy(vorbis, "OggS")
+	vorbis[26] = 1
+	vorbis = append(vorbis, 30)
+	vorbis = append(vorbis, "\x01vorbis\x00\x00\x00\x00\x02"...)
+	vorbis = binary.LittleEndian.AppendUint32(vorbis, 44100)
+	vorbis = append(vorbis, make([]byte, 14)...)
+	speex := append(oggOpus(0)[:28], "Speex   "...)
+	speex = append(speex, make([]byte, 8)...)
+	// Two 128 kbit/s 44.1 kHz joint stereo MPEG-1 Layer III frames.
+	frame := append([]byte{0xff, 0xfb, 0x90, 0x44}, make([]byte, 413)...)
+	mp3 := append(append([]byte(nil), frame...), frame...)
+	id3 := append([]byte("ID3\x04\x00\x00\x00\x00\x00\x02\x00\x00"), mp3...)
+	tests := []struct {
+		audio       []byte
+		want        AudioFormat
+		unsupported bool
+	}{
+		{wav(1, 2, 8000, 16, []byte{0, 0, 0, 0}), AudioFormat{Encoding: L16, SampleRate: 8000, Channels: 2}, false},
+		{flac, AudioFormat{Encoding: FLAC, SampleRate: 44100, Channels: 2}, false},
+		{oggOpus(16000), AudioFormat{Encoding: OggOpus, SampleRate: 16000, Channels: 1}, false},
+		{[]byte{1, 2, 3, 4}, AudioFormat{Encoding: L16}, false},
+		// Silence with a little noise looks like an MP3 frame header.
+		{[]byte{0xff, 0xff, 0xff, 0xfb, 0x90, 0x44, 0, 0}, AudioFormat{Encoding: L16}, false},
+		{frame, AudioFormat{Encoding: L16}, false},
+		{mp3, AudioFormat{Encoding: MP3, SampleRate: 44100, Channels: 2}, true},
+		{id3, AudioFormat{Encoding: MP3, SampleRate: 44100, Channels: 2}, true},
+		{vorbis, AudioFormat{Encoding: OggVorbis, SampleRate: 44100, Channels: 2}, true},
+		{[]byte("#!AMR-WB\n\x00"), AudioFormat{Encoding: AMR, SampleRate: 16000, Channels: 1}, true},
+		{speex, AudioFormat{}, true},
+	}
+	for i, tt := range tests {
+		got, err := DetectFormat(tt.audio)
+		if got != tt.want || errors.Is(err, ErrUnsupportedFormat) != tt.unsupported || err != nil && !tt.unsupported {
+			t.Errorf("%d: DetectFormat = %v, %v, want %v", i, got, err, tt.want)
+		}
+	}
+	if _, err := DetectFormat(wav(1, 1, 8000, 16, nil)[:20]); !errors.Is(err, ErrNotWAV) {
+		t.Errorf("truncated WAV: err = %v, want ErrNotWAV", err)
+	}
+}
+
+func TestListenFileDetectsFormat(t *testing.T) {
+	b := &rateBackend{}
+	c := NewClient("k", WithBackend(b), WithLanguages(English))
+	if _, err := c.ListenFile(oggOpus(16000)); err != nil || b.contentType != "audio/ogg; codecs=opus; rate=16000;" {
+		t.Errorf("Ogg Opus sent as %q, %v", b.contentType, err)
+	}
+	frame := append([]byte{0xff, 0xfb, 0x90, 0x44}, make([]byte, 413)...)
+	mp3 := append(append([]byte(nil), frame...), frame...)
+	if _, err := c.ListenFile(mp3); !errors.Is(err, ErrUnsupportedFormat) || !strings.Contains(err.Error(), "MP3") {
+		t.Errorf("MP3: err = %v, want ErrUnsupportedFormat naming it", err)
+	}
+	// A backend taking MP3 is sent it as the caller says.
+	if _, err := c.ListenFile(mp3, WithContentType("audio/mpeg")); err != nil || b.contentType != "audio/mpeg" {
+		t.Errorf("MP3 with its Content-Type sent as %q, %v", b.contentType, err)
+	}
+}
+
+func TestWithAudioFormat(t *testing.T) {
+	b := &rateBackend{}
+	c := NewClient("k", WithBackend(b), WithLanguages(English))
+	telephony := WithAudioFormat(AudioFormat{SampleRate: 8000})
+	if _, err := c.ListenFile([]byte{1, 2, 3, 4}, telephony); err != nil || b.contentType != "audio/l16; rate=8000;" {
+		t.Errorf("raw PCM sent as %q, %v", b.contentType, err)
+	}
+	if _, err := c.ListenFile(wav(1, 1, 8000, 16, []byte{1, 2}), telephony); err != nil || b.contentType != "audio/l16; rate=8000;" {
+		t.Errorf("matching WAV sent as %q, %v", b.contentType, err)
+	}
+	if _, err := c.ListenFile(wav(1, 1, 44100, 16, []byte{1, 2}), telephony); !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("44.1 kHz WAV declared as 8 kHz: err = %v", err)
+	}
+	if _, err := c.ListenFile([]byte{1, 2}, WithAudioFormat(AudioFormat{Encoding: FLAC})); !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("raw PCM declared as FLAC: err = %v", err)
+	}
+
+	// A call's format does not outlive it.
+	if _, err := c.ListenFile([]byte{1, 2}); err != nil || b.contentType != ContentType {
+		t.Errorf("later call sent as %q, %v", b.contentType, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "call.wav")
+	if err := os.WriteFile(path, wav(1, 1, 16000, 16, []byte{1, 2}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListenPath(path, telephony); !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("ListenPath of a 16 kHz WAV declared as 8 kHz: err = %v", err)
+	}
+}
+
+func TestWithAudioFormatDownmixes(t *testing.T) {
+	stereo := interleave([]byte{1, 2, 3, 4}, []byte{5, 6, 7, 8})
+	h, err := NewClient("k", WithBackend(&echoBackend{}), WithLanguages(English)).ListenFile(stereo, WithAudioFormat(AudioFormat{Channels: 2}))
+	if err != nil || h.Alternative.Transcript != string(Downmix(stereo, 2)) {
+		t.Errorf("stereo PCM = %v, %v", h, err)
+	}
+}
@@ -1,15 +1,19 @@
 package gorec
 
 import (
-	"bufio"
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log/slog"
+	"net"
 	"net/http"
-	"os"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -26,16 +30,16 @@ const (
 	Italian
 )
 
-var langs = [][]string{
+var langs = append([][]string{
 	[]string{"en-gb", "English"},
 	[]string{"es-es", "Spanish"},
 	[]string{"fr-fr", "French"},
 	[]string{"el", "Greek"},
 	[]string{"de-de", "German"},
 	[]string{"it-it", "Italian"},
-}
+}, locales...)
 
-var SupportedLanguages = []Language{
+var supportedLanguages = []Language{
 	English,
 	Spanish,
 	French,
@@ -46,17 +50,232 @@ var SupportedLanguages = []Language{
 
 type Language int
 
-func (l Language) StringCode() string           { return langs[l][0] }
-func (l Language) String() string               { return langs[l][1] }
-func (l Language) MarshalJSON() ([]byte, error) { return []byte(l.String()), nil }
+// SupportedLanguages returns the languages ListenFile queries, in the order
+// they are queried. The slice is a copy and may be freely modified.
+func SupportedLanguages() []Language {
+	return append([]Language(nil), supportedLanguages...)
+}
+
+// langsMu guards langs against RegisterLanguage.
+var langsMu sync.RWMutex
+
+func (l Language) StringCode() string           { return l.entry()[0] }
+func (l Language) String() string               { return l.entry()[1] }
+func (l Language) MarshalJSON() ([]byte, error) { return json.Marshal(l.String()) }
+
+// Code returns l's BCP-47 language tag, such as "fr-FR" or "cmn-Hans-CN".
+// StringCode is the lowercase form Google's v2 endpoint takes.
+func (l Language) Code() string { return bcp47(l.StringCode()) }
+
+// UnmarshalJSON reads a language from a JSON string holding its name, as
+// MarshalJSON writes it, or its code, as ParseLanguage reads them.
+func (l *Language) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("Language must be a string: %w", err)
+	}
+	lang, err := ParseLanguage(s)
+	if err != nil {
+		return err
+	}
+	*l = lang
+	return nil
+}
+
+func (l Language) entry() []string {
+	langsMu.RLock()
+	defer langsMu.RUnlock()
+	return langs[l]
+}
+
+// RegisterLanguage adds a language Google recognizes but the package
+// doesn't know, such as "pt-br", returning the existing Language if code is
+// known already. Registered languages are not queried by default; pass them
+// to WithLanguages.
+func RegisterLanguage(code, name string) Language {
+	code = strings.ToLower(strings.Replace(code, "_", "-", -1))
+	langsMu.Lock()
+	defer langsMu.Unlock()
+	for i, l := range langs {
+		if l[0] == code {
+			return Language(i)
+		}
+	}
+	langs = append(langs, []string{code, name})
+	return Language(len(langs) - 1)
+}
+
+// LanguageFromCode finds the language for a code such as "fr-FR" or "en_US".
+// Codes are compared case-insensitively, first against the languages queried
+// by default, so "en-US" resolves to English, then against every other
+// locale. Within each, a code matching none exactly is matched by its base
+// language alone. Use LocaleFromCode for the exact locale.
+func LanguageFromCode(code string) (Language, error) {
+	code = strings.ToLower(strings.Replace(code, "_", "-", -1))
+	langsMu.RLock()
+	defer langsMu.RUnlock()
+	if l, ok := matchCode(langs[:len(supportedLanguages)], code, 0); ok {
+		return l, nil
+	}
+	if l, ok := matchCode(langs[len(supportedLanguages):], code, len(supportedLanguages)); ok {
+		return l, nil
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnknownLanguage, code)
+}
+
+// LocaleFromCode finds the language or locale whose code is exactly code,
+// compared case-insensitively, so "en-US" resolves to English (United
+// States) rather than English.
+func LocaleFromCode(code string) (Language, error) {
+	code = strings.ToLower(strings.Replace(code, "_", "-", -1))
+	langsMu.RLock()
+	defer langsMu.RUnlock()
+	for i, l := range langs {
+		if l[0] == code {
+			return Language(i), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnknownLanguage, code)
+}
+
+// ParseLanguage finds the language s names, compared case-insensitively:
+// the locale whose code is exactly s, as LocaleFromCode finds it, then the
+// language named s, such as "French" or "English (United States)", then
+// the language LanguageFromCode finds for s, so "fr-FR" and "fr" both
+// resolve to French.
+func ParseLanguage(s string) (Language, error) {
+	s = strings.TrimSpace(s)
+	if l, err := LocaleFromCode(s); err == nil {
+		return l, nil
+	}
+	langsMu.RLock()
+	for i, l := range langs {
+		if strings.EqualFold(l[1], s) {
+			langsMu.RUnlock()
+			return Language(i), nil
+		}
+	}
+	langsMu.RUnlock()
+	return LanguageFromCode(s)
+}
+
+// matchCode matches code against entries, exactly and then by base
+// language, returning the Language of entries[i] as Language(offset+i).
+func matchCode(entries [][]string, code string, offset int) (Language, bool) {
+	for i, l := range entries {
+		if l[0] == code {
+			return Language(offset + i), true
+		}
+	}
+	base := baseCode(code)
+	for i, l := range entries {
+		if baseCode(l[0]) == base {
+			return Language(offset + i), true
+		}
+	}
+	return 0, false
+}
+
+func baseCode(code string) string {
+	if i := strings.Index(code, "-"); i >= 0 {
+		return code[:i]
+	}
+	return code
+}
 
 type Alternative struct {
 	Transcript string  `json:"transcript"`
 	Confidence float64 `json:"confidence"`
+
+	// Words has the timing of each word, for backends that report it.
+	Words []Word `json:"words,omitempty"`
 }
 type Result struct {
 	Alternatives []Alternative `json:"alternative"`
 	Final        bool          `json:"final"`
+
+	// Channel is the audio channel the result is for, for backends that
+	// recognize channels separately; zero otherwise.
+	Channel int `json:"channel,omitempty"`
+
+	// Intents and Entities are what the speech was understood to mean, for
+	// backends that understand it, such as WitBackend.
+	Intents  []Intent `json:"intents,omitempty"`
+	Entities []Entity `json:"entities,omitempty"`
+
+	// Disagreements are the words the backends of an Ensemble heard
+	// differently.
+	Disagreements []Disagreement `json:"disagreements,omitempty"`
+}
+
+// Word is a recognized word and when it was spoken, from the start of the
+// audio.
+type Word struct {
+	Word  string        `json:"word"`
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+
+	// Estimated is set when the backend reported no timing and it was
+	// spread over the chunk the word was recognized in instead, by length.
+	Estimated bool `json:"estimated,omitempty"`
+
+	// Speaker numbers who spoke the word from 1, for backends that tell
+	// speakers apart and for ListenChannels; it is 0 when unknown.
+	Speaker int `json:"speaker,omitempty"`
+
+	// Confidence is how sure the backend is of the word, for those that
+	// say; it is 0 otherwise.
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+// shiftWords returns words moved d later.
+func shiftWords(words []Word, d time.Duration) []Word {
+	shifted := make([]Word, len(words))
+	for i, w := range words {
+		w.Start += d
+		w.End += d
+		shifted[i] = w
+	}
+	return shifted
+}
+
+// estimateWords times the words of transcript, spoken between start and end,
+// by sharing that time out in proportion to their length.
+func estimateWords(transcript string, start, end time.Duration) []Word {
+	fields := strings.Fields(transcript)
+	var total int
+	for _, f := range fields {
+		total += len([]rune(f))
+	}
+	words := make([]Word, len(fields))
+	var done int
+	for i, f := range fields {
+		from := start + (end-start)*time.Duration(done)/time.Duration(total)
+		done += len([]rune(f))
+		words[i] = Word{Word: f, Start: from, End: start + (end-start)*time.Duration(done)/time.Duration(total), Estimated: true}
+	}
+	return words
+}
+
+func (r Result) MaxConfidence() float64 {
+	var max float64
+	for _, a := range r.Alternatives {
+		if a.Confidence > max {
+			max = a.Confidence
+		}
+	}
+	return max
+}
+
+func (r Result) MeanConfidence() float64 {
+	if len(r.Alternatives) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, a := range r.Alternatives {
+		sum += a.Confidence
+	}
+	return sum / float64(len(r.Alternatives))
 }
 
 type GoogleResponse struct {
@@ -64,10 +283,90 @@ type GoogleResponse struct {
 	ResultIndex int      `json:"result_index"`
 }
 
+// MaxConfidence and MeanConfidence aggregate over the alternatives of every result.
+func (gr *GoogleResponse) MaxConfidence() float64 {
+	var max float64
+	for _, r := range gr.Results {
+		if c := r.MaxConfidence(); c > max {
+			max = c
+		}
+	}
+	return max
+}
+
+func (gr *GoogleResponse) MeanConfidence() float64 {
+	var sum float64
+	var n int
+	for _, r := range gr.Results {
+		for _, a := range r.Alternatives {
+			sum += a.Confidence
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
 type Hypothesis struct {
-	Alternative Alternative `json:"text"`
-	Language    Language    `json:"language"`
-	Err         error       `json:-`
+	Alternative Alternative   `json:"text"`
+	Language    Language      `json:"language"`
+	Err         error         `json:"-"`
+	Raw         []byte        `json:"-"`
+	Latency     time.Duration `json:"latency,omitempty"`
+
+	// Partial is set on the best hypothesis when it was chosen while some
+	// languages had timed out or not answered yet, other than because it
+	// reached the confidence threshold. A complete fan-out might have picked
+	// another one.
+	Partial bool `json:"partial,omitempty"`
+
+	// Alternatives is every alternative of the result the chosen one belongs
+	// to, the N-best list as Google ranked it. See WithMaxAlternatives.
+	Alternatives []Alternative `json:"alternatives,omitempty"`
+
+	// Interim is set when the hypothesis is not final: it comes from a
+	// result Google marked as interim, or from the audio a Stream segment
+	// had so far with WithInterim. A later one will replace it.
+	Interim bool `json:"interim,omitempty"`
+
+	// Intents and Entities are those of the result the chosen alternative
+	// belongs to, for backends that understand speech, such as WitBackend.
+	Intents  []Intent `json:"intents,omitempty"`
+	Entities []Entity `json:"entities,omitempty"`
+
+	// Disagreements are those of the result the chosen alternative belongs
+	// to, for an Ensemble.
+	Disagreements []Disagreement `json:"disagreements,omitempty"`
+
+	// Source is the file the audio was read from, for ListenPath,
+	// RecognizeBatch and ListenDir.
+	Source string `json:"source,omitempty"`
+
+	// Duration is how long the audio recognized lasts, when it was sent as
+	// linear PCM; it is 0 otherwise.
+	Duration time.Duration `json:"duration,omitempty"`
+
+	// Translated is the transcript translated with WithTranslation.
+	Translated string `json:"translated,omitempty"`
+
+	response      *GoogleResponse
+	result        int
+	alternative   int
+	rawTranscript string
+}
+
+func (h Hypothesis) MarshalJSON() ([]byte, error) {
+	type hypothesis Hypothesis
+	v := struct {
+		hypothesis
+		Error string `json:"error,omitempty"`
+	}{hypothesis: hypothesis(h)}
+	if h.Err != nil {
+		v.Error = h.Err.Error()
+	}
+	return json.Marshal(v)
 }
 
 func (h Hypothesis) String() string {
@@ -78,99 +377,525 @@ func (h Hypothesis) String() string {
 	return string(bytes)
 }
 
-func ListenFile(audio []byte, key string) (*Hypothesis, error) {
-	var best *Hypothesis
-	c := make(chan Hypothesis)
+func ListenFile(audio []byte, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenFile(audio)
+}
+
+// Transcribe is ListenFile for callers that only need the winning transcript
+// and the language it was recognized in.
+func Transcribe(audio []byte, key string, opts ...Option) (text string, lang Language, confidence float64, err error) {
+	return NewClient(key, opts...).Transcribe(audio)
+}
+
+func ListenBase64(b64, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenBase64(b64)
+}
+
+func ListenReaderAt(r io.ReaderAt, size int64, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenReaderAt(r, size)
+}
+
+func ListenPath(path, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenPath(path)
+}
+
+func ListenFileAll(audio []byte, key string, opts ...Option) (map[Language]Hypothesis, error) {
+	return NewClient(key, opts...).ListenFileAll(audio)
+}
+
+func ListenFileFunc(audio []byte, key string, fn func(Hypothesis), opts ...Option) error {
+	return NewClient(key, opts...).ListenFileFunc(audio, fn)
+}
+
+// ListenFileContext is ListenFile abandoning the requests still in flight,
+// without leaving any goroutine behind, once ctx is done.
+func ListenFileContext(ctx context.Context, audio []byte, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenFileContext(ctx, audio)
+}
+
+func TranscribeContext(ctx context.Context, audio []byte, key string, opts ...Option) (text string, lang Language, confidence float64, err error) {
+	return NewClient(key, opts...).TranscribeContext(ctx, audio)
+}
+
+func ListenBase64Context(ctx context.Context, b64, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenBase64Context(ctx, b64)
+}
+
+func ListenReaderAtContext(ctx context.Context, r io.ReaderAt, size int64, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenReaderAtContext(ctx, r, size)
+}
+
+func ListenPathContext(ctx context.Context, path, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenPathContext(ctx, path)
+}
+
+func ListenFileAllContext(ctx context.Context, audio []byte, key string, opts ...Option) (map[Language]Hypothesis, error) {
+	return NewClient(key, opts...).ListenFileAllContext(ctx, audio)
+}
+
+func ListenFileFuncContext(ctx context.Context, audio []byte, key string, fn func(Hypothesis), opts ...Option) error {
+	return NewClient(key, opts...).ListenFileFuncContext(ctx, audio, fn)
+}
+
+func RecognizeContext(ctx context.Context, audio []byte, key string, lang Language, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).RecognizeContext(ctx, audio, lang)
+}
+
+// Recognize transcribes audio in a single, already known language. It runs
+// synchronously on the calling goroutine and sends exactly one request.
+func Recognize(audio []byte, key string, lang Language, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).Recognize(audio, lang)
+}
+
+func (c *Client) listenBest(ctx context.Context, r io.ReaderAt, size int64) (*Hypothesis, error) {
+	hs, err := c.listenAll(ctx, r, size, nil)
 	if err != nil {
 		return nil, err
 	}
-	for _, lang := range SupportedLanguages {
-		go checkLanguage(audio, key, lang, c)
+	if c.cfg.strict && anyFailed(hs) {
+		return nil, newSummaryError(c.languages(), hs)
+	}
+	best, err := c.selectBest(hs)
+	if err != nil {
+		return nil, err
+	}
+	if best == nil {
+		if c.heardNothing(hs) {
+			return nil, ErrNoSpeech
+		}
+		return nil, newSummaryError(c.languages(), hs)
+	}
+	if _, ok := c.multiLanguageBackend(); !ok {
+		best.Partial = !c.reachedThreshold(*best) && c.incomplete(hs)
+	}
+	if isL16(c.cfg.contentType) {
+		best.Duration = time.Duration(size/2) * time.Second / time.Duration(c.sampleRate())
+	}
+	c.logChoice(ctx, hs, best)
+	if err := c.translate(ctx, best); err != nil {
+		return nil, err
+	}
+	return best, nil
+}
+
+// incomplete reports whether any queried language is missing from hs or
+// timed out.
+func (c *Client) incomplete(hs []Hypothesis) bool {
+	if len(hs) < len(c.languages()) {
+		return true
+	}
+	for _, h := range hs {
+		if isTimeout(h.Err) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// anyFailed reports whether any of hs failed for a reason other than
+// hearing no speech.
+func anyFailed(hs []Hypothesis) bool {
+	for _, h := range hs {
+		if h.Err != nil && !errors.Is(h.Err, ErrNoSpeech) {
+			return true
+		}
+	}
+	return false
+}
+
+// heardNothing reports whether every language queried answered without
+// speech, as opposed to some failing or not answering in time.
+func (c *Client) heardNothing(hs []Hypothesis) bool {
+	queried := len(c.languages())
+	if _, ok := c.multiLanguageBackend(); ok {
+		queried = 1
+	}
+	return len(hs) > 0 && len(hs) >= queried && allNoSpeech(hs)
+}
+
+func allNoSpeech(hs []Hypothesis) bool {
+	for _, h := range hs {
+		if !errors.Is(h.Err, ErrNoSpeech) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectable reports whether h may be chosen as the best hypothesis.
+func (c *Client) selectable(h Hypothesis) bool {
+	floor, ok := c.cfg.minConfidence[h.Language]
+	if !ok {
+		floor = c.cfg.minConfidenceAll
+	}
+	return h.Err == nil && h.Alternative.Confidence >= floor
+}
+
+// listenAll queries every language and returns the hypotheses received before
+// the selection became final. If fn is not nil it is also called with each of
+// them as they arrive.
+func (c *Client) listenAll(parent context.Context, r io.ReaderAt, size int64, fn func(Hypothesis)) (hs []Hypothesis, err error) {
+	if err := parent.Err(); err != nil {
+		return nil, err
+	}
+	if err := c.checkSize(size); err != nil {
+		return nil, err
 	}
-	for remaining := len(SupportedLanguages); remaining > 0; remaining-- {
+	parent, span := c.startSpan(parent, "gorec.listen", "bytes", size)
+	defer func() {
+		span.SetAttributes("hypotheses", len(hs))
+		span.End(err)
+	}()
+	// Cancelling on return aborts every request still in flight once the
+	// selection is final, whether by threshold, timeout or completion.
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	deadline := c.deadline()
+	languages := c.languages()
+	if len(languages) == 0 {
+		return nil, ErrNoLanguages
+	}
+	if mb, ok := c.multiLanguageBackend(); ok {
+		hs = c.listenMulti(ctx, mb, r, size, languages, fn, deadline)
+	} else if c.cfg.sequential {
+		hs = c.listenSequential(ctx, r, size, languages, fn, deadline)
+	} else {
+		ch := make(chan Hypothesis, len(languages))
+		c.fanOut(ctx, r, size, languages, ch)
+		hs = c.gather(ctx, ch, len(languages), fn, deadline)
+	}
+	if c.cfg.coverage != nil {
+		c.cfg.coverage.fill(languages, hs)
+	}
+	if err := parent.Err(); err != nil {
+		return hs, err
+	}
+	return hs, nil
+}
+
+// gather receives up to n hypotheses from ch, until the threshold is reached,
+// ctx is done or deadline passes.
+func (c *Client) gather(ctx context.Context, ch chan Hypothesis, n int, fn func(Hypothesis), deadline <-chan time.Time) []Hypothesis {
+	var hs []Hypothesis
+	for remaining := n; remaining > 0; remaining-- {
 		select {
-		case h := <-c:
-			if h.Err == nil {
-				if best == nil || best.Alternative.Confidence < h.Alternative.Confidence {
-					best = &h
-				}
+		case h := <-ch:
+			hs = append(hs, h)
+			if fn != nil {
+				fn(h)
+			}
+			if c.reachedThreshold(h) {
+				return hs
 			}
-		case <-time.After(30 * time.Second):
+		case <-ctx.Done():
+			return hs
+		case <-deadline:
+			return hs
+		}
+	}
+	return hs
+}
+
+// listenSequential queries the languages one after the other, in order,
+// until one reaches the confidence threshold.
+func (c *Client) listenSequential(ctx context.Context, r io.ReaderAt, size int64, languages []Language, fn func(Hypothesis), deadline <-chan time.Time) []Hypothesis {
+	var hs []Hypothesis
+	for _, lang := range languages {
+		langCtx, cancel := context.WithCancel(ctx)
+		ch := make(chan Hypothesis, 1)
+		go c.checkLanguage(langCtx, r, size, lang, ch)
+		got := c.gather(langCtx, ch, 1, fn, deadline)
+		cancel()
+		hs = append(hs, got...)
+		// Nothing came when the deadline passed or ctx was done.
+		if len(got) == 0 || ctx.Err() != nil || c.reachedThreshold(got[0]) {
 			break
 		}
 	}
-	if best == nil {
-		return nil, errors.New("No response")
+	return hs
+}
+
+// deadline returns a channel delivering once the Client's timeout has passed,
+// or one that never does if it has none.
+func (c *Client) deadline() <-chan time.Time {
+	if c.cfg.timeout <= 0 {
+		return nil
 	}
-	return best, nil
+	return c.cfg.clock.After(c.cfg.timeout)
 }
 
-func checkLanguage(audio []byte, key string, lang Language, c chan Hypothesis) {
-	h := Hypothesis{Language: lang}
-	str, err := sendFile(audio, key, lang)
-	if err != nil {
-		h.Err = err
-		c <- h
-		return
+func (c *Client) reachedThreshold(h Hypothesis) bool {
+	return h.Err == nil && c.cfg.threshold > 0 && h.Alternative.Confidence >= c.cfg.threshold
+}
+
+// fanOut queries languages concurrently, at most WithMaxConcurrency at a
+// time, sending each hypothesis to ch, which must have room for all of
+// them: nothing may be receiving any more once the selection is final.
+// Once ctx is done the requests in flight are aborted and the languages
+// still queued are dropped, so every worker returns promptly.
+func (c *Client) fanOut(ctx context.Context, r io.ReaderAt, size int64, languages []Language, ch chan Hypothesis) {
+	workers := c.cfg.maxConcurrency
+	if workers <= 0 || workers > len(languages) {
+		workers = len(languages)
 	}
-	gr := &GoogleResponse{}
-	err = json.Unmarshal([]byte(str), gr)
-	if err != nil {
-		h.Err = err
-		c <- h
-		return
+	queue := make(chan Language, len(languages))
+	for _, lang := range languages {
+		queue <- lang
+	}
+	close(queue)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for lang := range queue {
+				if ctx.Err() != nil {
+					return
+				}
+				c.checkLanguage(ctx, r, size, lang, ch)
+			}
+		}()
+	}
+}
+
+func (c *Client) checkLanguage(ctx context.Context, r io.ReaderAt, size int64, lang Language, ch chan Hypothesis) {
+	h, err := c.recognize(ctx, r, size, lang)
+	h.Err = err
+	ch <- *h
+}
+
+// recognize always returns a hypothesis for lang, carrying whatever was
+// gathered before err occurred.
+func (c *Client) recognize(ctx context.Context, r io.ReaderAt, size int64, lang Language) (*Hypothesis, error) {
+	ctx, span := c.startSpan(ctx, "gorec.language", "language", lang.StringCode())
+	h, err := c.recognizeLanguage(ctx, r, size, lang)
+	if err == nil {
+		span.SetAttributes("confidence", h.Alternative.Confidence)
+	}
+	span.End(err)
+	return h, err
+}
+
+func (c *Client) recognizeLanguage(ctx context.Context, r io.ReaderAt, size int64, lang Language) (*Hypothesis, error) {
+	h := &Hypothesis{Language: lang}
+	if err := ctx.Err(); err != nil {
+		return h, err
+	}
+	if c.cfg.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.requestTimeout)
+		defer cancel()
+	}
+	if err := c.checkSize(size); err != nil {
+		return h, err
+	}
+	gr, raw, err := c.fetch(ctx, r, size, lang, h)
+	if c.cfg.rawCapture {
+		h.Raw = raw
+	}
+	if err == nil {
+		err = c.interpret(ctx, h, gr, raw)
+	}
+	if c.cfg.metrics != nil {
+		c.cfg.metrics.observe(h, err)
+	}
+	return h, err
+}
+
+// interpret fills h from the response gr to its request, raw being the
+// response body if known.
+func (c *Client) interpret(ctx context.Context, h *Hypothesis, gr *GoogleResponse, raw []byte) error {
+	lang := h.Language
+	h.response = gr
+	alt := c.selectAlternative(gr, lang)
+	if alt == nil {
+		// Usually nobody spoke, but a change in Google's schema looks the same.
+		c.log(ctx, slog.LevelWarn, "response has no results", "language", lang.StringCode(), "body", string(raw))
+		if c.cfg.anomalyHook != nil {
+			c.cfg.anomalyHook(lang, raw)
+		}
+		return ErrNoSpeech
 	}
-	alt := checkAlternatives(gr)
 	h.Alternative = *alt
-	c <- h
+	h.result, h.alternative = indexOf(gr, alt)
+	if h.result >= 0 {
+		h.Alternatives = gr.Results[h.result].Alternatives
+		h.Interim = !gr.Results[h.result].Final
+		h.Intents, h.Entities = gr.Results[h.result].Intents, gr.Results[h.result].Entities
+		h.Disagreements = gr.Results[h.result].Disagreements
+	}
+	h.rawTranscript = h.Alternative.Transcript
+	if c.cfg.profanity == ProfanityDrop {
+		dropMasked(&h.Alternative)
+	}
+	if c.cfg.normalizer != nil {
+		h.Alternative.Transcript = c.cfg.normalizer(h.Alternative.Transcript)
+	}
+	if strings.TrimSpace(h.Alternative.Transcript) == "" {
+		return ErrNoSpeech
+	}
+	return nil
 }
 
-func sendFile(audio []byte, key string, lang Language) (string, error) {
-	r, err := http.NewRequest("POST", fmt.Sprintf(GoogleEndpoint, lang.StringCode(), key), bytes.NewBuffer(audio))
-	if err != nil {
-		return "", err
+func (c *Client) checkSize(size int64) error {
+	if size <= 0 {
+		return ErrEmptyAudio
 	}
-	r.Header.Set("Content-Type", ContentType)
+	if c.cfg.maxUpload > 0 && size > c.cfg.maxUpload {
+		return fmt.Errorf("%w: %d bytes, the maximum is %d", ErrAudioTooLarge, size, c.cfg.maxUpload)
+	}
+	return nil
+}
 
-	client := &http.Client{}
+func (c *Client) sendFile(ctx context.Context, audio io.Reader, size int64, lang Language) ([]byte, time.Duration, error) {
+	key, err := c.apiKey()
+	if err != nil {
+		return nil, 0, err
+	}
+	r, err := c.newRequest(ctx, audio, size, lang, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(c.cfg.phraseHints) > 0 {
+		c.log(ctx, slog.LevelDebug, "phrase hints are not supported by the v2 endpoint, ignoring them", "language", lang.StringCode())
+	}
+	client := c.cfg.httpClient
+	if client == nil {
+		client = &http.Client{Transport: sharedTransport, CheckRedirect: c.cfg.redirectPolicy}
+		if c.cfg.transport != nil {
+			client.Transport = c.cfg.transport
+		}
+	}
+	start := c.cfg.clock.Now()
 	resp, err := client.Do(r)
+	latency := c.cfg.clock.Now().Sub(start)
 	if err != nil {
-		return "", err
+		return nil, latency, err
 	}
 	defer resp.Body.Close()
 
-	bodyByte, _ := ioutil.ReadAll(resp.Body)
-	body := strings.TrimPrefix(string(bodyByte), "{\"result\":[]}\n")
-	return string(body), nil
+	bodyByte, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return bodyByte, latency, fmt.Errorf("Reading response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		apiErr := newAPIError(resp.StatusCode, bodyByte)
+		if c.cfg.keyPool != nil && errors.Is(apiErr, ErrQuotaExceeded) {
+			c.cfg.keyPool.rest(key, c.cfg.clock.Now())
+		}
+		return bodyByte, latency, apiErr
+	}
+	return bodyByte, latency, nil
 }
 
-func ReadAudioFile(path string) ([]byte, error) {
-	file, err := os.Open(path)
+func (c *Client) newRequest(ctx context.Context, audio io.Reader, size int64, lang Language, key string) (*http.Request, error) {
+	r, err := http.NewRequestWithContext(ctx, "POST", c.requestURL(lang, key), audio)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+	r.ContentLength = size
+	r.Header.Set("Content-Type", c.cfg.contentType)
+	return r, nil
+}
 
-	info, err := file.Stat()
-	if err != nil {
-		return nil, err
+func (c *Client) requestURL(lang Language, key string) string {
+	endpoint := GoogleEndpoint
+	if c.cfg.endpoint != "" {
+		endpoint = c.cfg.endpoint
+	}
+	if e, ok := c.cfg.endpoints[lang]; ok {
+		endpoint = e
+	}
+	u := fmt.Sprintf(endpoint, lang.StringCode(), url.QueryEscape(key))
+	if c.cfg.clientParam != "" {
+		u += "&client=" + url.QueryEscape(c.cfg.clientParam)
+	}
+	if c.cfg.maxAlternatives > 0 {
+		u += "&maxAlternatives=" + strconv.Itoa(c.cfg.maxAlternatives)
 	}
+	if p := c.cfg.profanity.pFilter(); p != "" {
+		u += "&pFilter=" + p
+	}
+	return u
+}
 
-	var size int64 = info.Size()
-	bytes := make([]byte, size)
+// AlternativeSelector picks the alternative of a response to use, or returns
+// nil if none is acceptable.
+type AlternativeSelector func(gr *GoogleResponse) *Alternative
 
-	buffer := bufio.NewReader(file)
-	_, err = buffer.Read(bytes)
-	if err != nil {
-		return nil, err
+// SelectAlternative is the default AlternativeSelector: it picks the most
+// confident alternative in gr. With preferFinal, interim results are only
+// considered when no final result has any alternative.
+func SelectAlternative(gr *GoogleResponse, preferFinal bool) *Alternative {
+	ri, ai, ok := checkAlternatives(gr, preferFinal, confidence)
+	if !ok {
+		return nil
 	}
+	return &gr.Results[ri].Alternatives[ai]
+}
+
+// Scorer rates how good an alternative recognized in lang is, higher being
+// better. The default is its confidence.
+type Scorer func(alt Alternative, lang Language) float64
+
+func confidence(alt Alternative) float64 { return alt.Confidence }
 
-	return bytes, nil
+// score rates alt, recognized in lang, with the Client's Scorer.
+func (c *Client) score(alt Alternative, lang Language) float64 {
+	if c.cfg.scorer == nil {
+		return alt.Confidence
+	}
+	return c.cfg.scorer(alt, lang)
 }
 
-func checkAlternatives(gr *GoogleResponse) *Alternative {
-	if len(gr.Results) == 0 || len(gr.Results[0].Alternatives) == 0 {
+func (c *Client) selectAlternative(gr *GoogleResponse, lang Language) *Alternative {
+	if c.cfg.selectAlternative != nil {
+		return c.cfg.selectAlternative(gr)
+	}
+	if c.cfg.scorer == nil {
+		return SelectAlternative(gr, c.cfg.preferFinal)
+	}
+	score := func(alt Alternative) float64 { return c.cfg.scorer(alt, lang) }
+	ri, ai, ok := checkAlternatives(gr, c.cfg.preferFinal, score)
+	if !ok {
 		return nil
 	}
-	return &gr.Results[0].Alternatives[0]
+	return &gr.Results[ri].Alternatives[ai]
+}
+
+// indexOf locates alt within gr, returning -1, -1 if a selector made it up.
+func indexOf(gr *GoogleResponse, alt *Alternative) (ri, ai int) {
+	for i := range gr.Results {
+		for j := range gr.Results[i].Alternatives {
+			if &gr.Results[i].Alternatives[j] == alt {
+				return i, j
+			}
+		}
+	}
+	return -1, -1
+}
+
+func checkAlternatives(gr *GoogleResponse, preferFinal bool, score func(Alternative) float64) (ri, ai int, ok bool) {
+	if preferFinal {
+		if ri, ai, ok = bestAlternative(gr, true, score); ok {
+			return ri, ai, ok
+		}
+	}
+	return bestAlternative(gr, false, score)
+}
+
+func bestAlternative(gr *GoogleResponse, finalOnly bool, score func(Alternative) float64) (ri, ai int, ok bool) {
+	for i, r := range gr.Results {
+		if finalOnly && !r.Final {
+			continue
+		}
+		for j, a := range r.Alternatives {
+			if !ok || score(a) > score(gr.Results[ri].Alternatives[ai]) {
+				ri, ai, ok = i, j, true
+			}
+		}
+	}
+	return ri, ai, ok
 }
@@ -0,0 +1,121 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// rtFunc is an http.RoundTripper calling itself.
+type rtFunc func(*http.Request) (*http.Response, error)
+
+func (f rtFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// newLanguageServer answers every request with the response given for its
+// lang query parameter, and with no results for other languages.
+func newLanguageServer(responses map[string]string) (*httptest.Server, map[Language]string) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Query().Get("lang")]
+		if !ok {
+			body = `{"result":[]}`
+		}
+		fmt.Fprint(w, body)
+	}))
+	eps := map[Language]string{}
+	for _, l := range SupportedLanguages() {
+		eps[l] = srv.URL + "/?lang=%s&key=%s"
+	}
+	return srv, eps
+}
+
+func TestListenFile(t *testing.T) {
+	srv, eps := newLanguageServer(map[string]string{
+		"fr-fr": `{"result":[{"alternative":[{"transcript":"bonjour","confidence":0.9}],"final":true}],"result_index":0}`,
+		"es-es": `{"result":[{"alternative":[{"transcript":"bueno","confidence":0.4}],"final":true}],"result_index":0}`,
+	})
+	defer srv.Close()
+	h, err := ListenFile([]byte{1, 2}, "k", WithLanguageEndpoint(eps))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Language != French || h.Alternative.Transcript != "bonjour" || h.Alternative.Confidence != 0.9 {
+		t.Errorf("ListenFile = %v", h)
+	}
+}
+
+func TestHypothesisString(t *testing.T) {
+	h := Hypothesis{Alternative: Alternative{Transcript: "hi", Confidence: 0.5}, Language: French}
+	if got, want := h.String(), `{"text":{"transcript":"hi","confidence":0.5},"language":"French"}`; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+	h.Err = errors.New("boom")
+	if got, want := h.String(), `{"text":{"transcript":"hi","confidence":0.5},"language":"French","error":"boom"}`; got != want {
+		t.Errorf("String() with Err = %s, want %s", got, want)
+	}
+}
+
+func TestAllLanguagesNoSpeech(t *testing.T) {
+	for name, body := range map[string]string{
+		"no results":        `{"result":[]}` + "\n",
+		"empty transcripts": `{"result":[{"alternative":[{"transcript":"","confidence":0.4}],"final":true}],"result_index":0}`,
+	} {
+		responses := map[string]string{}
+		for _, l := range SupportedLanguages() {
+			responses[l.StringCode()] = body
+		}
+		srv, eps := newLanguageServer(responses)
+		if _, err := ListenFile([]byte{1, 2}, "k", WithLanguageEndpoint(eps)); err != ErrNoSpeech {
+			t.Errorf("%s: ListenFile = %v, want ErrNoSpeech", name, err)
+		}
+		srv.Close()
+	}
+}
+
+// quietBackend hears nothing, failing with err if set, and stalls the
+// languages in stall until their request is cancelled.
+type quietBackend struct {
+	stall []Language
+	err   error
+}
+
+func (b quietBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	if slices.Contains(b.stall, lang) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &GoogleResponse{}, b.err
+}
+
+func TestNoSpeechNeedsEveryLanguage(t *testing.T) {
+	c := NewClient("k", WithBackend(quietBackend{stall: []Language{French}}), WithLanguages(English, French), WithTimeout(50*time.Millisecond))
+	_, err := c.ListenFile([]byte{1, 2})
+	var summary *SummaryError
+	if err == ErrNoSpeech || !errors.As(err, &summary) {
+		t.Errorf("with French timing out: err = %v, want a *SummaryError", err)
+	}
+
+	wrapped := fmt.Errorf("Backend says: %w", ErrNoSpeech)
+	c = NewClient("k", WithBackend(quietBackend{err: wrapped}), WithLanguages(English, French))
+	if _, err := c.ListenFile([]byte{1, 2}); err != ErrNoSpeech {
+		t.Errorf("with wrapped ErrNoSpeech: err = %v, want ErrNoSpeech", err)
+	}
+}
+
+func TestEstimateWords(t *testing.T) {
+	got := estimateWords("ab abcd  ab", time.Second, 2*time.Second)
+	want := []Word{
+		{Word: "ab", Start: time.Second, End: 1250 * time.Millisecond, Estimated: true},
+		{Word: "abcd", Start: 1250 * time.Millisecond, End: 1750 * time.Millisecond, Estimated: true},
+		{Word: "ab", Start: 1750 * time.Millisecond, End: 2 * time.Second, Estimated: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("estimateWords = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,191 @@
+package gorectest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// Interaction is a request to the endpoint and the response it got, as
+// fixture files hold them. The request is identified by its method, its URL
+// with the API key redacted and the SHA-256 of its body.
+type Interaction struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	BodySHA256 string      `json:"body_sha256"`
+	Status     int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// redactedParams are the query parameters left out of fixtures.
+var redactedParams = []string{"key"}
+
+// identify returns the method, redacted URL and body hash identifying r,
+// and its body, which is put back for sending.
+func identify(r *http.Request) (method, u, sum string, err error) {
+	var body []byte
+	if r.Body != nil {
+		body, err = io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return "", "", "", err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	redacted := *r.URL
+	q := redacted.Query()
+	for _, p := range redactedParams {
+		if q.Has(p) {
+			q.Set(p, "REDACTED")
+		}
+	}
+	redacted.RawQuery = q.Encode()
+	h := sha256.Sum256(body)
+	return r.Method, redacted.String(), hex.EncodeToString(h[:]), nil
+}
+
+// Recorder is an http.RoundTripper sending the requests with Transport,
+// http.DefaultTransport if nil, and recording them with their responses.
+// Use it as the Transport of the client passed to gorec.WithHTTPClient,
+// then Save the fixture for a Replayer.
+type Recorder struct {
+	Transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+func (rec *Recorder) RoundTrip(r *http.Request) (*http.Response, error) {
+	method, u, sum, err := identify(r)
+	if err != nil {
+		return nil, err
+	}
+	transport := rec.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	rec.mu.Lock()
+	rec.interactions = append(rec.interactions, Interaction{
+		Method:     method,
+		URL:        u,
+		BodySHA256: sum,
+		Status:     resp.StatusCode,
+		Header:     http.Header{"Content-Type": resp.Header.Values("Content-Type")},
+		Body:       string(body),
+	})
+	rec.mu.Unlock()
+	return resp, nil
+}
+
+// Interactions returns what was recorded so far, in the order the
+// responses came.
+func (rec *Recorder) Interactions() []Interaction {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]Interaction(nil), rec.interactions...)
+}
+
+// Save writes what was recorded to the fixture file at path.
+func (rec *Recorder) Save(path string) error {
+	b, err := json.MarshalIndent(rec.Interactions(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// Replayer is an http.RoundTripper answering requests with the recorded
+// responses to the same requests, each used once and in order, without
+// touching the network. Requests nothing was recorded for fail.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer returns a Replayer of interactions, such as a Recorder's.
+func NewReplayer(interactions []Interaction) *Replayer {
+	return &Replayer{interactions: interactions, used: make([]bool, len(interactions))}
+}
+
+// LoadReplayer returns a Replayer of the fixture file at path, as
+// Recorder.Save writes them.
+func LoadReplayer(path string) (*Replayer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(b, &interactions); err != nil {
+		return nil, fmt.Errorf("Invalid fixture %s: %w", path, err)
+	}
+	return NewReplayer(interactions), nil
+}
+
+// Client returns an http.Client replaying with rp, for
+// gorec.WithHTTPClient.
+func (rp *Replayer) Client() *http.Client {
+	return &http.Client{Transport: rp}
+}
+
+func (rp *Replayer) RoundTrip(r *http.Request) (*http.Response, error) {
+	method, u, sum, err := identify(r)
+	if err != nil {
+		return nil, err
+	}
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	for i, in := range rp.interactions {
+		if rp.used[i] || in.Method != method || in.URL != u || in.BodySHA256 != sum {
+			continue
+		}
+		rp.used[i] = true
+		header := in.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+			StatusCode:    in.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader([]byte(in.Body))),
+			ContentLength: int64(len(in.Body)),
+			Request:       r,
+		}, nil
+	}
+	return nil, fmt.Errorf("No recorded response for %s %s", method, (&url.URL{Scheme: r.URL.Scheme, Host: r.URL.Host, Path: r.URL.Path}).String())
+}
+
+// Unused returns the recorded interactions no request has replayed yet.
+func (rp *Replayer) Unused() []Interaction {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	var unused []Interaction
+	for i, in := range rp.interactions {
+		if !rp.used[i] {
+			unused = append(unused, in)
+		}
+	}
+	return unused
+}
@@ -0,0 +1,93 @@
+package gorectest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carlescere/gorec"
+)
+
+// loud is audio loud enough to count as speech.
+var loud = []byte(strings.Repeat("\x00\x40\x00\xc0", 8000))
+
+func TestMockBackend(t *testing.T) {
+	boom := errors.New("boom")
+	m := NewMockBackend(map[gorec.Language]string{gorec.French: "bonjour"})
+	m.Responses[gorec.Spanish] = []Response{{Err: boom}, {Transcript: "hola", Confidence: 0.95}}
+	c := gorec.NewClient("k", gorec.WithBackend(m), gorec.WithLanguages(gorec.French, gorec.Spanish))
+
+	h, err := c.ListenFile(loud)
+	if err != nil || h.Language != gorec.French || h.Alternative.Transcript != "bonjour" {
+		t.Fatalf("first call = %v, %v", h, err)
+	}
+	h, err = c.ListenFile(loud)
+	if err != nil || h.Language != gorec.Spanish || h.Alternative.Transcript != "hola" {
+		t.Fatalf("second call = %v, %v", h, err)
+	}
+	if _, err := c.Recognize(loud, gorec.English); !errors.Is(err, gorec.ErrNoSpeech) {
+		t.Errorf("unscripted language returned %v", err)
+	}
+	calls := m.Calls()
+	if len(calls) != 5 || len(calls[0].Audio) == 0 {
+		t.Errorf("calls %+v", calls)
+	}
+}
+
+func TestMockBackendDelay(t *testing.T) {
+	m := &MockBackend{Responses: map[gorec.Language][]Response{gorec.French: {{Transcript: "bonjour", Confidence: 0.9, Delay: time.Minute}}}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := gorec.NewClient("k", gorec.WithBackend(m)).RecognizeContext(ctx, loud, gorec.French); err == nil {
+		t.Error("delayed response outlived its context")
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("lang") != "fr-fr" {
+			fmt.Fprint(w, `{"result":[]}`)
+			return
+		}
+		fmt.Fprint(w, `{"result":[{"alternative":[{"transcript":"bonjour","confidence":0.9}],"final":true}],"result_index":0}`)
+	}))
+	endpoint := gorec.WithEndpoint(srv.URL + "/?lang=%s&key=%s")
+	langs := gorec.WithLanguages(gorec.French, gorec.Spanish)
+
+	rec := &Recorder{}
+	h, err := gorec.NewClient("secret", endpoint, langs, gorec.WithHTTPClient(&http.Client{Transport: rec})).ListenFile(loud)
+	if err != nil || h.Alternative.Transcript != "bonjour" {
+		t.Fatalf("recording = %v, %v", h, err)
+	}
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range rec.Interactions() {
+		if strings.Contains(in.URL, "secret") {
+			t.Errorf("fixture holds the key: %s", in.URL)
+		}
+	}
+	srv.Close()
+
+	rp, err := LoadReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err = gorec.NewClient("other", endpoint, langs, gorec.WithHTTPClient(rp.Client())).ListenFile(loud)
+	if err != nil || h.Language != gorec.French || h.Alternative.Transcript != "bonjour" {
+		t.Fatalf("replay = %v, %v", h, err)
+	}
+	if unused := rp.Unused(); len(unused) != 0 {
+		t.Errorf("unused interactions %+v", unused)
+	}
+	if _, err := gorec.NewClient("k", endpoint, gorec.WithHTTPClient(rp.Client())).Recognize(loud, gorec.French); err == nil || !strings.Contains(err.Error(), "No recorded response") {
+		t.Errorf("replaying used interaction returned %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+// Package gorectest helps test applications embedding gorec without API
+// keys or network access: MockBackend answers with scripted transcripts,
+// and Recorder and Replayer save and replay the exchanges with Google's
+// endpoint as fixture files.
+package gorectest
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/carlescere/gorec"
+)
+
+// Response is what MockBackend answers for a language: Err if set,
+// otherwise Transcript, heard with Confidence, or no speech if Transcript
+// is empty. Delay holds the answer back as a slow backend would.
+type Response struct {
+	Transcript string
+	Confidence float64
+	Words      []gorec.Word
+	Err        error
+	Delay      time.Duration
+}
+
+// Call is a request MockBackend received.
+type Call struct {
+	Language gorec.Language
+	Params   gorec.BackendParams
+	Audio    []byte
+}
+
+// MockBackend is a gorec.Backend answering each language with its
+// Responses in turn, the last repeated once they run out; languages without
+// any hear no speech. Pass it to gorec.WithBackend. It is safe for the
+// concurrent requests of a Client, but Responses must not change while it
+// is in use.
+type MockBackend struct {
+	Responses map[gorec.Language][]Response
+
+	mu    sync.Mutex
+	calls []Call
+	next  map[gorec.Language]int
+}
+
+// NewMockBackend returns a MockBackend answering each language in
+// transcripts with its transcript, heard with confidence 0.9.
+func NewMockBackend(transcripts map[gorec.Language]string) *MockBackend {
+	m := &MockBackend{Responses: make(map[gorec.Language][]Response)}
+	for lang, t := range transcripts {
+		m.Responses[lang] = []Response{{Transcript: t, Confidence: 0.9}}
+	}
+	return m
+}
+
+func (m *MockBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang gorec.Language, params gorec.BackendParams) (*gorec.GoogleResponse, error) {
+	data, err := io.ReadAll(audio)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Language: lang, Params: params, Audio: data})
+	var resp Response
+	if script := m.Responses[lang]; len(script) > 0 {
+		if m.next == nil {
+			m.next = make(map[gorec.Language]int)
+		}
+		i := min(m.next[lang], len(script)-1)
+		m.next[lang]++
+		resp = script[i]
+	}
+	m.mu.Unlock()
+
+	if resp.Delay > 0 {
+		t := time.NewTimer(resp.Delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	if resp.Transcript == "" {
+		return &gorec.GoogleResponse{}, nil
+	}
+	alt := gorec.Alternative{Transcript: resp.Transcript, Confidence: resp.Confidence, Words: resp.Words}
+	return &gorec.GoogleResponse{Results: []gorec.Result{{Alternatives: []gorec.Alternative{alt}, Final: true}}}, nil
+}
+
+// Calls returns the requests received so far, in the order they came.
+func (m *MockBackend) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
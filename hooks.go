@@ -0,0 +1,54 @@
+package gorec
+
+// Hooks are called as batch and chunked recognitions progress, to drive
+// progress bars and the like. Any of them may be nil. A unit is a clip of
+// ListenClips and ListenChunked, a chunk of ListenLong or a file of
+// RecognizeBatch and ListenDir; total is how many there are, -1 when not
+// known in advance. Units recognized in parallel call the hooks
+// concurrently.
+type Hooks struct {
+	// OnChunkStart is called as unit index starts being recognized.
+	OnChunkStart func(index, total int)
+
+	// OnChunkResult is called with the hypothesis of unit index, its Err
+	// set if it failed.
+	OnChunkResult func(index, total int, h Hypothesis)
+
+	// OnRetry is called as a request to lang that failed with err, nil for
+	// an empty answer, is about to be tried again. attempt is the number of
+	// the failed attempt, from 1.
+	OnRetry func(lang Language, attempt int, err error)
+
+	// OnComplete is called once every unit has been recognized, or the
+	// call has failed with err.
+	OnComplete func(err error)
+}
+
+// WithHooks has h called as the batch and chunked recognitions progress.
+func WithHooks(h Hooks) Option {
+	return func(c *config) { c.hooks = h }
+}
+
+func (c *Client) chunkStarted(index, total int) {
+	if c.cfg.hooks.OnChunkStart != nil {
+		c.cfg.hooks.OnChunkStart(index, total)
+	}
+}
+
+func (c *Client) chunkDone(index, total int, h Hypothesis) {
+	if c.cfg.hooks.OnChunkResult != nil {
+		c.cfg.hooks.OnChunkResult(index, total, h)
+	}
+}
+
+func (c *Client) retrying(lang Language, attempt int, err error) {
+	if c.cfg.hooks.OnRetry != nil {
+		c.cfg.hooks.OnRetry(lang, attempt, err)
+	}
+}
+
+func (c *Client) completed(err error) {
+	if c.cfg.hooks.OnComplete != nil {
+		c.cfg.hooks.OnComplete(err)
+	}
+}
@@ -0,0 +1,93 @@
+package gorec
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyBackend answers 503 to its first request.
+type flakyBackend struct {
+	calls int32
+}
+
+func (b *flakyBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	if atomic.AddInt32(&b.calls, 1) == 1 {
+		return nil, &APIError{StatusCode: 503}
+	}
+	return timedBackend{}.Recognize(ctx, audio, size, lang, p)
+}
+
+// hookRecorder counts the calls of its Hooks.
+type hookRecorder struct {
+	mu       sync.Mutex
+	started  map[int]int
+	results  map[int]Hypothesis
+	retries  []int
+	complete int
+	totals   map[int]bool
+}
+
+func (r *hookRecorder) hooks() Hooks {
+	r.started, r.results, r.totals = map[int]int{}, map[int]Hypothesis{}, map[int]bool{}
+	return Hooks{
+		OnChunkStart: func(index, total int) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.started[index]++
+			r.totals[total] = true
+		},
+		OnChunkResult: func(index, total int, h Hypothesis) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.results[index] = h
+		},
+		OnRetry: func(lang Language, attempt int, err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.retries = append(r.retries, attempt)
+		},
+		OnComplete: func(err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.complete++
+		},
+	}
+}
+
+func TestHooksChunked(t *testing.T) {
+	var r hookRecorder
+	b := &flakyBackend{}
+	_, err := ListenChunked(make([]byte, 3*32000), 32000, "k", WithBackend(b), WithLanguages(English),
+		WithRetry(RetryPolicy{MaxAttempts: 2}), WithHooks(r.hooks()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.started) != 3 || len(r.results) != 3 || !r.totals[3] || len(r.totals) != 1 {
+		t.Errorf("started %v, results %v, totals %v", r.started, r.results, r.totals)
+	}
+	if len(r.retries) != 1 || r.retries[0] != 1 {
+		t.Errorf("retries = %v, want [1]", r.retries)
+	}
+	if r.complete != 1 {
+		t.Errorf("OnComplete called %d times", r.complete)
+	}
+}
+
+func TestHooksLong(t *testing.T) {
+	var r hookRecorder
+	pcm := speechPCM(40*time.Second,
+		[2]time.Duration{0, 10 * time.Second},
+		[2]time.Duration{12 * time.Second, 20 * time.Second},
+		[2]time.Duration{25 * time.Second, 38 * time.Second})
+	_, err := ListenLong(pcm, "k", WithBackend(durationBackend{}), WithLanguages(English), WithMaxConcurrency(2), WithHooks(r.hooks()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.results) != 3 || r.results[2].Alternative.Transcript != "s13" || !r.totals[3] || r.complete != 1 {
+		t.Errorf("results %v, totals %v, complete %d", r.results, r.totals, r.complete)
+	}
+}
@@ -0,0 +1,273 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carlescere/gorec"
+	"github.com/carlescere/gorec/gorectest"
+)
+
+// packet builds an RTP packet of payload type pt.
+func packet(pt uint8, seq uint16, ts uint32, payload []byte) []byte {
+	b := make([]byte, 12, 12+len(payload))
+	b[0] = 0x80
+	b[1] = pt
+	binary.BigEndian.PutUint16(b[2:], seq)
+	binary.BigEndian.PutUint32(b[4:], ts)
+	binary.BigEndian.PutUint32(b[8:], 0xcafe)
+	return append(b, payload...)
+}
+
+func TestParsePacket(t *testing.T) {
+	b := []byte{
+		0xb1, 0x88, 0x00, 0x07, 0, 0, 0, 160, 0, 0, 0, 1, // padding, extension, one CSRC; marker, PT 8
+		0, 0, 0, 2, // CSRC
+		0xbe, 0xde, 0x00, 0x01, 1, 2, 3, 4, // extension of one word
+		0xaa, 0xbb, 0, 0, 3, // payload then 3 bytes of padding
+	}
+	p, err := ParsePacket(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.PayloadType != 8 || !p.Marker || p.SequenceNumber != 7 || p.Timestamp != 160 || p.SSRC != 1 || string(p.Payload) != "\xaa\xbb" {
+		t.Errorf("ParsePacket = %+v", p)
+	}
+	for _, bad := range [][]byte{b[:8], append([]byte{0x40}, b[1:]...), {0x8f, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}} {
+		if _, err := ParsePacket(bad); err == nil {
+			t.Errorf("ParsePacket(%x) succeeded", bad)
+		}
+	}
+}
+
+func TestG711(t *testing.T) {
+	for b, want := range map[byte]int16{0xff: 0, 0x7f: 0, 0x00: -32124, 0x80: 32124, 0xf0: 120} {
+		if got := ulaw(b); got != want {
+			t.Errorf("ulaw(%#x) = %d, want %d", b, got, want)
+		}
+	}
+	for b, want := range map[byte]int16{0xd5: 8, 0x55: -8, 0xaa: 32256, 0x2a: -32256} {
+		if got := alaw(b); got != want {
+			t.Errorf("alaw(%#x) = %d, want %d", b, got, want)
+		}
+	}
+}
+
+func TestParseSDP(t *testing.T) {
+	sdp := "v=0\r\no=- 0 0 IN IP4 127.0.0.1\r\ns=cam\r\na=control:*\r\n" +
+		"m=video 0 RTP/AVP 96\r\na=rtpmap:96 H264/90000\r\na=control:trackID=0\r\n" +
+		"m=audio 0 RTP/AVP 97 0\r\na=rtpmap:97 MPEG4-GENERIC/16000/1\r\na=control:trackID=1\r\n"
+	m, err := parseSDP(sdp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.codec != PCMU || m.control != "trackID=1" {
+		t.Errorf("parseSDP = %+v", m)
+	}
+	m, err = parseSDP("m=audio 0 RTP/AVP 98\na=rtpmap:98 L16/16000/2\n")
+	if err != nil || m.codec != (Codec{PayloadType: 98, Name: "L16", ClockRate: 16000, Channels: 2}) {
+		t.Errorf("parseSDP of L16 = %+v, %v", m, err)
+	}
+	if _, err := parseSDP("m=audio 0 RTP/AVP 97\na=rtpmap:97 opus/48000/2\n"); err == nil {
+		t.Error("parseSDP accepted Opus")
+	}
+}
+
+func TestControlURL(t *testing.T) {
+	for _, c := range [][3]string{
+		{"rtsp://cam/stream", "", "rtsp://cam/stream"},
+		{"rtsp://cam/stream", "trackID=1", "rtsp://cam/stream/trackID=1"},
+		{"rtsp://cam/stream/", "trackID=1", "rtsp://cam/stream/trackID=1"},
+		{"rtsp://cam/stream", "rtsp://other/track", "rtsp://other/track"},
+	} {
+		if got, err := controlURL(c[0], c[1]); err != nil || got != c[2] {
+			t.Errorf("controlURL(%q, %q) = %q, %v", c[0], c[1], got, err)
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	packets := [][]byte{
+		packet(0, 1, 0, []byte{0xff, 0xff}),
+		packet(96, 2, 2, []byte{0xff}), // another payload type
+		packet(0, 3, 2, []byte{0x80}),
+		packet(0, 3, 2, []byte{0x80}),    // duplicate
+		packet(0, 2, 2, []byte{0x80}),    // late
+		packet(0, 5, 5, []byte{0x00}),    // after a loss of 2 samples
+		packet(0, 6, 9000, []byte{0xff}), // a jump too long to fill
+	}
+	r := newReader(PCMU, func() ([]byte, error) {
+		if len(packets) == 0 {
+			return nil, io.EOF
+		}
+		p := packets[0]
+		packets = packets[1:]
+		return p, nil
+	}, nil)
+	pcm, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int16
+	for i := 0; i+1 < len(pcm); i += 2 {
+		got = append(got, int16(binary.LittleEndian.Uint16(pcm[i:])))
+	}
+	if want := []int16{0, 0, 32124, 0, 0, -32124, 0}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("read %v, want %v", got, want)
+	}
+}
+
+func TestDecodeL16(t *testing.T) {
+	c := Codec{PayloadType: 11, Name: "L16", ClockRate: 44100, Channels: 2}
+	if got := c.decode([]byte{0x00, 0x10, 0x00, 0x30, 0xff}); string(got) != "\x20\x00" {
+		t.Errorf("decode = %x", got)
+	}
+}
+
+// rtspServer serves a PCMU stream of samples µ-law bytes behind Digest
+// authentication, in packets of 160, then hangs up.
+func rtspServer(t *testing.T, samples int) (addr string, methods <-chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	seen := make(chan string, 16)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewReader(bufio.NewReader(conn))
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			header, err := tp.ReadMIMEHeader()
+			if err != nil {
+				return
+			}
+			method, uri, _ := strings.Cut(line, " ")
+			uri, _, _ = strings.Cut(uri, " ")
+			seen <- method
+			cseq := header.Get("CSeq")
+			auth := header.Get("Authorization")
+			if !strings.HasPrefix(auth, "Digest ") || !strings.Contains(auth, `username="alice"`) || !strings.Contains(auth, `uri="`+uri+`"`) {
+				fmt.Fprintf(conn, "RTSP/1.0 401 Unauthorized\r\nCSeq: %s\r\nWWW-Authenticate: Digest realm=\"cam, inc\", nonce=\"n0\"\r\n\r\n", cseq)
+				continue
+			}
+			ha1, ha2 := md5Hex("alice:cam, inc:secret"), md5Hex(method+":"+uri)
+			if !strings.Contains(auth, `response="`+md5Hex(ha1+":n0:"+ha2)+`"`) {
+				t.Errorf("%s authorized with %s", method, auth)
+			}
+			switch method {
+			case "DESCRIBE":
+				sdp := "v=0\r\nm=audio 0 RTP/AVP 0\r\na=control:track1\r\n"
+				fmt.Fprintf(conn, "RTSP/1.0 200 OK\r\nCSeq: %s\r\nContent-Base: %s/\r\nContent-Type: application/sdp\r\nContent-Length: %d\r\n\r\n%s", cseq, uri, len(sdp), sdp)
+			case "SETUP":
+				if !strings.HasSuffix(uri, "/track1") || !strings.Contains(header.Get("Transport"), "interleaved=0-1") {
+					t.Errorf("SETUP %s with %v", uri, header)
+				}
+				fmt.Fprintf(conn, "RTSP/1.0 200 OK\r\nCSeq: %s\r\nSession: 12345678;timeout=60\r\nTransport: RTP/AVP/TCP;unicast;interleaved=0-1\r\n\r\n", cseq)
+			case "PLAY":
+				if header.Get("Session") != "12345678" {
+					t.Errorf("PLAY in session %q", header.Get("Session"))
+				}
+				fmt.Fprintf(conn, "RTSP/1.0 200 OK\r\nCSeq: %s\r\n\r\n", cseq)
+				conn.Write([]byte{'$', 1, 0, 4, 0x80, 200, 0, 1}) // RTCP
+				for seq := 0; seq*160 < samples; seq++ {
+					p := packet(0, uint16(seq), uint32(seq*160), []byte(strings.Repeat("\x80", min(160, samples-seq*160))))
+					conn.Write(append([]byte{'$', 0, byte(len(p) >> 8), byte(len(p))}, p...))
+				}
+				return
+			}
+		}
+	}()
+	return l.Addr().String(), seen
+}
+
+func TestTranscribeRTSP(t *testing.T) {
+	addr, methods := rtspServer(t, 8000)
+	src, err := DialRTSP(context.Background(), "rtsp://alice:secret@"+addr+"/live")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	if src.SampleRate() != 8000 || src.Codec() != PCMU {
+		t.Errorf("stream codec %+v", src.Codec())
+	}
+	m := gorectest.NewMockBackend(map[gorec.Language]string{gorec.English: "hello camera"})
+	c := gorec.NewClient("k", gorec.WithBackend(m), gorec.WithLanguages(gorec.English))
+	var hs []gorec.Hypothesis
+	if err := Transcribe(context.Background(), c, src, func(h gorec.Hypothesis) { hs = append(hs, h) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(hs) != 1 || hs[0].Alternative.Transcript != "hello camera" {
+		t.Errorf("hypotheses %v", hs)
+	}
+	calls := m.Calls()
+	if len(calls) != 1 || len(calls[0].Audio) != 16000 || !strings.Contains(calls[0].Params.ContentType, "rate=8000") {
+		t.Errorf("backend called with %d calls, %+v", len(calls), calls[0].Params)
+	}
+	var got []string
+	for len(methods) > 0 {
+		got = append(got, <-methods)
+	}
+	if want := "DESCRIBE DESCRIBE SETUP PLAY"; strings.Join(got, " ") != want {
+		t.Errorf("requests %v, want %s", got, want)
+	}
+}
+
+func TestDialRTSPCancel(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := DialRTSP(ctx, "rtsp://"+l.Addr().String()+"/live"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DialRTSP of a silent server returned %v", err)
+	}
+}
+
+func TestListenRTP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := ListenRTP(conn, PCMA)
+	out, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	out.Write(packet(8, 1, 0, []byte{0xd5, 0x55}))
+	buf := make([]byte, 16)
+	n, err := r.Read(buf)
+	if err != nil || string(buf[:n]) != "\x08\x00\xf8\xff" {
+		t.Errorf("Read = %x, %v", buf[:n], err)
+	}
+	r.Close()
+	if _, err := r.Read(buf); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Read after Close returned %v", err)
+	}
+}
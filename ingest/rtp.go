@@ -0,0 +1,223 @@
+// Package ingest transcribes live audio streams, such as those of IP
+// cameras (RTSP) and SIP media legs (RTP over UDP), feeding their decoded
+// audio into a gorec.Stream.
+//
+// The audio may be G.711 (PCMU or PCMA) or linear PCM (L16); compressed
+// codecs such as Opus or AAC are not decoded.
+package ingest
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/carlescere/gorec"
+)
+
+// Packet is an RTP packet, as RFC 3550 lays it out.
+type Packet struct {
+	PayloadType    uint8
+	Marker         bool
+	SequenceNumber uint16
+	Timestamp      uint32
+	SSRC           uint32
+	Payload        []byte
+}
+
+// ParsePacket reads the RTP packet b, skipping its CSRC list, header
+// extension and padding. The Payload shares b.
+func ParsePacket(b []byte) (*Packet, error) {
+	if len(b) < 12 {
+		return nil, errors.New("Short RTP packet")
+	}
+	if b[0]>>6 != 2 {
+		return nil, fmt.Errorf("RTP version %d", b[0]>>6)
+	}
+	p := &Packet{
+		PayloadType:    b[1] & 0x7f,
+		Marker:         b[1]&0x80 != 0,
+		SequenceNumber: binary.BigEndian.Uint16(b[2:]),
+		Timestamp:      binary.BigEndian.Uint32(b[4:]),
+		SSRC:           binary.BigEndian.Uint32(b[8:]),
+	}
+	off := 12 + 4*int(b[0]&0x0f)
+	if b[0]&0x10 != 0 {
+		if len(b) < off+4 {
+			return nil, errors.New("Truncated RTP header extension")
+		}
+		off += 4 + 4*int(binary.BigEndian.Uint16(b[off+2:]))
+	}
+	end := len(b)
+	if b[0]&0x20 != 0 && end > 0 {
+		end -= int(b[end-1])
+	}
+	if off > end {
+		return nil, errors.New("Truncated RTP packet")
+	}
+	p.Payload = b[off:end]
+	return p, nil
+}
+
+// Codec is how the audio of a stream is encoded.
+type Codec struct {
+	PayloadType uint8
+	// Name is the encoding name of the RTP profile, PCMU, PCMA or L16.
+	Name      string
+	ClockRate int
+	Channels  int
+}
+
+// The codecs of the static payload types of RFC 3551.
+var (
+	PCMU = Codec{PayloadType: 0, Name: "PCMU", ClockRate: 8000, Channels: 1}
+	PCMA = Codec{PayloadType: 8, Name: "PCMA", ClockRate: 8000, Channels: 1}
+)
+
+var staticCodecs = map[uint8]Codec{
+	0:  PCMU,
+	8:  PCMA,
+	10: {PayloadType: 10, Name: "L16", ClockRate: 44100, Channels: 2},
+	11: {PayloadType: 11, Name: "L16", ClockRate: 44100, Channels: 1},
+}
+
+// supported reports whether c can be decoded.
+func (c Codec) supported() bool {
+	switch strings.ToUpper(c.Name) {
+	case "PCMU", "PCMA", "L16":
+		return c.ClockRate > 0
+	}
+	return false
+}
+
+func (c Codec) channels() int {
+	return max(c.Channels, 1)
+}
+
+// decode turns payload into 16-bit little-endian mono PCM.
+func (c Codec) decode(payload []byte) []byte {
+	var pcm []byte
+	switch strings.ToUpper(c.Name) {
+	case "PCMU":
+		pcm = make([]byte, 2*len(payload))
+		for i, b := range payload {
+			binary.LittleEndian.PutUint16(pcm[2*i:], uint16(ulaw(b)))
+		}
+	case "PCMA":
+		pcm = make([]byte, 2*len(payload))
+		for i, b := range payload {
+			binary.LittleEndian.PutUint16(pcm[2*i:], uint16(alaw(b)))
+		}
+	case "L16":
+		// Network byte order.
+		pcm = make([]byte, len(payload)&^1)
+		for i := 0; i+1 < len(payload); i += 2 {
+			pcm[i], pcm[i+1] = payload[i+1], payload[i]
+		}
+	}
+	return gorec.Downmix(pcm, c.channels())
+}
+
+// ulaw decodes a G.711 µ-law sample.
+func ulaw(b byte) int16 {
+	b = ^b
+	t := (int(b&0x0f)<<3 + 0x84) << (uint(b&0x70) >> 4)
+	if b&0x80 != 0 {
+		return int16(0x84 - t)
+	}
+	return int16(t - 0x84)
+}
+
+// alaw decodes a G.711 A-law sample.
+func alaw(b byte) int16 {
+	b ^= 0x55
+	t := int(b&0x0f) << 4
+	switch seg := int(b&0x70) >> 4; seg {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t = (t + 0x108) << (seg - 1)
+	}
+	if b&0x80 != 0 {
+		return int16(t)
+	}
+	return int16(-t)
+}
+
+// maxGap is the longest loss Reader fills with silence, in seconds; longer
+// jumps of the timestamp are taken as the source restarting.
+const maxGap = 1
+
+// Reader is an io.Reader of the audio of an RTP stream, decoded to 16-bit
+// little-endian mono PCM at SampleRate. Packets arriving late or twice are
+// dropped, and those lost are made up for with silence, so that the audio
+// keeps time.
+type Reader struct {
+	codec  Codec
+	next   func() ([]byte, error)
+	closer io.Closer
+
+	buf     []byte
+	started bool
+	seq     uint16
+	ts      uint32
+}
+
+func newReader(codec Codec, next func() ([]byte, error), closer io.Closer) *Reader {
+	return &Reader{codec: codec, next: next, closer: closer}
+}
+
+// SampleRate returns the sample rate of the audio Read returns.
+func (r *Reader) SampleRate() int { return r.codec.ClockRate }
+
+// Codec returns how the stream is encoded.
+func (r *Reader) Codec() Codec { return r.codec }
+
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		b, err := r.next()
+		if err != nil {
+			return 0, err
+		}
+		pkt, err := ParsePacket(b)
+		if err != nil || pkt.PayloadType != r.codec.PayloadType {
+			continue
+		}
+		r.buf = r.packetAudio(pkt)
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// packetAudio returns the audio pkt brings, after the silence making up for
+// the packets lost before it, if any.
+func (r *Reader) packetAudio(pkt *Packet) []byte {
+	var silence int
+	if r.started {
+		if int16(pkt.SequenceNumber-r.seq) <= 0 {
+			return nil
+		}
+		if gap := int32(pkt.Timestamp - r.ts); gap > 0 && gap <= int32(maxGap*r.codec.ClockRate) {
+			silence = int(gap)
+		}
+	}
+	pcm := r.codec.decode(pkt.Payload)
+	r.started, r.seq = true, pkt.SequenceNumber
+	r.ts = pkt.Timestamp + uint32(len(pcm)/2)
+	if silence == 0 {
+		return pcm
+	}
+	return append(make([]byte, 2*silence, 2*silence+len(pcm)), pcm...)
+}
+
+// Close stops the stream.
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
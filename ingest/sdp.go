@@ -0,0 +1,79 @@
+package ingest
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// audioMedia is the audio stream an SDP description offers.
+type audioMedia struct {
+	codec   Codec
+	control string
+}
+
+// parseSDP finds the first audio stream of the SDP description sdp in a
+// codec Reader decodes.
+func parseSDP(sdp string) (*audioMedia, error) {
+	type media struct {
+		kind    string
+		formats []string
+		rtpmap  map[string]Codec
+		control string
+	}
+	var all []*media
+	var m *media
+	for _, line := range strings.Split(sdp, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "m="):
+			f := strings.Fields(line[2:])
+			m = &media{rtpmap: make(map[string]Codec)}
+			if len(f) > 0 {
+				m.kind = f[0]
+			}
+			if len(f) > 3 {
+				m.formats = f[3:]
+			}
+			all = append(all, m)
+		case m == nil:
+			// Session-level attributes name no stream.
+		case strings.HasPrefix(line, "a=rtpmap:"):
+			pt, encoding, ok := strings.Cut(line[len("a=rtpmap:"):], " ")
+			if !ok {
+				continue
+			}
+			parts := strings.Split(encoding, "/")
+			c := Codec{Name: strings.ToUpper(parts[0]), Channels: 1}
+			if len(parts) > 1 {
+				c.ClockRate, _ = strconv.Atoi(parts[1])
+			}
+			if len(parts) > 2 {
+				c.Channels, _ = strconv.Atoi(parts[2])
+			}
+			m.rtpmap[pt] = c
+		case strings.HasPrefix(line, "a=control:"):
+			m.control = strings.TrimSpace(line[len("a=control:"):])
+		}
+	}
+	for _, m := range all {
+		if m.kind != "audio" {
+			continue
+		}
+		for _, format := range m.formats {
+			n, err := strconv.Atoi(format)
+			if err != nil || n < 0 || n > 127 {
+				continue
+			}
+			c, ok := m.rtpmap[format]
+			if !ok {
+				c, ok = staticCodecs[uint8(n)]
+			}
+			c.PayloadType = uint8(n)
+			if ok && c.supported() {
+				return &audioMedia{codec: c, control: m.control}, nil
+			}
+		}
+	}
+	return nil, errors.New("No audio stream in a supported codec")
+}
@@ -0,0 +1,55 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/carlescere/gorec"
+)
+
+// Transcribe feeds the audio of src into a Stream of c, declared at the
+// sample rate of src, calling fn with each hypothesis the Stream delivers,
+// in order, until src ends or fails or ctx is done, which closes src. What
+// was read last is still recognized. It returns the error reading src, if
+// any but its end or the closing of its connection.
+func Transcribe(ctx context.Context, c *gorec.Client, src *Reader, fn func(gorec.Hypothesis), opts ...gorec.Option) error {
+	stop := context.AfterFunc(ctx, func() { src.Close() })
+	defer stop()
+	s := c.Stream(ctx, append(append([]gorec.Option(nil), opts...), gorec.WithSampleRate(src.SampleRate()))...)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for h := range s.Results() {
+			fn(h)
+		}
+	}()
+	err := feed(ctx, s, src)
+	if cerr := s.Close(); err == nil {
+		err = cerr
+	}
+	<-done
+	return err
+}
+
+func feed(ctx context.Context, s *gorec.Stream, src io.Reader) error {
+	buf := make([]byte, 4096)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			if _, werr := s.Write(buf[:n]); werr != nil {
+				return werr
+			}
+		}
+		switch {
+		case err == nil:
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, io.EOF), errors.Is(err, net.ErrClosed):
+			return nil
+		default:
+			return err
+		}
+	}
+}
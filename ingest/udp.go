@@ -0,0 +1,17 @@
+package ingest
+
+import "net"
+
+// ListenRTP reads the RTP stream arriving on conn, such as the media leg
+// a SIP call negotiated, whose packets of codec's payload type carry the
+// audio. Closing the Reader closes conn.
+func ListenRTP(conn net.PacketConn, codec Codec) *Reader {
+	buf := make([]byte, 64*1024)
+	return newReader(codec, func() ([]byte, error) {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}, conn)
+}
@@ -0,0 +1,341 @@
+// Package websocket is the little of RFC 6455 gorec needs: the server side
+// of the handshake for the live transcription endpoint, the client side for
+// backends such as Vosk's server, and framing for both.
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// guid is appended to the client's key to accept a handshake.
+const guid = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Opcodes of the messages Conn reads and writes.
+const (
+	OpContinuation = 0x0
+	OpText         = 0x1
+	OpBinary       = 0x2
+	OpClose        = 0x8
+	OpPing         = 0x9
+	OpPong         = 0xa
+)
+
+// Close codes.
+const (
+	CloseNormal      = 1000
+	CloseProtocol    = 1002
+	CloseUnsupported = 1003
+	CloseTooLarge    = 1009
+	CloseInternal    = 1011
+)
+
+// CloseError ends a connection with its Code: the peer closed it, or broke
+// the protocol, as Reason says.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("WebSocket closed with %d", e.Code)
+	}
+	return e.Reason
+}
+
+// Conn is one end of a WebSocket. Writes may come from several goroutines,
+// reads from one at a time.
+type Conn struct {
+	net.Conn
+	rw     *bufio.ReadWriter
+	max    int64
+	client bool
+
+	mu sync.Mutex
+}
+
+// Upgrade completes the WebSocket handshake of r, answering it with an
+// error status instead if it is not one. Messages are bounded by max bytes.
+func Upgrade(w http.ResponseWriter, r *http.Request, max int64) (*Conn, error) {
+	var err error
+	status := http.StatusBadRequest
+	key := r.Header.Get("Sec-WebSocket-Key")
+	switch {
+	case !headerHas(r.Header, "Connection", "upgrade") || !strings.EqualFold(r.Header.Get("Upgrade"), "websocket"):
+		err = errors.New("Not a WebSocket handshake")
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		err = errors.New("Unsupported WebSocket version")
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		status = http.StatusUpgradeRequired
+	case key == "":
+		err = errors.New("Missing Sec-WebSocket-Key")
+	}
+	hj, ok := w.(http.Hijacker)
+	if err == nil && !ok {
+		err = errors.New("Connection cannot be upgraded")
+		status = http.StatusInternalServerError
+	}
+	if err != nil {
+		return nil, &HandshakeError{Status: status, Err: err}
+	}
+	c, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + accept(key) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return &Conn{Conn: c, rw: rw, max: max}, nil
+}
+
+// HandshakeError is returned by Upgrade for requests that cannot be
+// upgraded, to be answered with Status.
+type HandshakeError struct {
+	Status int
+	Err    error
+}
+
+func (e *HandshakeError) Error() string { return e.Err.Error() }
+func (e *HandshakeError) Unwrap() error { return e.Err }
+
+// Dial opens a WebSocket to the ws:// URL rawURL. Messages read are bounded
+// by max bytes.
+func Dial(ctx context.Context, rawURL string, max int64) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ws" {
+		return nil, fmt.Errorf("Unsupported WebSocket URL %s", rawURL)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host += ":80"
+	}
+	var d net.Dialer
+	c, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		c.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { c.Close() })
+	conn, err := handshake(c, u)
+	if !stop() && err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	// The connection stays bound to ctx until it is closed.
+	context.AfterFunc(ctx, func() { c.Close() })
+	return &Conn{Conn: c, rw: conn, max: max, client: true}, nil
+}
+
+func handshake(c net.Conn, u *url.URL) (*bufio.ReadWriter, error) {
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	req := &http.Request{
+		Method: "GET",
+		URL:    u,
+		Host:   u.Host,
+		Header: http.Header{
+			"Upgrade":               {"websocket"},
+			"Connection":            {"Upgrade"},
+			"Sec-WebSocket-Key":     {key},
+			"Sec-WebSocket-Version": {"13"},
+		},
+	}
+	rw := bufio.NewReadWriter(bufio.NewReader(c), bufio.NewWriter(c))
+	if err := req.Write(rw); err != nil {
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		return nil, err
+	}
+	resp, err := http.ReadResponse(rw.Reader, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, fmt.Errorf("WebSocket handshake answered %s", resp.Status)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != accept(key) {
+		return nil, errors.New("WebSocket handshake not accepted")
+	}
+	return rw, nil
+}
+
+func accept(key string) string {
+	sum := sha1.Sum([]byte(key + guid))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerHas reports whether the comma-separated header name lists token.
+func headerHas(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadMessage returns the next data message, reassembled from its frames,
+// answering pings on the way. A close from the peer is a *CloseError.
+func (c *Conn) ReadMessage() (op byte, msg []byte, err error) {
+	for {
+		fin, frameOp, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch frameOp {
+		case OpPing:
+			if err := c.WriteMessage(OpPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case OpPong:
+			continue
+		case OpClose:
+			code := CloseNormal
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+				payload = payload[2:]
+			}
+			return 0, nil, &CloseError{Code: code, Reason: string(payload)}
+		case OpText, OpBinary:
+			if op != 0 {
+				return 0, nil, &CloseError{CloseProtocol, "Expected a continuation frame"}
+			}
+			op = frameOp
+		case OpContinuation:
+			if op == 0 {
+				return 0, nil, &CloseError{CloseProtocol, "Unexpected continuation frame"}
+			}
+		default:
+			return 0, nil, &CloseError{CloseProtocol, fmt.Sprintf("Unknown opcode %#x", frameOp)}
+		}
+		if int64(len(msg)+len(payload)) > c.max {
+			return 0, nil, &CloseError{CloseTooLarge, fmt.Sprintf("Message larger than %d bytes", c.max)}
+		}
+		msg = append(msg, payload...)
+		if fin {
+			return op, msg, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var h [8]byte
+	if _, err := io.ReadFull(c.rw, h[:2]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, op = h[0]&0x80 != 0, h[0]&0x0f
+	// Clients must mask their frames and servers must not.
+	if masked := h[1]&0x80 != 0; masked == c.client {
+		return false, 0, nil, &CloseError{CloseProtocol, "Wrongly masked frame"}
+	}
+	n := uint64(h[1] & 0x7f)
+	switch n {
+	case 126:
+		if _, err := io.ReadFull(c.rw, h[:2]); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(h[:2]))
+	case 127:
+		if _, err := io.ReadFull(c.rw, h[:8]); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(h[:8])
+	}
+	if n > uint64(c.max) {
+		return false, 0, nil, &CloseError{CloseTooLarge, fmt.Sprintf("Message larger than %d bytes", c.max)}
+	}
+	var mask [4]byte
+	if !c.client {
+		if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if !c.client {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, op, payload, nil
+}
+
+// WriteMessage writes payload as a single frame of op.
+func (c *Conn) WriteMessage(op byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h := []byte{0x80 | op}
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		h = append(h, maskBit|byte(n))
+	case n <= 0xffff:
+		h = append(h, maskBit|126)
+		h = binary.BigEndian.AppendUint16(h, uint16(n))
+	default:
+		h = append(h, maskBit|127)
+		h = binary.BigEndian.AppendUint64(h, uint64(n))
+	}
+	if c.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		h = append(h, mask[:]...)
+		masked := make([]byte, len(payload))
+		for i := range payload {
+			masked[i] = payload[i] ^ mask[i%4]
+		}
+		payload = masked
+	}
+	c.rw.Write(h)
+	c.rw.Write(payload)
+	return c.rw.Flush()
+}
+
+// WriteClose writes a close frame with code and reason.
+func (c *Conn) WriteClose(code int, reason string) error {
+	// Control frames are limited to 125 bytes, two of them the code.
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	return c.WriteMessage(OpClose, append(binary.BigEndian.AppendUint16(nil, uint16(code)), reason...))
+}
@@ -0,0 +1,89 @@
+package gorec
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultKeyCooldown is how long a KeyPool rests a key that ran out of quota
+// unless told otherwise.
+const defaultKeyCooldown = time.Hour
+
+// KeyPool spreads requests over several API keys in turn. A key that gets a
+// quota error rests for the pool's cooldown, the request moving on to the
+// next key straight away; once every key rests, requests fail with
+// ErrQuotaExceeded. Safe for concurrent use, and may be shared by Clients.
+type KeyPool struct {
+	keys     []string
+	cooldown time.Duration
+
+	mu      sync.Mutex
+	next    int
+	resting map[string]time.Time
+}
+
+// NewKeyPool returns a KeyPool of keys resting those that run out of quota
+// for cooldown, an hour if zero.
+func NewKeyPool(cooldown time.Duration, keys ...string) *KeyPool {
+	if cooldown <= 0 {
+		cooldown = defaultKeyCooldown
+	}
+	return &KeyPool{keys: append([]string(nil), keys...), cooldown: cooldown, resting: make(map[string]time.Time)}
+}
+
+// WithKeyPool sends the requests with the keys of p instead of the Client's
+// key.
+func WithKeyPool(p *KeyPool) Option {
+	return func(c *config) { c.keyPool = p }
+}
+
+// take returns the next key not resting at now.
+func (p *KeyPool) take(now time.Time) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.keys) == 0 {
+		return "", fmt.Errorf("%w: empty key pool", ErrUnauthorized)
+	}
+	var soonest time.Time
+	for range p.keys {
+		key := p.keys[p.next]
+		p.next = (p.next + 1) % len(p.keys)
+		until, ok := p.resting[key]
+		if !ok || !now.Before(until) {
+			delete(p.resting, key)
+			return key, nil
+		}
+		if soonest.IsZero() || until.Before(soonest) {
+			soonest = until
+		}
+	}
+	return "", fmt.Errorf("%w: all %d keys resting until %s", ErrQuotaExceeded, len(p.keys), soonest.Format(time.RFC3339))
+}
+
+// rest has key rest for the cooldown from now.
+func (p *KeyPool) rest(key string, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.resting[key] = now.Add(p.cooldown)
+}
+
+// available reports whether any key is not resting at now.
+func (p *KeyPool) available(now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, key := range p.keys {
+		if until, ok := p.resting[key]; !ok || !now.Before(until) {
+			return true
+		}
+	}
+	return false
+}
+
+// apiKey returns the key to send the next request with.
+func (c *Client) apiKey() (string, error) {
+	if c.cfg.keyPool == nil {
+		return c.key, nil
+	}
+	return c.cfg.keyPool.take(c.cfg.clock.Now())
+}
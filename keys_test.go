@@ -0,0 +1,62 @@
+package gorec
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeyPoolFailsOver(t *testing.T) {
+	var mu sync.Mutex
+	used := map[string]int{}
+	exhausted := map[string]bool{"a": true}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("key")
+		mu.Lock()
+		defer mu.Unlock()
+		used[key]++
+		if exhausted[key] {
+			http.Error(w, `{"error":{"status":"RESOURCE_EXHAUSTED"}}`, http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"result":[{"alternative":[{"transcript":"hi","confidence":0.9}],"final":true}]}`)
+	}))
+	defer srv.Close()
+	clk := newFakeClock()
+	pool := NewKeyPool(time.Minute, "a", "b")
+	c := NewClient("unused", WithEndpoint(srv.URL+"/?lang=%s&key=%s"), WithLanguages(English), WithKeyPool(pool), withClock(clk))
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.ListenFile([]byte{1, 2}); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if used["a"] != 1 || used["b"] != 3 || used["unused"] != 0 {
+		t.Errorf("keys used %v, want a once and then only b", used)
+	}
+
+	mu.Lock()
+	exhausted["b"] = true
+	mu.Unlock()
+	if _, err := c.ListenFile([]byte{1, 2}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("ListenFile with every key exhausted = %v, want ErrQuotaExceeded", err)
+	}
+	if used["a"] != 1 {
+		t.Errorf("resting key a used again: %v", used)
+	}
+
+	mu.Lock()
+	exhausted["a"] = false
+	mu.Unlock()
+	clk.Advance(time.Minute)
+	if _, err := c.ListenFile([]byte{1, 2}); err != nil {
+		t.Errorf("ListenFile after the cooldown: %v", err)
+	}
+	if used["a"] != 2 {
+		t.Errorf("keys used %v, want a again after its cooldown", used)
+	}
+}
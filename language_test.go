@@ -0,0 +1,102 @@
+package gorec
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestLanguageFromCode(t *testing.T) {
+	for code, want := range map[string]Language{
+		"en-US": English,
+		"EN_gb": English,
+		"en":    English,
+		"es-MX": Spanish,
+		"el-GR": Greek,
+		"el":    Greek,
+	} {
+		if l, err := LanguageFromCode(code); err != nil || l != want {
+			t.Errorf("LanguageFromCode(%q) = %v, %v, want %v", code, l, err, want)
+		}
+	}
+	if l, err := LanguageFromCode("pt-PT"); err != nil || l.StringCode() != "pt-pt" {
+		t.Errorf("LanguageFromCode(pt-PT) = %v, %v", l, err)
+	}
+	if _, err := LanguageFromCode("xx-yy"); !errors.Is(err, ErrUnknownLanguage) {
+		t.Errorf("LanguageFromCode(xx-yy) error = %v", err)
+	}
+}
+
+func TestLocaleFromCode(t *testing.T) {
+	if l, err := LocaleFromCode("en-US"); err != nil || l.String() != "English (United States)" {
+		t.Errorf("LocaleFromCode(en-US) = %v, %v", l, err)
+	}
+	if l, err := LocaleFromCode("en_GB"); err != nil || l != English {
+		t.Errorf("LocaleFromCode(en_GB) = %v, %v", l, err)
+	}
+	if _, err := LocaleFromCode("en"); !errors.Is(err, ErrUnknownLanguage) {
+		t.Errorf("LocaleFromCode(en) error = %v", err)
+	}
+}
+
+func TestLocalesByBase(t *testing.T) {
+	es := LocalesByBase()["es"]
+	if len(es) < 10 || es[0].StringCode() != "es-ar" {
+		t.Errorf("LocalesByBase()[es] = %v", es)
+	}
+}
+
+func TestParseLanguage(t *testing.T) {
+	for s, want := range map[string]string{
+		"fr-FR":                   "fr-fr",
+		"fr":                      "fr-fr",
+		"en-us":                   "en-us",
+		"English":                 "en-gb",
+		"english (united states)": "en-us",
+		"CMN-hans-CN":             "cmn-hans-cn",
+	} {
+		if l, err := ParseLanguage(s); err != nil || l.StringCode() != want {
+			t.Errorf("ParseLanguage(%q) = %v, %v, want %s", s, l, err, want)
+		}
+	}
+	if _, err := ParseLanguage("Klingon"); !errors.Is(err, ErrUnknownLanguage) {
+		t.Errorf("ParseLanguage(Klingon) error = %v", err)
+	}
+}
+
+func TestLanguageCode(t *testing.T) {
+	for code, want := range map[string]string{"en-gb": "en-GB", "el": "el", "cmn-hans-cn": "cmn-Hans-CN", "es-419": "es-419"} {
+		l, err := LocaleFromCode(code)
+		if err != nil {
+			l = RegisterLanguage(code, code)
+		}
+		if got := l.Code(); got != want {
+			t.Errorf("Code() of %s = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestLanguageJSON(t *testing.T) {
+	us, _ := LocaleFromCode("en-us")
+	for _, l := range []Language{English, French, Greek, us} {
+		b, err := json.Marshal(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Language
+		if err := json.Unmarshal(b, &got); err != nil || got != l {
+			t.Errorf("%s round-tripped as %v, %v", b, got, err)
+		}
+	}
+	var langs []Language
+	if err := json.Unmarshal([]byte(`["de-DE", "it", "spanish"]`), &langs); err != nil || len(langs) != 3 || langs[0] != German || langs[1] != Italian || langs[2] != Spanish {
+		t.Errorf("decoded %v, %v", langs, err)
+	}
+	var l Language
+	if err := json.Unmarshal([]byte(`3`), &l); err == nil {
+		t.Error("decoded a number as a language")
+	}
+	if err := json.Unmarshal([]byte(`"xx-yy"`), &l); !errors.Is(err, ErrUnknownLanguage) {
+		t.Errorf("decoding xx-yy returned %v", err)
+	}
+}
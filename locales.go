@@ -0,0 +1,145 @@
+package gorec
+
+import "sort"
+
+// locales are the other locales Google's endpoint recognizes, beyond the
+// languages queried by default. They have no constant of their own; find
+// them with LocaleFromCode or enumerate them with Locales.
+var locales = [][]string{
+	{"af-za", "Afrikaans (South Africa)"},
+	{"am-et", "Amharic (Ethiopia)"},
+	{"ar-ae", "Arabic (United Arab Emirates)"},
+	{"ar-bh", "Arabic (Bahrain)"},
+	{"ar-dz", "Arabic (Algeria)"},
+	{"ar-eg", "Arabic (Egypt)"},
+	{"ar-il", "Arabic (Israel)"},
+	{"ar-iq", "Arabic (Iraq)"},
+	{"ar-jo", "Arabic (Jordan)"},
+	{"ar-kw", "Arabic (Kuwait)"},
+	{"ar-lb", "Arabic (Lebanon)"},
+	{"ar-ma", "Arabic (Morocco)"},
+	{"ar-om", "Arabic (Oman)"},
+	{"ar-ps", "Arabic (Palestine)"},
+	{"ar-qa", "Arabic (Qatar)"},
+	{"ar-sa", "Arabic (Saudi Arabia)"},
+	{"ar-tn", "Arabic (Tunisia)"},
+	{"az-az", "Azerbaijani (Azerbaijan)"},
+	{"bg-bg", "Bulgarian (Bulgaria)"},
+	{"bn-bd", "Bengali (Bangladesh)"},
+	{"bn-in", "Bengali (India)"},
+	{"ca-es", "Catalan (Spain)"},
+	{"cmn-hans-cn", "Chinese, Mandarin (Simplified, China)"},
+	{"cmn-hans-hk", "Chinese, Mandarin (Simplified, Hong Kong)"},
+	{"cmn-hant-tw", "Chinese, Mandarin (Traditional, Taiwan)"},
+	{"cs-cz", "Czech (Czech Republic)"},
+	{"da-dk", "Danish (Denmark)"},
+	{"en-au", "English (Australia)"},
+	{"en-ca", "English (Canada)"},
+	{"en-gh", "English (Ghana)"},
+	{"en-ie", "English (Ireland)"},
+	{"en-in", "English (India)"},
+	{"en-ke", "English (Kenya)"},
+	{"en-ng", "English (Nigeria)"},
+	{"en-nz", "English (New Zealand)"},
+	{"en-ph", "English (Philippines)"},
+	{"en-tz", "English (Tanzania)"},
+	{"en-us", "English (United States)"},
+	{"en-za", "English (South Africa)"},
+	{"es-ar", "Spanish (Argentina)"},
+	{"es-bo", "Spanish (Bolivia)"},
+	{"es-cl", "Spanish (Chile)"},
+	{"es-co", "Spanish (Colombia)"},
+	{"es-cr", "Spanish (Costa Rica)"},
+	{"es-do", "Spanish (Dominican Republic)"},
+	{"es-ec", "Spanish (Ecuador)"},
+	{"es-gt", "Spanish (Guatemala)"},
+	{"es-hn", "Spanish (Honduras)"},
+	{"es-mx", "Spanish (Mexico)"},
+	{"es-ni", "Spanish (Nicaragua)"},
+	{"es-pa", "Spanish (Panama)"},
+	{"es-pe", "Spanish (Peru)"},
+	{"es-pr", "Spanish (Puerto Rico)"},
+	{"es-py", "Spanish (Paraguay)"},
+	{"es-sv", "Spanish (El Salvador)"},
+	{"es-us", "Spanish (United States)"},
+	{"es-uy", "Spanish (Uruguay)"},
+	{"es-ve", "Spanish (Venezuela)"},
+	{"eu-es", "Basque (Spain)"},
+	{"fa-ir", "Persian (Iran)"},
+	{"fi-fi", "Finnish (Finland)"},
+	{"fil-ph", "Filipino (Philippines)"},
+	{"gl-es", "Galician (Spain)"},
+	{"gu-in", "Gujarati (India)"},
+	{"he-il", "Hebrew (Israel)"},
+	{"hi-in", "Hindi (India)"},
+	{"hr-hr", "Croatian (Croatia)"},
+	{"hu-hu", "Hungarian (Hungary)"},
+	{"hy-am", "Armenian (Armenia)"},
+	{"id-id", "Indonesian (Indonesia)"},
+	{"is-is", "Icelandic (Iceland)"},
+	{"ja-jp", "Japanese (Japan)"},
+	{"jv-id", "Javanese (Indonesia)"},
+	{"ka-ge", "Georgian (Georgia)"},
+	{"km-kh", "Khmer (Cambodia)"},
+	{"kn-in", "Kannada (India)"},
+	{"ko-kr", "Korean (South Korea)"},
+	{"lo-la", "Lao (Laos)"},
+	{"lt-lt", "Lithuanian (Lithuania)"},
+	{"lv-lv", "Latvian (Latvia)"},
+	{"ml-in", "Malayalam (India)"},
+	{"mr-in", "Marathi (India)"},
+	{"ms-my", "Malay (Malaysia)"},
+	{"nb-no", "Norwegian Bokmål (Norway)"},
+	{"ne-np", "Nepali (Nepal)"},
+	{"nl-nl", "Dutch (Netherlands)"},
+	{"pl-pl", "Polish (Poland)"},
+	{"pt-br", "Portuguese (Brazil)"},
+	{"pt-pt", "Portuguese (Portugal)"},
+	{"ro-ro", "Romanian (Romania)"},
+	{"ru-ru", "Russian (Russia)"},
+	{"si-lk", "Sinhala (Sri Lanka)"},
+	{"sk-sk", "Slovak (Slovakia)"},
+	{"sl-si", "Slovenian (Slovenia)"},
+	{"sr-rs", "Serbian (Serbia)"},
+	{"su-id", "Sundanese (Indonesia)"},
+	{"sv-se", "Swedish (Sweden)"},
+	{"sw-ke", "Swahili (Kenya)"},
+	{"sw-tz", "Swahili (Tanzania)"},
+	{"ta-in", "Tamil (India)"},
+	{"ta-lk", "Tamil (Sri Lanka)"},
+	{"ta-my", "Tamil (Malaysia)"},
+	{"ta-sg", "Tamil (Singapore)"},
+	{"te-in", "Telugu (India)"},
+	{"th-th", "Thai (Thailand)"},
+	{"tr-tr", "Turkish (Turkey)"},
+	{"uk-ua", "Ukrainian (Ukraine)"},
+	{"ur-in", "Urdu (India)"},
+	{"ur-pk", "Urdu (Pakistan)"},
+	{"vi-vn", "Vietnamese (Vietnam)"},
+	{"yue-hant-hk", "Chinese, Cantonese (Traditional, Hong Kong)"},
+	{"zu-za", "Zulu (South Africa)"},
+}
+
+// Locales returns every language known, the built-in and registered ones,
+// ordered by code.
+func Locales() []Language {
+	langsMu.RLock()
+	all := make([]Language, len(langs))
+	for i := range langs {
+		all[i] = Language(i)
+	}
+	langsMu.RUnlock()
+	sort.Slice(all, func(i, j int) bool { return all[i].StringCode() < all[j].StringCode() })
+	return all
+}
+
+// LocalesByBase groups Locales by base language, the part of the code before
+// the first hyphen: "es" holds es-es, es-mx and the other Spanish variants.
+func LocalesByBase() map[string][]Language {
+	byBase := make(map[string][]Language)
+	for _, l := range Locales() {
+		base := baseCode(l.StringCode())
+		byBase[base] = append(byBase[base], l)
+	}
+	return byBase
+}
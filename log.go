@@ -0,0 +1,70 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Logger receives the package's diagnostics. *slog.Logger satisfies it.
+type Logger interface {
+	Log(ctx context.Context, level slog.Level, msg string, args ...any)
+}
+
+func (c *Client) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if c.cfg.logger != nil {
+		c.cfg.logger.Log(ctx, level, msg, args...)
+	}
+}
+
+// logResponse records the outcome of a request to lang that took latency.
+func (c *Client) logResponse(ctx context.Context, lang Language, latency time.Duration, err error) {
+	if c.cfg.logger == nil {
+		return
+	}
+	args := []any{"language", lang.StringCode(), "latency", latency}
+	status := c.status(err)
+	if status != 0 {
+		args = append(args, "status", status)
+	}
+	switch {
+	case err != nil && status != 0:
+		c.log(ctx, slog.LevelWarn, "request failed", append(args, "error", err)...)
+	case err != nil:
+		c.log(ctx, slog.LevelDebug, "request failed", append(args, "error", err)...)
+	default:
+		c.log(ctx, slog.LevelDebug, "request done", args...)
+	}
+}
+
+// status is the HTTP status of a request that returned err, zero if not
+// known.
+func (c *Client) status(err error) int {
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.StatusCode
+	case err == nil && c.cfg.backend == nil:
+		return http.StatusOK
+	}
+	return 0
+}
+
+// logChoice records the hypotheses a call chose best among.
+func (c *Client) logChoice(ctx context.Context, hs []Hypothesis, best *Hypothesis) {
+	if c.cfg.logger == nil {
+		return
+	}
+	for _, h := range hs {
+		if h.Err != nil {
+			c.log(ctx, slog.LevelDebug, "candidate", "language", h.Language.StringCode(), "error", h.Err)
+			continue
+		}
+		c.log(ctx, slog.LevelDebug, "candidate", "language", h.Language.StringCode(),
+			"confidence", h.Alternative.Confidence, "score", c.score(h.Alternative, h.Language))
+	}
+	c.log(ctx, slog.LevelInfo, "chose hypothesis", "language", best.Language.StringCode(),
+		"confidence", best.Alternative.Confidence, "partial", best.Partial)
+}
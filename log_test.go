@@ -0,0 +1,98 @@
+package gorec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type logEntry struct {
+	level slog.Level
+	msg   string
+	args  []any
+}
+
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordingLogger) Log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, logEntry{level, msg, args})
+}
+
+func TestResponseSchemas(t *testing.T) {
+	for _, body := range []string{
+		`{"result":[{"alternative":[{"transcript":"a","confidence":0.5}],"final":true}],"result_index":1}`,
+		`{"results":[{"alternatives":[{"transcript":"a","confidence":0.5}],"isFinal":true}],"resultIndex":1,"extra":{}}`,
+	} {
+		gr := &GoogleResponse{}
+		if err := json.Unmarshal([]byte(body), gr); err != nil {
+			t.Fatal(err)
+		}
+		if gr.ResultIndex != 1 || !gr.Results[0].Final || gr.Results[0].Alternatives[0].Transcript != "a" {
+			t.Errorf("%s decoded to %+v", body, gr)
+		}
+	}
+}
+
+func TestLoggerWarnsOnUnknownSchema(t *testing.T) {
+	const body = `{"outcome":[{"hypotheses":[{"text":"hi","score":0.9}]}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+	l := &recordingLogger{}
+	_, err := Recognize([]byte{1, 2}, "k", English,
+		WithLanguageEndpoint(map[Language]string{English: srv.URL + "/?lang=%s&key=%s"}),
+		WithLogger(l), WithPhraseHints([]string{"hi"}))
+	if !errors.Is(err, ErrNoSpeech) {
+		t.Fatalf("Recognize = %v, want ErrNoSpeech", err)
+	}
+	var warned, debugged bool
+	for _, e := range l.entries {
+		switch e.level {
+		case slog.LevelWarn:
+			warned = e.msg == "response has no results" && fmt.Sprint(e.args...) == fmt.Sprint("language", "en-gb", "body", body)
+		case slog.LevelDebug:
+			debugged = true
+		}
+	}
+	if !warned || !debugged {
+		t.Errorf("logged %+v", l.entries)
+	}
+}
+
+func TestLoggerRecordsRequestsAndChoice(t *testing.T) {
+	srv, eps := newLanguageServer(map[string]string{
+		"fr-fr": `{"result":[{"alternative":[{"transcript":"bonjour","confidence":0.9}],"final":true}]}`,
+		"es-es": `{"result":[{"alternative":[{"transcript":"bueno","confidence":0.4}],"final":true}]}`,
+	})
+	defer srv.Close()
+	l := &recordingLogger{}
+	if _, err := ListenFile([]byte{1, 2}, "secret-key", WithLanguageEndpoint(eps), WithLanguages(French, Spanish), WithLogger(l)); err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int{}
+	for _, e := range l.entries {
+		counts[e.msg]++
+		if s := fmt.Sprint(e.args...); strings.Contains(s, "secret-key") {
+			t.Errorf("%s logged the key: %s", e.msg, s)
+		}
+		if e.msg == "chose hypothesis" && fmt.Sprint(e.args...) != fmt.Sprint("language", "fr-fr", "confidence", 0.9, "partial", false) {
+			t.Errorf("chose hypothesis with %v", e.args)
+		}
+	}
+	if counts["sending request"] != 2 || counts["request done"] != 2 || counts["candidate"] != 2 || counts["chose hypothesis"] != 1 {
+		t.Errorf("logged %v", counts)
+	}
+}
@@ -0,0 +1,215 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// longPause is the shortest pause ListenLong cuts audio on.
+	longPause = 500 * time.Millisecond
+
+	// longOverlap is how much consecutive chunks share when speech runs on
+	// for longer than a chunk and has to be cut without a pause.
+	longOverlap = time.Second
+)
+
+// longChunk is a piece of the audio ListenLong recognizes on its own.
+type longChunk struct {
+	offset time.Duration
+	audio  []byte
+
+	// overlaps reports whether the chunk starts with the end of the
+	// previous one.
+	overlaps bool
+}
+
+func ListenLong(audio []byte, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenLong(audio)
+}
+
+func ListenLongContext(ctx context.Context, audio []byte, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenLongContext(ctx, audio)
+}
+
+// ListenLong recognizes linear PCM of any length. The audio is split into
+// chunks no longer than WithMaxDuration, 15 seconds by default, on its
+// pauses where it has any and into chunks overlapping by a second where
+// speech runs on. The chunks are recognized in parallel, at most
+// WithMaxConcurrency at a time, and their transcripts stitched in order
+// with the Client's ChunkMerger, dropping the words the overlaps repeat.
+// The Words of the result are timed from the start of audio, spread over
+// their chunk when the backend doesn't time them.
+// Chunks that hear no speech are left out; a chunk that fails fails the
+// call with a *ClipError.
+func (c *Client) ListenLong(audio []byte, opts ...Option) (*Hypothesis, error) {
+	return c.ListenLongContext(context.Background(), audio, opts...)
+}
+
+// ListenLongContext is ListenLong abandoning the chunks in flight once ctx
+// is done.
+func (c *Client) ListenLongContext(ctx context.Context, audio []byte, opts ...Option) (*Hypothesis, error) {
+	c = c.with(opts)
+	c, audio, err := c.prepare(audio)
+	if err != nil {
+		return nil, err
+	}
+	if len(audio) == 0 {
+		return nil, ErrEmptyAudio
+	}
+	if !isL16(c.cfg.contentType) {
+		return nil, fmt.Errorf("%w: ListenLong needs linear PCM, not %s", ErrUnsupportedFormat, c.cfg.contentType)
+	}
+	chunks := c.longChunks(audio)
+	if len(chunks) == 0 {
+		return nil, ErrNoSpeech
+	}
+	hs, err := c.listenChunks(ctx, chunks)
+	c.completed(err)
+	if err != nil {
+		return nil, err
+	}
+	var heard []Hypothesis
+	for i, h := range hs {
+		if h.Err != nil {
+			continue
+		}
+		ch := chunks[i]
+		words := h.Alternative.Words
+		if len(words) == 0 {
+			end := ch.offset + DurationOf(ch.audio, c.sampleRate(), 1, 16)
+			words = estimateWords(h.Alternative.Transcript, ch.offset, end)
+		} else {
+			words = shiftWords(words, ch.offset)
+		}
+		if ch.overlaps && hs[i-1].Err == nil {
+			fields := strings.Fields(h.Alternative.Transcript)
+			k := overlapWords(heard[len(heard)-1].Alternative.Transcript, fields)
+			h.Alternative.Transcript = strings.Join(fields[k:], " ")
+			if len(words) == len(fields) {
+				words = words[k:]
+			}
+		}
+		h.Alternative.Words = words
+		heard = append(heard, h)
+	}
+	if len(heard) == 0 {
+		return nil, ErrNoSpeech
+	}
+	return c.mergeChunks(heard), nil
+}
+
+// longChunks splits pcm into the chunks ListenLong recognizes.
+func (c *Client) longChunks(pcm []byte) []longChunk {
+	rate := c.sampleRate()
+	max := c.cfg.maxDuration
+	if max <= 0 {
+		max = defaultMaxReadDuration
+	}
+	maxBytes, step := bytesFor(max, rate), bytesFor(max-longOverlap, rate)
+	if step <= 0 {
+		step = maxBytes
+	}
+	var chunks []longChunk
+	from, to := -1, -1
+	flush := func() {
+		if from >= 0 {
+			chunks = append(chunks, longChunk{offset: DurationOf(pcm[:from], rate, 1, 16), audio: pcm[from:to]})
+		}
+		from = -1
+	}
+	for _, s := range (VAD{SampleRate: rate}).Split(pcm, longPause) {
+		start, end := bytesFor(s.Start, rate), bytesFor(s.Start, rate)+len(s.Audio)
+		if from >= 0 && end-from <= maxBytes {
+			to = end
+			continue
+		}
+		flush()
+		if end-start <= maxBytes {
+			from, to = start, end
+			continue
+		}
+		// Speech without a pause long enough to cut on.
+		for off := start; off < end; off += step {
+			chunks = append(chunks, longChunk{
+				offset:   DurationOf(pcm[:off], rate, 1, 16),
+				audio:    pcm[off:min(off+maxBytes, end)],
+				overlaps: off > start,
+			})
+			if off+maxBytes >= end {
+				break
+			}
+		}
+	}
+	flush()
+	return chunks
+}
+
+// listenChunks recognizes chunks in parallel, returning their hypotheses in
+// order, with ErrNoSpeech as the Err of those that heard nothing.
+func (c *Client) listenChunks(ctx context.Context, chunks []longChunk) ([]Hypothesis, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	workers := c.cfg.maxConcurrency
+	if workers <= 0 || workers > len(chunks) {
+		workers = len(chunks)
+	}
+	queue := make(chan int, len(chunks))
+	for i := range chunks {
+		queue <- i
+	}
+	close(queue)
+	hs := make([]Hypothesis, len(chunks))
+	var failed *ClipError
+	var once sync.Once
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				audio := chunks[i].audio
+				c.chunkStarted(i, len(chunks))
+				h, err := c.listenBest(ctx, bytes.NewReader(audio), int64(len(audio)))
+				if err != nil {
+					c.chunkDone(i, len(chunks), Hypothesis{Err: err})
+				} else {
+					c.chunkDone(i, len(chunks), *h)
+				}
+				switch {
+				case errors.Is(err, ErrNoSpeech):
+					hs[i] = Hypothesis{Err: err}
+				case err != nil:
+					once.Do(func() {
+						failed = &ClipError{Index: i, Err: err}
+						cancel()
+					})
+				default:
+					hs[i] = *h
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if failed != nil {
+		return nil, failed
+	}
+	return hs, nil
+}
+
+// overlapWords returns how many of the words next starts with end prev, as
+// the overlap of two chunks has them recognized twice.
+func overlapWords(prev string, next []string) int {
+	p := strings.Fields(prev)
+	for k := min(len(p), len(next)); k > 0; k-- {
+		if strings.EqualFold(strings.Join(p[len(p)-k:], " "), strings.Join(next[:k], " ")) {
+			return k
+		}
+	}
+	return 0
+}
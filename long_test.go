@@ -0,0 +1,86 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// durationBackend transcribes audio as its length in whole seconds.
+type durationBackend struct{}
+
+func (durationBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	seconds := DurationOf(make([]byte, size), defaultSampleRate, 1, 16).Round(time.Second) / time.Second
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{{Transcript: fmt.Sprintf("s%d", seconds), Confidence: 0.8}}, Final: true}}}, nil
+}
+
+func TestListenLongSplitsOnPauses(t *testing.T) {
+	pcm := speechPCM(40*time.Second,
+		[2]time.Duration{0, 10 * time.Second},
+		[2]time.Duration{12 * time.Second, 20 * time.Second},
+		[2]time.Duration{25 * time.Second, 38 * time.Second})
+	h, err := ListenLong(pcm, "k", WithBackend(durationBackend{}), WithLanguages(English), WithMaxConcurrency(2))
+	if err != nil || h.Alternative.Transcript != "s10 s8 s13" {
+		t.Fatalf("ListenLong = %v, %v", h, err)
+	}
+	want := []Word{
+		{Word: "s10", Start: 0, End: 10 * time.Second, Estimated: true},
+		{Word: "s8", Start: 12 * time.Second, End: 20 * time.Second, Estimated: true},
+		{Word: "s13", Start: 25 * time.Second, End: 38 * time.Second, Estimated: true},
+	}
+	// Spans are cut on VAD frames, 30 ms apart.
+	var got []Word
+	for _, w := range h.Alternative.Words {
+		w.Start, w.End = w.Start.Round(100*time.Millisecond), w.End.Round(100*time.Millisecond)
+		got = append(got, w)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Words = %v, want %v", h.Alternative.Words, want)
+	}
+}
+
+func TestLongChunksOverlap(t *testing.T) {
+	c := NewClient("k")
+	defer c.Close()
+	chunks := c.longChunks(speechPCM(40*time.Second, [2]time.Duration{0, 40 * time.Second}))
+	want := []struct {
+		offset, length time.Duration
+		overlaps       bool
+	}{{0, 15 * time.Second, false}, {14 * time.Second, 15 * time.Second, true}, {28 * time.Second, 12 * time.Second, true}}
+	if len(chunks) != len(want) {
+		t.Fatalf("%d chunks, want %d", len(chunks), len(want))
+	}
+	for i, w := range want {
+		ch := chunks[i]
+		if got := DurationOf(ch.audio, defaultSampleRate, 1, 16); ch.offset != w.offset || got != w.length || ch.overlaps != w.overlaps {
+			t.Errorf("chunk %d at %v, %v long, overlapping %v; want %+v", i, ch.offset, got, ch.overlaps, w)
+		}
+	}
+}
+
+func TestOverlapWords(t *testing.T) {
+	for _, c := range []struct {
+		prev, next string
+		want       int
+	}{
+		{"the quick brown fox", "Brown fox jumps over", 2},
+		{"the quick brown fox", "jumps over", 0},
+		{"", "jumps", 0},
+		{"fox", "fox", 1},
+	} {
+		if got := overlapWords(c.prev, strings.Fields(c.next)); got != c.want {
+			t.Errorf("overlapWords(%q, %q) = %d, want %d", c.prev, c.next, got, c.want)
+		}
+	}
+}
+
+func TestListenLongSilence(t *testing.T) {
+	if _, err := ListenLong(make([]byte, 32000), "k", WithBackend(durationBackend{})); !errors.Is(err, ErrNoSpeech) {
+		t.Errorf("ListenLong of silence = %v, want ErrNoSpeech", err)
+	}
+}
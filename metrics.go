@@ -0,0 +1,170 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+var (
+	latencyBuckets    = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+	confidenceBuckets = []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1}
+)
+
+// Metrics counts the requests of the Clients given it with WithMetrics: how
+// many were sent per language and how they ended, a request and its retries
+// counting once, how long they took and how confident their transcripts
+// were. It serves them in the Prometheus text exposition format as an
+// http.Handler, to be scraped directly or mounted on a server's /metrics.
+//
+// The outcome label is one of ok, no_speech, quota_exceeded, unauthorized,
+// timeout, canceled and error.
+type Metrics struct {
+	mu         sync.Mutex
+	requests   map[requestKey]uint64
+	latency    map[Language]*histogram
+	confidence map[Language]*histogram
+}
+
+type requestKey struct {
+	lang    Language
+	outcome string
+}
+
+type histogram struct {
+	bounds []float64
+	// counts[i] is the number of observations up to bounds[i].
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewMetrics returns empty Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests:   make(map[requestKey]uint64),
+		latency:    make(map[Language]*histogram),
+		confidence: make(map[Language]*histogram),
+	}
+}
+
+// WithMetrics records every request in m.
+func WithMetrics(m *Metrics) Option {
+	return func(c *config) { c.metrics = m }
+}
+
+// observe records the request that returned h and err.
+func (m *Metrics) observe(h *Hypothesis, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[requestKey{h.Language, outcome(err)}]++
+	if h.Latency > 0 {
+		observeIn(m.latency, h.Language, latencyBuckets, h.Latency.Seconds())
+	}
+	if err == nil {
+		observeIn(m.confidence, h.Language, confidenceBuckets, h.Alternative.Confidence)
+	}
+}
+
+func observeIn(hs map[Language]*histogram, lang Language, bounds []float64, v float64) {
+	h, ok := hs[lang]
+	if !ok {
+		h = &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+		hs[lang] = h
+	}
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, ErrNoSpeech):
+		return "no_speech"
+	case errors.Is(err, ErrQuotaExceeded):
+		return "quota_exceeded"
+	case errors.Is(err, ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, ErrTimeout), isTimeout(err):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	}
+	return "error"
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cw := &countingWriter{w: w}
+	fmt.Fprint(cw, "# HELP gorec_requests_total Recognition requests sent, by language and outcome.\n")
+	fmt.Fprint(cw, "# TYPE gorec_requests_total counter\n")
+	keys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].lang != keys[j].lang {
+			return keys[i].lang.StringCode() < keys[j].lang.StringCode()
+		}
+		return keys[i].outcome < keys[j].outcome
+	})
+	for _, k := range keys {
+		fmt.Fprintf(cw, "gorec_requests_total{language=%q,outcome=%q} %d\n", k.lang.StringCode(), k.outcome, m.requests[k])
+	}
+	writeHistograms(cw, "gorec_request_duration_seconds", "Latency of the recognition requests, by language.", m.latency)
+	writeHistograms(cw, "gorec_confidence", "Confidence of the transcripts recognized, by language.", m.confidence)
+	return cw.n, cw.err
+}
+
+func writeHistograms(w io.Writer, name, help string, hs map[Language]*histogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	langs := make([]Language, 0, len(hs))
+	for lang := range hs {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool { return langs[i].StringCode() < langs[j].StringCode() })
+	for _, lang := range langs {
+		h, code := hs[lang], lang.StringCode()
+		for i, bound := range h.bounds {
+			fmt.Fprintf(w, "%s_bucket{language=%q,le=%q} %d\n", name, code, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{language=%q,le=\"+Inf\"} %d\n", name, code, h.count)
+		fmt.Fprintf(w, "%s_sum{language=%q} %s\n", name, code, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(w, "%s_count{language=%q} %d\n", name, code, h.count)
+	}
+}
+
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
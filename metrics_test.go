@@ -0,0 +1,43 @@
+package gorec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	srv, eps := newLanguageServer(map[string]string{
+		"fr-fr": `{"result":[{"alternative":[{"transcript":"bonjour","confidence":0.85}],"final":true}]}`,
+		"es-es": `{"result":[{"alternative":[{"transcript":"bueno","confidence":0.4}],"final":true}]}`,
+	})
+	defer srv.Close()
+	m := NewMetrics()
+	c := NewClient("k", WithLanguageEndpoint(eps), WithLanguages(French, Spanish, English), WithMetrics(m))
+	for i := 0; i < 2; i++ {
+		if _, err := c.ListenFile([]byte{1, 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var b strings.Builder
+	if _, err := m.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"# TYPE gorec_requests_total counter\n",
+		`gorec_requests_total{language="en-gb",outcome="no_speech"} 2` + "\n",
+		`gorec_requests_total{language="fr-fr",outcome="ok"} 2` + "\n",
+		`gorec_request_duration_seconds_count{language="es-es"} 2` + "\n",
+		`gorec_confidence_bucket{language="fr-fr",le="0.8"} 0` + "\n",
+		`gorec_confidence_bucket{language="fr-fr",le="0.9"} 2` + "\n",
+		`gorec_confidence_bucket{language="es-es",le="+Inf"} 2` + "\n",
+		`gorec_confidence_sum{language="fr-fr"} 1.7` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics lack %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `gorec_confidence_count{language="en-gb"}`) {
+		t.Errorf("confidence recorded for the empty response:\n%s", out)
+	}
+}
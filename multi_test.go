@@ -0,0 +1,38 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// stallingMulti is a MultiLanguageBackend answering only once ctx is done.
+type stallingMulti struct{ coverageBackend }
+
+func (stallingMulti) RecognizeAny(ctx context.Context, audio io.Reader, size int64, langs []Language, p BackendParams) (*GoogleResponse, Language, error) {
+	<-ctx.Done()
+	return nil, 0, ctx.Err()
+}
+
+func TestListenMultiTimeouts(t *testing.T) {
+	for name, opt := range map[string]Option{
+		"WithTimeout":        WithTimeout(20 * time.Millisecond),
+		"WithRequestTimeout": WithRequestTimeout(20 * time.Millisecond),
+	} {
+		done := make(chan error, 1)
+		go func() {
+			_, err := ListenFile([]byte{1, 2}, "k", WithBackend(stallingMulti{}), WithSingleRequestMultiLang(true), opt)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err == nil || errors.Is(err, context.Canceled) {
+				t.Errorf("%s: ListenFile = %v", name, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: the single multi-language request outlived the timeout", name)
+		}
+	}
+}
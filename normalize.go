@@ -0,0 +1,202 @@
+package gorec
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// chain is the Normalizer applying each of ns in turn, nil if there are
+// none.
+func chain(ns []Normalizer) Normalizer {
+	if len(ns) == 0 {
+		return nil
+	}
+	return func(transcript string) string {
+		for _, n := range ns {
+			transcript = n(transcript)
+		}
+		return transcript
+	}
+}
+
+// Capitalize is a Normalizer capitalizing the first letter of the
+// transcript and of every sentence after a ".", "!" or "?".
+func Capitalize(transcript string) string {
+	var b strings.Builder
+	start := true
+	for _, r := range transcript {
+		switch {
+		case start && unicode.IsLetter(r):
+			r = unicode.ToUpper(r)
+			start = false
+		case r == '.' || r == '!' || r == '?':
+			start = true
+		case !unicode.IsSpace(r):
+			start = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Punctuate is a Normalizer ending the transcript with a full stop unless
+// it already ends with a ".", "!", "?" or "…".
+func Punctuate(transcript string) string {
+	transcript = strings.TrimRightFunc(transcript, unicode.IsSpace)
+	if transcript == "" {
+		return transcript
+	}
+	if r, _ := utf8.DecodeLastRuneInString(transcript); strings.ContainsRune(".!?…", r) {
+		return transcript
+	}
+	return transcript + "."
+}
+
+// Replacements is a Normalizer replacing each whole word or phrase in
+// dict, regardless of case, with its value, such as "gee mail" with
+// "Gmail". Longer phrases are replaced first.
+func Replacements(dict map[string]string) Normalizer {
+	phrases := make([]string, 0, len(dict))
+	for p := range dict {
+		if strings.TrimSpace(p) != "" {
+			phrases = append(phrases, p)
+		}
+	}
+	if len(phrases) == 0 {
+		return func(transcript string) string { return transcript }
+	}
+	sort.Slice(phrases, func(i, j int) bool {
+		if len(phrases[i]) != len(phrases[j]) {
+			return len(phrases[i]) > len(phrases[j])
+		}
+		return phrases[i] < phrases[j]
+	})
+	byLower := make(map[string]string, len(phrases))
+	quoted := make([]string, len(phrases))
+	for i, p := range phrases {
+		byLower[strings.ToLower(p)] = dict[p]
+		quoted[i] = regexp.QuoteMeta(p)
+	}
+	re := regexp.MustCompile(`(?i)(^|[^\pL\pN])(` + strings.Join(quoted, "|") + `)([^\pL\pN]|$)`)
+	return func(transcript string) string {
+		// The boundary after a match is put back and searched again, so
+		// adjacent phrases are both replaced.
+		var b strings.Builder
+		for {
+			m := re.FindStringSubmatchIndex(transcript)
+			if m == nil {
+				b.WriteString(transcript)
+				return b.String()
+			}
+			b.WriteString(transcript[:m[3]])
+			b.WriteString(byLower[strings.ToLower(transcript[m[4]:m[5]])])
+			transcript = transcript[m[5]:]
+		}
+	}
+}
+
+// numberWords are the English number words NumbersToDigits reads, and
+// numberScales those multiplying what comes before them.
+var (
+	numberWords = map[string]int{
+		"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9,
+		"ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14, "fifteen": 15, "sixteen": 16,
+		"seventeen": 17, "eighteen": 18, "nineteen": 19,
+		"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+	}
+	numberScales = map[string]int{"thousand": 1000, "million": 1000000, "billion": 1000000000}
+)
+
+// NumbersToDigits is a Normalizer writing English numbers spelled out in
+// words as digits, "two hundred and forty five" becoming "245". Numbers of
+// a single word below ten are left as words, as in "one of them".
+func NumbersToDigits(transcript string) string {
+	words := strings.Fields(transcript)
+	out := make([]string, 0, len(words))
+	for i := 0; i < len(words); {
+		n, used, punct := readNumber(words[i:])
+		if used == 0 || (used == 1 && n < 10) {
+			out = append(out, words[i])
+			i++
+			continue
+		}
+		out = append(out, strconv.Itoa(n)+punct)
+		i += used
+	}
+	return strings.Join(out, " ")
+}
+
+// readNumber reads the number spelled at the start of words, returning it,
+// how many words it took, none if there is no number, and the punctuation
+// after its last word.
+func readNumber(words []string) (n, used int, punct string) {
+	// last is what the previous word was: 0 nothing, 1 a unit, 2 a teen,
+	// 3 a multiple of ten, 4 "hundred" and 5 a scale.
+	var total, current, last int
+	scale := 0
+	for i, raw := range words {
+		w := strings.ToLower(strings.TrimRight(raw, ".,!?;:"))
+		p := raw[len(w):]
+		v, isWord := numberWords[w]
+		s, isScale := numberScales[w]
+		switch {
+		case w == "zero":
+			if i > 0 {
+				return total + current, used, punct
+			}
+			return 0, 1, p
+		case isWord && v < 10:
+			if last != 0 && last != 3 && last != 4 && last != 5 {
+				return total + current, used, punct
+			}
+			current += v
+			last = 1
+		case isWord && v < 20:
+			if last != 0 && last != 4 && last != 5 {
+				return total + current, used, punct
+			}
+			current += v
+			last = 2
+		case isWord:
+			if last != 0 && last != 4 && last != 5 {
+				return total + current, used, punct
+			}
+			current += v
+			last = 3
+		case w == "hundred":
+			if last == 0 || last == 4 || last == 5 || current >= 100 {
+				return total + current, used, punct
+			}
+			current *= 100
+			last = 4
+		case isScale:
+			if current == 0 || (scale != 0 && s >= scale) {
+				return total + current, used, punct
+			}
+			total += current * s
+			current, scale = 0, s
+			last = 5
+		case w == "and":
+			// "and" belongs to the number only between "hundred" or a
+			// scale and the rest of it.
+			if (last != 4 && last != 5) || p != "" || i+1 == len(words) {
+				return total + current, used, punct
+			}
+			if _, ok := numberWords[strings.ToLower(strings.TrimRight(words[i+1], ".,!?;:"))]; !ok {
+				return total + current, used, punct
+			}
+			continue
+		default:
+			return total + current, used, punct
+		}
+		used, punct = i+1, p
+		if p != "" {
+			break
+		}
+	}
+	return total + current, used, punct
+}
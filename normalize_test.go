@@ -0,0 +1,66 @@
+package gorec
+
+import "testing"
+
+func TestCapitalize(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                               "",
+		"hello there. how are you? fine": "Hello there. How are you? Fine",
+		"  ok! émile said 3.5 times":     "  Ok! Émile said 3.5 times",
+	} {
+		if got := Capitalize(in); got != want {
+			t.Errorf("Capitalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPunctuate(t *testing.T) {
+	for in, want := range map[string]string{"": "", "hello": "hello.", "hello ": "hello.", "really?": "really?", "well…": "well…"} {
+		if got := Punctuate(in); got != want {
+			t.Errorf("Punctuate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestReplacements(t *testing.T) {
+	r := Replacements(map[string]string{"gee mail": "Gmail", "gee": "G", "go lang": "Go"})
+	for in, want := range map[string]string{
+		"send it to gee mail":      "send it to Gmail",
+		"Gee, go lang gee":         "G, Go G",
+		"geese are not gee mailed": "geese are not G mailed",
+	} {
+		if got := r(in); got != want {
+			t.Errorf("replaced %q with %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNumbersToDigits(t *testing.T) {
+	for in, want := range map[string]string{
+		"one of them":                             "one of them",
+		"twenty three apples":                     "23 apples",
+		"two hundred and forty five.":             "245.",
+		"call me at nine fifteen":                 "call me at nine 15",
+		"three thousand four hundred and twelve":  "3412",
+		"one million two hundred thousand people": "1200000 people",
+		"rock and roll hundred":                   "rock and roll hundred",
+		"a hundred and one nights":                "a hundred and one nights",
+		"I want zero":                             "I want zero",
+		"fifty, sixty":                            "50, 60",
+	} {
+		if got := NumbersToDigits(in); got != want {
+			t.Errorf("NumbersToDigits(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWithNormalizer(t *testing.T) {
+	h, err := ListenFile([]byte("twenty two gee mail accounts"), "k", WithBackend(&echoBackend{}), WithLanguages(English),
+		WithNormalizer(Replacements(map[string]string{"gee mail": "Gmail"}), NumbersToDigits, Punctuate, Capitalize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Alternative.Transcript != "22 Gmail accounts." {
+		t.Errorf("transcript %q", h.Alternative.Transcript)
+	}
+}
@@ -0,0 +1,500 @@
+package gorec
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Option func(*config)
+
+type config struct {
+	includeErrors bool
+	rawCapture    bool
+	contentType   string
+	threshold     float64
+	coverage      *Coverage
+	clientParam   string
+	inOrder       bool
+	limiter       Limiter
+	preferFinal   bool
+	maxUpload     int64
+	chunkMerger   ChunkMerger
+	trimSilence   bool
+	vad           *VAD
+	leadPadding   time.Duration
+	languages     []Language
+	logger        Logger
+	maxDuration   time.Duration
+	endpoint      string
+	endpoints     map[Language]string
+	minConfidence map[Language]float64
+	preprocess    Pipeline
+	clock         clock
+	phraseHints   []string
+	normalizer    Normalizer
+	sequential    bool
+	backend       Backend
+	singleRequest bool
+	strict        bool
+	anomalyHook   func(lang Language, raw []byte)
+	scorer        Scorer
+
+	redirectPolicy func(req *http.Request, via []*http.Request) error
+	httpClient     *http.Client
+	timeout        time.Duration
+	requestTimeout time.Duration
+	retry          RetryPolicy
+	maxConcurrency int
+
+	// The transport is rebuilt from proxy and the TLS settings whenever an
+	// option changes them.
+	proxy              *url.URL
+	rootCAs            *x509.CertPool
+	insecureSkipVerify bool
+	transport          *http.Transport
+	transportChanged   bool
+	inputRate          int
+	keepRate           bool
+	maxAlternatives    int
+	minConfidenceAll   float64
+
+	selectAlternative AlternativeSelector
+
+	filePattern string
+	resume      map[string]Hypothesis
+	hooks       Hooks
+	metrics     *Metrics
+	tracer      Tracer
+	cache       Cache
+	keyPool     *KeyPool
+
+	twoPassPrefix time.Duration
+	twoPassTop    int
+	profanity     ProfanityLevel
+	interim       time.Duration
+	translator    Translator
+	translateTo   Language
+	inputChannels int
+	fetchers      map[string]Fetcher
+	selector      Selector
+	format        *AudioFormat
+	spotter       Spotter
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{contentType: ContentType, preferFinal: true, chunkMerger: JoinChunks, clock: realClock{}, redirectPolicy: refuseRedirect, timeout: defaultTimeout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	cfg.buildTransport()
+	return cfg
+}
+
+// maxIdleConnsPerHost keeps a connection to each endpoint for every
+// language queried at once, and then some, where http.DefaultTransport
+// keeps two and redials the rest on every recognition over HTTP/1.1.
+const maxIdleConnsPerHost = 32
+
+// sharedTransport carries the requests of every Client and Backend not
+// given a transport or HTTP client of its own, so that all of them, across
+// recognitions, share the connections of pooledTransport: over HTTP/2 the
+// languages of a recognition are multiplexed on one connection per
+// endpoint. A program that replaced http.DefaultTransport, as tests faking
+// Google do, has its requests sent with that instead.
+var sharedTransport http.RoundTripper = defaultTransport{}
+
+// sharedClient is the default HTTP client of the Backends, using
+// sharedTransport.
+var sharedClient = &http.Client{Transport: sharedTransport}
+
+var (
+	stockTransport  = http.DefaultTransport
+	pooledTransport = newTransport()
+)
+
+type defaultTransport struct{}
+
+func (defaultTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if http.DefaultTransport != stockTransport {
+		return http.DefaultTransport.RoundTrip(r)
+	}
+	return pooledTransport.RoundTrip(r)
+}
+
+func newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ForceAttemptHTTP2 = true
+	t.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	return t
+}
+
+func (cfg *config) buildTransport() {
+	if !cfg.transportChanged {
+		return
+	}
+	cfg.transportChanged = false
+	t := newTransport()
+	if cfg.proxy != nil {
+		t.Proxy = http.ProxyURL(cfg.proxy)
+	}
+	if cfg.rootCAs != nil || cfg.insecureSkipVerify {
+		t.TLSClientConfig = &tls.Config{RootCAs: cfg.rootCAs, InsecureSkipVerify: cfg.insecureSkipVerify}
+	}
+	cfg.transport = t
+}
+
+// WithIncludeErrors makes ListenFileAll also return the languages that failed,
+// with their Err set.
+func WithIncludeErrors(include bool) Option {
+	return func(c *config) { c.includeErrors = include }
+}
+
+// WithRawCapture keeps the unparsed response body of every request on the
+// returned hypotheses, in Hypothesis.Raw.
+func WithRawCapture(capture bool) Option {
+	return func(c *config) { c.rawCapture = capture }
+}
+
+// WithContentType overrides the Content-Type sent with the audio, for
+// encodings other than 16 kHz linear PCM. See OggOpusContentType, or
+// WithAudioFormat to have it built and checked against the audio.
+func WithContentType(contentType string) Option {
+	return func(c *config) { c.contentType = contentType }
+}
+
+// WithSampleRate declares audio sampled at rate, updating the rate of the
+// Content-Type set so far and the durations derived from it. Passed to a
+// single call, it applies to that call only.
+func WithSampleRate(rate int) Option {
+	return func(c *config) { c.contentType = withRate(c.contentType, rate) }
+}
+
+// WithConfidenceThreshold stops waiting for the remaining languages as soon
+// as one of them is recognized with at least the given confidence, and
+// cancels their requests.
+func WithConfidenceThreshold(threshold float64) Option {
+	return func(c *config) { c.threshold = threshold }
+}
+
+// WithCoverage fills cov with how each queried language fared once the call
+// returns. A Client used concurrently may share cov; see Coverage.Snapshot.
+func WithCoverage(cov *Coverage) Option {
+	return func(c *config) { c.coverage = cov }
+}
+
+// WithClientParam sets the client query parameter of the request, e.g.
+// "chromium". It is left out by default.
+func WithClientParam(client string) Option {
+	return func(c *config) { c.clientParam = client }
+}
+
+// WithInOrder makes ListenFileFunc deliver hypotheses in SupportedLanguages
+// order instead of as they arrive.
+func WithInOrder(inOrder bool) Option {
+	return func(c *config) { c.inOrder = inOrder }
+}
+
+// Limiter paces outgoing requests. *rate.Limiter from golang.org/x/time/rate
+// satisfies it.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// WithLimiter makes every request wait on l before it is sent. Sharing one
+// Limiter between Clients caps their combined request rate.
+func WithLimiter(l Limiter) Option {
+	return func(c *config) { c.limiter = l }
+}
+
+// WithPreferFinal controls whether a final result wins over more confident
+// interim ones in the same response. It is on by default; turning it off
+// picks the most confident alternative of any result.
+func WithPreferFinal(prefer bool) Option {
+	return func(c *config) { c.preferFinal = prefer }
+}
+
+// WithMaxUploadBytes rejects audio larger than max with ErrAudioTooLarge
+// instead of sending it.
+func WithMaxUploadBytes(max int64) Option {
+	return func(c *config) { c.maxUpload = max }
+}
+
+// WithChunkMerger replaces JoinChunks as the way ListenChunked stitches
+// chunk transcripts together, e.g. to drop words repeated at boundaries.
+func WithChunkMerger(merge ChunkMerger) Option {
+	return func(c *config) { c.chunkMerger = merge }
+}
+
+// WithTrimSilence cuts leading and trailing silence from the audio before
+// sending it. It applies to byte slices, not to ListenReaderAt.
+func WithTrimSilence(trim bool) Option {
+	return func(c *config) { c.trimSilence = trim }
+}
+
+// WithLeadPadding keeps d of the audio before the first speech when trimming
+// silence. A little ambient sound ahead of speech helps recognition,
+// especially of short commands.
+func WithLeadPadding(d time.Duration) Option {
+	return func(c *config) { c.leadPadding = d }
+}
+
+// WithVAD trims the silence around the audio with v before sending it, in
+// place of the fixed threshold of WithTrimSilence. v measures the audio at
+// the Client's sample rate unless it sets its own. Like WithTrimSilence it
+// applies to byte slices, not to ListenReaderAt.
+func WithVAD(v VAD) Option {
+	return func(c *config) { c.vad = &v }
+}
+
+// WithInterim has a Stream recognize the segment being written every time
+// it grows by another interval of audio, delivering those hypotheses with
+// Interim set ahead of the segment's final one, for live captions. An
+// interim recognition is skipped while the previous one is still running;
+// each costs a request per language.
+func WithInterim(interval time.Duration) Option {
+	return func(c *config) { c.interim = interval }
+}
+
+// WithLanguages replaces SupportedLanguages as the languages queried, in the
+// given order.
+func WithLanguages(langs ...Language) Option {
+	return func(c *config) { c.languages = append([]Language{}, langs...) }
+}
+
+// Langs is WithLanguages, short for restricting a single call to the
+// languages it is likely in, as in ListenFile(audio, key, Langs(English,
+// German)), to save the quota and latency of racing the others.
+func Langs(langs ...Language) Option {
+	return WithLanguages(langs...)
+}
+
+// WithLogger sends diagnostics to l: at Warn, every response that decoded
+// to no results, with its language and body, since a change to Google's
+// response format looks like silence, and every error status; at Info, the
+// language and confidence of the hypothesis chosen; at Debug, each request
+// with its latency and status, the candidates the choice was made among and
+// phrase hints ignored by the v2 endpoint. The key is never logged.
+func WithLogger(l Logger) Option {
+	return func(c *config) { c.logger = l }
+}
+
+// WithMaxDuration bounds how much audio ListenLive reads from its source.
+// It defaults to 15 seconds.
+func WithMaxDuration(d time.Duration) Option {
+	return func(c *config) { c.maxDuration = d }
+}
+
+// WithEndpoint sends the requests to endpoint instead of GoogleEndpoint, such
+// as a proxy, a regional mirror or an httptest server. Like GoogleEndpoint,
+// the template takes the language code and the key, in that order.
+func WithEndpoint(endpoint string) Option {
+	return func(c *config) { c.endpoint = endpoint }
+}
+
+// WithLanguageEndpoint sends the requests for the given languages to their
+// own endpoint instead of GoogleEndpoint or that of WithEndpoint. Templates take the language code
+// and the key, in that order, like GoogleEndpoint.
+func WithLanguageEndpoint(endpoints map[Language]string) Option {
+	return func(c *config) {
+		c.endpoints = make(map[Language]string, len(endpoints))
+		for lang, e := range endpoints {
+			c.endpoints[lang] = e
+		}
+	}
+}
+
+// WithMinConfidence excludes results from selection when their confidence is
+// below min, so that a poor match in the wrong language is not returned for
+// lack of anything better. Floors set with WithLanguageMinConfidence take
+// precedence for their languages.
+func WithMinConfidence(min float64) Option {
+	return func(c *config) { c.minConfidenceAll = min }
+}
+
+// WithLanguageMinConfidence excludes results of a language from selection
+// when their confidence is below its floor. Confidence scales differently
+// from one language model to another, so each can have its own.
+func WithLanguageMinConfidence(floors map[Language]float64) Option {
+	return func(c *config) {
+		c.minConfidence = make(map[Language]float64, len(floors))
+		for lang, f := range floors {
+			c.minConfidence[lang] = f
+		}
+	}
+}
+
+// WithPreprocess runs every audio passed as a byte slice through p before
+// sending it, such as Pipeline{RemoveDC, NormalizeGain(-1), ReduceNoise}
+// to clean up quiet or noisy recordings.
+func WithPreprocess(p Pipeline) Option {
+	return func(c *config) { c.preprocess = append(Pipeline(nil), p...) }
+}
+
+// WithAlternativeSelector replaces SelectAlternative as the way an
+// alternative is picked from each response. WithPreferFinal has no effect
+// on a custom selector.
+func WithAlternativeSelector(sel AlternativeSelector) Option {
+	return func(c *config) { c.selectAlternative = sel }
+}
+
+// WithPhraseHints biases recognition towards the given phrases, such as
+// product names or commands, on backends that support it: CloudBackend
+// sends them as speech contexts and WhisperBackend as its prompt. Google's
+// v2 endpoint has no such parameter, so there the hints are ignored.
+func WithPhraseHints(phrases []string) Option {
+	return func(c *config) { c.phraseHints = append([]string(nil), phrases...) }
+}
+
+// WithPhrases is WithPhraseHints.
+func WithPhrases(phrases []string) Option {
+	return WithPhraseHints(phrases)
+}
+
+// Normalizer rewrites a transcript before it is returned, such as
+// Capitalize or Replacements.
+type Normalizer func(transcript string) string
+
+// WithNormalizer rewrites transcripts with each of ns in turn.
+func WithNormalizer(ns ...Normalizer) Option {
+	return func(c *config) { c.normalizer = chain(ns) }
+}
+
+// WithSequential queries the languages one at a time, in order, instead of
+// all at once, stopping at the first to reach WithConfidenceThreshold. It
+// trades latency for a lower and predictable request rate.
+func WithSequential(sequential bool) Option {
+	return func(c *config) { c.sequential = sequential }
+}
+
+// WithStrict makes ListenFile fail with a *SummaryError when any language
+// failed, other than by hearing no speech, rather than choosing among those
+// that succeeded.
+func WithStrict(strict bool) Option {
+	return func(c *config) { c.strict = strict }
+}
+
+// WithAnomalyHook calls hook with the body of every response that decoded
+// fine but had no usable alternative. Most are silence, but a run of them is
+// the first sign of Google changing its schema. The body is nil for Backends
+// other than Google.
+func WithAnomalyHook(hook func(lang Language, raw []byte)) Option {
+	return func(c *config) { c.anomalyHook = hook }
+}
+
+// WithScorer picks the best alternative, within a response and across
+// languages, by the score s gives it rather than by confidence. Thresholds
+// and minimum confidences still apply to the confidence.
+func WithScorer(s Scorer) Option {
+	return func(c *config) { c.scorer = s }
+}
+
+// WithRedirectPolicy decides whether to follow a redirect from Google, as
+// http.Client.CheckRedirect does. By default none are followed and the
+// request fails wrapping ErrRedirect, since following one would resend the
+// audio to wherever it points, if at all. A nil policy follows them as
+// net/http does.
+func WithRedirectPolicy(policy func(req *http.Request, via []*http.Request) error) Option {
+	return func(c *config) { c.redirectPolicy = policy }
+}
+
+func refuseRedirect(req *http.Request, via []*http.Request) error {
+	return fmt.Errorf("%w to %s", ErrRedirect, req.URL.Redacted())
+}
+
+// WithHTTPClient sends the requests with client instead of a default one,
+// for custom transports and proxies. Its own CheckRedirect applies instead of
+// WithRedirectPolicy.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *config) { c.httpClient = client }
+}
+
+// defaultTimeout bounds a call when WithTimeout is not given.
+const defaultTimeout = 30 * time.Second
+
+// WithTimeout bounds how long a call waits for the languages to answer, 30
+// seconds by default; zero waits as long as the context allows. When it runs
+// out the best hypothesis received so far is chosen, with Partial set, and
+// the requests still in flight are abandoned.
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) { c.timeout = d }
+}
+
+// WithRequestTimeout bounds every request on its own, so that one language
+// hanging fails with a timeout as soon as d has passed instead of holding
+// up the rest until the overall WithTimeout.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(c *config) { c.requestTimeout = d }
+}
+
+// WithInputSampleRate says linear PCM audio is sampled at rate, so it is
+// resampled to the rate declared to Google, 16 kHz unless WithSampleRate
+// says otherwise. WAV files need no such option: their rate is read from
+// the header, which is stripped.
+func WithInputSampleRate(rate int) Option {
+	return func(c *config) { c.inputRate = rate }
+}
+
+// WithInputChannels says linear PCM audio interleaves the given number of
+// channels, so it is downmixed to mono before it is sent. Multichannel WAV
+// files are downmixed without it. To recognize each channel on its own,
+// such as the agent and the customer of a call, use ListenChannels.
+func WithInputChannels(channels int) Option {
+	return func(c *config) { c.inputChannels = channels }
+}
+
+// WithKeepSampleRate declares audio at its own rate, when known from
+// WithInputSampleRate or a WAV header, instead of resampling it.
+func WithKeepSampleRate(keep bool) Option {
+	return func(c *config) { c.keepRate = keep }
+}
+
+// WithMaxAlternatives asks for up to n alternatives per result, reported in
+// Hypothesis.Alternatives.
+func WithMaxAlternatives(n int) Option {
+	return func(c *config) { c.maxAlternatives = n }
+}
+
+// WithMaxConcurrency has at most n languages queried at once, the others
+// waiting for one of them to finish. By default all are queried at once.
+// It bounds the chunks of ListenLong and the files of ListenDir as well.
+func WithMaxConcurrency(n int) Option {
+	return func(c *config) { c.maxConcurrency = n }
+}
+
+// WithProxy sends the requests through the HTTP proxy at proxy rather than
+// the one the environment names, if any. Like WithRootCAs and
+// WithInsecureSkipVerify, it is best given to NewClient: as a call option it
+// costs that call its own connections.
+func WithProxy(proxy *url.URL) Option {
+	return func(c *config) { c.proxy, c.transportChanged = proxy, true }
+}
+
+// WithRootCAs verifies Google's certificate against pool instead of the
+// system roots, for proxies that intercept TLS with a corporate CA.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(c *config) { c.rootCAs, c.transportChanged = pool, true }
+}
+
+// WithInsecureSkipVerify accepts any certificate. It is meant for test labs
+// and leaves the key and the audio open to interception.
+func WithInsecureSkipVerify(skip bool) Option {
+	return func(c *config) { c.insecureSkipVerify, c.transportChanged = skip, true }
+}
+
+// WithFilePattern has ListenDir recognize the files whose name matches
+// pattern, in the syntax of filepath.Match, rather than those with a known
+// audio extension.
+func WithFilePattern(pattern string) Option {
+	return func(c *config) { c.filePattern = pattern }
+}
+
+// WithResume has ListenDir keep the hypotheses without Err of a previous
+// call rather than recognize their files again.
+func WithResume(previous map[string]Hypothesis) Option {
+	return func(c *config) { c.resume = previous }
+}
@@ -0,0 +1,71 @@
+package gorec
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestCallerMutationsDontLeak(t *testing.T) {
+	SupportedLanguages()[0] = Italian
+	if SupportedLanguages()[0] != English {
+		t.Fatal("mutating SupportedLanguages changed the package's languages")
+	}
+
+	langs := []Language{German, French}
+	hints := []string{"hola"}
+	endpoints := map[Language]string{German: "http://de/%s/%s"}
+	floors := map[Language]float64{German: 0.5}
+	c := NewClient("k", WithLanguages(langs...), WithPhraseHints(hints),
+		WithLanguageEndpoint(endpoints), WithLanguageMinConfidence(floors))
+	defer c.Close()
+	langs[0], hints[0] = Greek, "adiós"
+	endpoints[French] = "http://fr/%s/%s"
+	floors[German] = 0.9
+	c.Languages()[1] = Spanish
+
+	if got := c.Languages(); !reflect.DeepEqual(got, []Language{German, French}) {
+		t.Errorf("Languages() = %v", got)
+	}
+	if !reflect.DeepEqual(c.cfg.phraseHints, []string{"hola"}) {
+		t.Errorf("phrase hints = %v", c.cfg.phraseHints)
+	}
+	if len(c.cfg.endpoints) != 1 || c.cfg.minConfidence[German] != 0.5 {
+		t.Errorf("endpoints = %v, floors = %v", c.cfg.endpoints, c.cfg.minConfidence)
+	}
+}
+
+func TestLangsPerCall(t *testing.T) {
+	var mu sync.Mutex
+	var queried []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queried = append(queried, r.URL.Query().Get("lang"))
+		mu.Unlock()
+		fmt.Fprint(w, `{"result":[{"alternative":[{"transcript":"hallo","confidence":0.9}],"final":true}],"result_index":0}`)
+	}))
+	defer srv.Close()
+	eps := map[Language]string{}
+	for _, l := range SupportedLanguages() {
+		eps[l] = srv.URL + "/?lang=%s&key=%s"
+	}
+	c := NewClient("k", WithLanguageEndpoint(eps))
+	if _, err := c.ListenFile([]byte{1, 2}, Langs(English, German)); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(queried)
+	if want := []string{"de-de", "en-gb"}; !reflect.DeepEqual(queried, want) {
+		t.Errorf("queried %v, want %v", queried, want)
+	}
+	queried = nil
+	if _, err := c.ListenFile([]byte{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if len(queried) != len(SupportedLanguages()) {
+		t.Errorf("a later call queried %v", queried)
+	}
+}
@@ -0,0 +1,33 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Ping checks that Google is reachable and accepts the key by sending a
+// tenth of a second of silence in the first language queried. Silence being
+// recognized as no speech counts as success. Failures are an *APIError for
+// rejections, wrapping ErrUnauthorized or ErrQuotaExceeded when the cause is
+// known, or wrap ErrNetwork when Google couldn't be reached.
+func (c *Client) Ping(ctx context.Context, opts ...Option) error {
+	c = c.with(opts)
+	languages := c.languages()
+	if len(languages) == 0 {
+		return ErrNoLanguages
+	}
+	silence := make([]byte, bytesFor(100*time.Millisecond, c.sampleRate()))
+	_, err := c.recognize(ctx, bytes.NewReader(silence), int64(len(silence)), languages[0])
+	if err == nil || errors.Is(err, ErrNoSpeech) {
+		return nil
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && ctx.Err() == nil && !errors.Is(err, ErrRedirect) {
+		return fmt.Errorf("%w: %w", ErrNetwork, err)
+	}
+	return err
+}
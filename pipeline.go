@@ -0,0 +1,40 @@
+package gorec
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// Stage is a step of audio processing, such as RemoveDC or FFmpeg.Convert.
+type Stage func(audio []byte) ([]byte, error)
+
+// Pipeline is a sequence of audio processing stages, each fed the output of
+// the previous one.
+type Pipeline []Stage
+
+// Preprocess passes pcm through stages in order, as Pipeline.Run does.
+func Preprocess(pcm []byte, stages ...Stage) ([]byte, error) {
+	return Pipeline(stages).Run(pcm)
+}
+
+// Run passes audio through every stage in order. The first stage to fail
+// stops the pipeline, and its error is wrapped with the stage's position and
+// function name.
+func (p Pipeline) Run(audio []byte) ([]byte, error) {
+	for i, stage := range p {
+		out, err := stage(audio)
+		if err != nil {
+			return nil, fmt.Errorf("Preprocessing stage %d (%s): %w", i, stageName(stage), err)
+		}
+		audio = out
+	}
+	return audio, nil
+}
+
+func stageName(stage Stage) string {
+	if f := runtime.FuncForPC(reflect.ValueOf(stage).Pointer()); f != nil {
+		return f.Name()
+	}
+	return "unknown"
+}
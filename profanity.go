@@ -0,0 +1,68 @@
+package gorec
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ProfanityLevel says what to do about profane words in transcripts.
+type ProfanityLevel int
+
+const (
+	// ProfanityDefault leaves the behaviour to the backend.
+	ProfanityDefault ProfanityLevel = iota
+	// ProfanityOff asks for transcripts as heard.
+	ProfanityOff
+	// ProfanityMask asks for profane words masked but for their first
+	// letter, as in "f***".
+	ProfanityMask
+	// ProfanityDrop masks profane words like ProfanityMask, then leaves
+	// them out of the transcript and its Words.
+	ProfanityDrop
+)
+
+// WithProfanityFilter filters profane words as level says. On Google's v2
+// endpoint it sets the pFilter parameter; CloudBackend masks them with its
+// profanityFilter setting. Backends without a filter, such as
+// WhisperBackend, ignore it, though ProfanityDrop still drops what they
+// mask.
+func WithProfanityFilter(level ProfanityLevel) Option {
+	return func(c *config) { c.profanity = level }
+}
+
+// pFilter is the pFilter parameter of Google's v2 endpoint for l, empty to
+// leave it out.
+func (l ProfanityLevel) pFilter() string {
+	switch l {
+	case ProfanityOff:
+		return "0"
+	case ProfanityMask, ProfanityDrop:
+		return "2"
+	}
+	return ""
+}
+
+// masked reports whether word is a profanity masked by the backend.
+func masked(word string) bool {
+	_, size := utf8.DecodeRuneInString(word)
+	rest := strings.TrimRight(word[size:], ".,!?;:")
+	return size < len(word) && rest != "" && strings.Trim(rest, "*") == ""
+}
+
+// dropMasked removes the masked profanities from alt.
+func dropMasked(alt *Alternative) {
+	var kept []string
+	for _, w := range strings.Fields(alt.Transcript) {
+		if !masked(w) {
+			kept = append(kept, w)
+		}
+	}
+	alt.Transcript = strings.Join(kept, " ")
+	var words []Word
+	for _, w := range alt.Words {
+		if !masked(w.Word) {
+			words = append(words, w)
+		}
+	}
+	alt.Words = words
+}
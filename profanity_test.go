@@ -0,0 +1,43 @@
+package gorec
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProfanityFilter(t *testing.T) {
+	var pFilter string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pFilter = r.URL.Query().Get("pFilter")
+		fmt.Fprint(w, `{"result":[{"alternative":[{"transcript":"what the f*** is this s***!","confidence":0.9}],"final":true}]}`)
+	}))
+	defer srv.Close()
+	for _, c := range []struct {
+		level      ProfanityLevel
+		pFilter    string
+		transcript string
+	}{
+		{ProfanityDefault, "", "what the f*** is this s***!"},
+		{ProfanityOff, "0", "what the f*** is this s***!"},
+		{ProfanityMask, "2", "what the f*** is this s***!"},
+		{ProfanityDrop, "2", "what the is this"},
+	} {
+		h, err := Recognize([]byte{1, 2}, "k", English, WithEndpoint(srv.URL+"/?lang=%s&key=%s"), WithProfanityFilter(c.level))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pFilter != c.pFilter || h.Alternative.Transcript != c.transcript {
+			t.Errorf("level %d sent pFilter %q and got %q, want %q and %q", c.level, pFilter, h.Alternative.Transcript, c.pFilter, c.transcript)
+		}
+	}
+}
+
+func TestMasked(t *testing.T) {
+	for word, want := range map[string]bool{"f***": true, "s***,": true, "ñ**": true, "*": false, "f": false, "a*b": false, "2*3": false} {
+		if got := masked(word); got != want {
+			t.Errorf("masked(%q) = %v, want %v", word, got, want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package gorecv1
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carlescere/gorec"
+)
+
+// DefaultMaxRequestSize bounds the request messages of a call when
+// Server.MaxRequestSize is zero.
+const DefaultMaxRequestSize = 10 << 20
+
+// The gRPC status codes the Server answers with.
+const (
+	CodeOK                = 0
+	CodeCanceled          = 1
+	CodeInvalidArgument   = 3
+	CodeDeadlineExceeded  = 4
+	CodeNotFound          = 5
+	CodeResourceExhausted = 8
+	CodeUnimplemented     = 12
+	CodeInternal          = 13
+	CodeUnavailable       = 14
+	CodeUnauthenticated   = 16
+)
+
+// The paths of the methods of the Transcription service.
+const (
+	RecognizePath          = "/gorec.v1.Transcription/Recognize"
+	StreamingRecognizePath = "/gorec.v1.Transcription/StreamingRecognize"
+)
+
+// Server is an http.Handler serving the Transcription service with
+// Recognizer, as gRPC does over HTTP/2. Served over TLS, net/http speaks
+// HTTP/2 by itself; clients calling it in cleartext, as most do within a
+// cluster, need an http.Server whose Protocols allow unencrypted HTTP/2, as
+// from Go 1.24.
+type Server struct {
+	Recognizer *gorec.Client
+
+	// Token, when set, is the bearer token every call must carry in its
+	// "authorization" metadata.
+	Token string
+
+	// MaxRequestSize bounds the total size of the request messages of a
+	// call, DefaultMaxRequestSize if zero.
+	MaxRequestSize int64
+}
+
+// Status is the error a call fails with: its gRPC status code and message.
+type Status struct {
+	Code    int
+	Message string
+}
+
+func (s *Status) Error() string {
+	return fmt.Sprintf("gRPC status %d: %s", s.Code, s.Message)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor != 2 {
+		http.Error(w, "gRPC needs HTTP/2", http.StatusHTTPVersionNotSupported)
+		return
+	}
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "Not a gRPC call", http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	resp, err := s.call(r)
+	if err != nil {
+		writeStatus(w, status(err))
+		return
+	}
+	msg := resp.Marshal()
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(append(frame, msg...))
+	writeStatus(w, &Status{Code: CodeOK})
+}
+
+// call runs the method r calls.
+func (s *Server) call(r *http.Request) (*RecognizeResponse, error) {
+	var stream bool
+	switch r.URL.Path {
+	case RecognizePath:
+	case StreamingRecognizePath:
+		stream = true
+	default:
+		return nil, &Status{CodeUnimplemented, fmt.Sprintf("Unknown method %s", r.URL.Path)}
+	}
+	if s.Token != "" {
+		got := r.Header.Get("Authorization")
+		if subtle.ConstantTimeCompare([]byte(got), []byte("Bearer "+s.Token)) != 1 {
+			return nil, &Status{CodeUnauthenticated, "Missing or wrong bearer token"}
+		}
+	}
+	if e := r.Header.Get("Grpc-Encoding"); e != "" && e != "identity" {
+		return nil, &Status{CodeUnimplemented, fmt.Sprintf("Unsupported grpc-encoding %q", e)}
+	}
+	ctx := r.Context()
+	if t := r.Header.Get("Grpc-Timeout"); t != "" {
+		d, err := parseTimeout(t)
+		if err != nil {
+			return nil, &Status{CodeInvalidArgument, err.Error()}
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	max := s.MaxRequestSize
+	if max <= 0 {
+		max = DefaultMaxRequestSize
+	}
+	var req RecognizeRequest
+	for n := 0; ; n++ {
+		msg, err := readMessage(r.Body, &max)
+		if err == io.EOF {
+			if n == 0 {
+				return nil, &Status{CodeInternal, "No request message"}
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var m RecognizeRequest
+		if err := m.Unmarshal(msg); err != nil {
+			return nil, &Status{CodeInternal, err.Error()}
+		}
+		if n == 0 {
+			req.Config = m.Config
+		}
+		req.Audio = append(req.Audio, m.Audio...)
+		if !stream {
+			break
+		}
+	}
+	return s.recognize(ctx, &req)
+}
+
+// readMessage reads a length-prefixed message, taking its size off the
+// bytes left for the call's requests.
+func readMessage(r io.Reader, left *int64) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, &Status{CodeInternal, fmt.Sprintf("Reading request: %v", err)}
+	}
+	if header[0] != 0 {
+		return nil, &Status{CodeUnimplemented, "Compressed messages are not supported"}
+	}
+	n := int64(binary.BigEndian.Uint32(header[1:]))
+	if n > *left {
+		return nil, &Status{CodeResourceExhausted, "Request messages too large"}
+	}
+	*left -= n
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, &Status{CodeInternal, fmt.Sprintf("Reading request: %v", err)}
+	}
+	return msg, nil
+}
+
+func (s *Server) recognize(ctx context.Context, req *RecognizeRequest) (*RecognizeResponse, error) {
+	var opts []gorec.Option
+	if cfg := req.Config; cfg != nil {
+		if len(cfg.Languages) > 0 {
+			langs := make([]gorec.Language, len(cfg.Languages))
+			for i, code := range cfg.Languages {
+				l, err := gorec.LanguageFromCode(code)
+				if err != nil {
+					return nil, err
+				}
+				langs[i] = l
+			}
+			opts = append(opts, gorec.WithLanguages(langs...))
+		}
+		switch {
+		case cfg.SampleRateHertz < 0:
+			return nil, &Status{CodeInvalidArgument, fmt.Sprintf("Negative sample rate %d", cfg.SampleRateHertz)}
+		case cfg.SampleRateHertz > 0:
+			opts = append(opts, gorec.WithSampleRate(int(cfg.SampleRateHertz)))
+		}
+	}
+	h, err := s.Recognizer.ListenFileContext(ctx, req.Audio, opts...)
+	if err != nil {
+		return nil, err
+	}
+	resp := &RecognizeResponse{
+		Language:    h.Language.Code(),
+		Alternative: alternative(h.Alternative),
+		Partial:     h.Partial,
+	}
+	for _, a := range h.Alternatives {
+		resp.Alternatives = append(resp.Alternatives, alternative(a))
+	}
+	return resp, nil
+}
+
+func alternative(a gorec.Alternative) *Alternative {
+	alt := &Alternative{Transcript: a.Transcript, Confidence: a.Confidence}
+	for _, w := range a.Words {
+		alt.Words = append(alt.Words, &Word{
+			Word:    w.Word,
+			StartMs: w.Start.Milliseconds(),
+			EndMs:   w.End.Milliseconds(),
+			Speaker: int32(w.Speaker),
+		})
+	}
+	return alt
+}
+
+// status is the Status reporting err.
+func status(err error) *Status {
+	var s *Status
+	switch {
+	case errors.As(err, &s):
+		return s
+	case errors.Is(err, context.Canceled):
+		return &Status{CodeCanceled, err.Error()}
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, gorec.ErrTimeout):
+		return &Status{CodeDeadlineExceeded, err.Error()}
+	case errors.Is(err, gorec.ErrEmptyAudio), errors.Is(err, gorec.ErrUnsupportedFormat), errors.Is(err, gorec.ErrNotWAV),
+		errors.Is(err, gorec.ErrNotFLAC), errors.Is(err, gorec.ErrNotOggOpus), errors.Is(err, gorec.ErrFormatMismatch),
+		errors.Is(err, gorec.ErrUnknownLanguage), errors.Is(err, gorec.ErrNoLanguages), errors.Is(err, gorec.ErrAudioTooLarge):
+		return &Status{CodeInvalidArgument, err.Error()}
+	case errors.Is(err, gorec.ErrNoSpeech):
+		return &Status{CodeNotFound, err.Error()}
+	case errors.Is(err, gorec.ErrQuotaExceeded):
+		return &Status{CodeResourceExhausted, err.Error()}
+	}
+	return &Status{CodeUnavailable, err.Error()}
+}
+
+// writeStatus ends the response with s in its trailers.
+func writeStatus(w http.ResponseWriter, s *Status) {
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(s.Code))
+	if s.Message != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", encodeMessage(s.Message))
+	}
+}
+
+// encodeMessage percent-encodes msg as the grpc-message trailer carries it.
+func encodeMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		if c := msg[i]; c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// parseTimeout parses a grpc-timeout header, such as "100m" for 100
+// milliseconds.
+func parseTimeout(t string) (time.Duration, error) {
+	units := map[byte]time.Duration{
+		'H': time.Hour, 'M': time.Minute, 'S': time.Second,
+		'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond,
+	}
+	unit, ok := units[t[len(t)-1]]
+	n, err := strconv.ParseInt(t[:len(t)-1], 10, 64)
+	if !ok || err != nil || n < 0 || len(t) > 9 {
+		return 0, fmt.Errorf("Invalid grpc-timeout %q", t)
+	}
+	if n > math.MaxInt64/int64(unit) {
+		return math.MaxInt64, nil
+	}
+	return time.Duration(n) * unit, nil
+}
@@ -0,0 +1,165 @@
+package gorecv1
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/carlescere/gorec"
+)
+
+// frenchBackend hears "bonjour" in French, with as many words as the audio
+// has kilobytes, and nothing in other languages.
+type frenchBackend struct{}
+
+func (frenchBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang gorec.Language, p gorec.BackendParams) (*gorec.GoogleResponse, error) {
+	if lang != gorec.French {
+		return &gorec.GoogleResponse{}, nil
+	}
+	pcm, err := io.ReadAll(audio)
+	if err != nil {
+		return nil, err
+	}
+	var words []gorec.Word
+	for i := 0; i < len(pcm)/1000; i++ {
+		words = append(words, gorec.Word{Word: "bonjour", Start: time.Duration(i) * time.Second, End: time.Duration(i)*time.Second + 500*time.Millisecond})
+	}
+	alt := gorec.Alternative{Transcript: "bonjour", Confidence: 0.9, Words: words}
+	return &gorec.GoogleResponse{Results: []gorec.Result{{Alternatives: []gorec.Alternative{alt}, Final: true}}}, nil
+}
+
+// stallBackend hears nothing until its context is done.
+type stallBackend struct{}
+
+func (stallBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang gorec.Language, p gorec.BackendParams) (*gorec.GoogleResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// serve serves s over HTTP/2 on a local listener, returning its URL and a
+// client speaking to it as gRPC clients do.
+func serve(t *testing.T, s *Server) (string, *http.Client) {
+	srv := httptest.NewUnstartedServer(s)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv.URL, srv.Client()
+}
+
+// call calls path with reqs, returning the response and the status the
+// call ended with.
+func call(t *testing.T, url string, client *http.Client, path string, header http.Header, reqs ...*RecognizeRequest) (*RecognizeResponse, int, string) {
+	var body bytes.Buffer
+	for _, r := range reqs {
+		msg := r.Marshal()
+		body.WriteByte(0)
+		binary.Write(&body, binary.BigEndian, uint32(len(msg)))
+		body.Write(msg)
+	}
+	req, _ := http.NewRequest(http.MethodPost, url+path, &body)
+	req.Header.Set("Content-Type", "application/grpc")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := strconv.Atoi(resp.Trailer.Get("Grpc-Status"))
+	if err != nil {
+		t.Fatalf("no grpc-status in trailers %v", resp.Trailer)
+	}
+	if len(b) == 0 {
+		return nil, code, resp.Trailer.Get("Grpc-Message")
+	}
+	if len(b) < 5 || int(binary.BigEndian.Uint32(b[1:5])) != len(b)-5 {
+		t.Fatalf("malformed response message %q", b)
+	}
+	var r RecognizeResponse
+	if err := r.Unmarshal(b[5:]); err != nil {
+		t.Fatal(err)
+	}
+	return &r, code, resp.Trailer.Get("Grpc-Message")
+}
+
+func TestRecognize(t *testing.T) {
+	c := gorec.NewClient("k", gorec.WithBackend(frenchBackend{}))
+	defer c.Close()
+	url, client := serve(t, &Server{Recognizer: c})
+
+	req := &RecognizeRequest{Config: &RecognizeConfig{Languages: []string{"fr-FR"}}, Audio: make([]byte, 2000)}
+	resp, code, msg := call(t, url, client, RecognizePath, nil, req)
+	if code != CodeOK || resp == nil {
+		t.Fatalf("Recognize ended with %d %q", code, msg)
+	}
+	if resp.Language != "fr-FR" || resp.Alternative.Transcript != "bonjour" || len(resp.Alternative.Words) != 2 || resp.Alternative.Words[1].StartMs != 1000 {
+		t.Errorf("Recognize answered %+v, alternative %+v", resp, resp.Alternative)
+	}
+}
+
+func TestStreamingRecognize(t *testing.T) {
+	c := gorec.NewClient("k", gorec.WithBackend(frenchBackend{}))
+	defer c.Close()
+	url, client := serve(t, &Server{Recognizer: c})
+
+	resp, code, msg := call(t, url, client, StreamingRecognizePath, nil,
+		&RecognizeRequest{Config: &RecognizeConfig{Languages: []string{"fr"}}, Audio: make([]byte, 1000)},
+		&RecognizeRequest{Audio: make([]byte, 1000)},
+		&RecognizeRequest{Config: &RecognizeConfig{Languages: []string{"en"}}, Audio: make([]byte, 1000)})
+	if code != CodeOK || resp == nil {
+		t.Fatalf("StreamingRecognize ended with %d %q", code, msg)
+	}
+	if resp.Alternative.Transcript != "bonjour" || len(resp.Alternative.Words) != 3 {
+		t.Errorf("StreamingRecognize of the three messages' audio answered %+v", resp.Alternative)
+	}
+}
+
+func TestRecognizeStatus(t *testing.T) {
+	c := gorec.NewClient("k", gorec.WithBackend(frenchBackend{}))
+	defer c.Close()
+	url, client := serve(t, &Server{Recognizer: c, MaxRequestSize: 1 << 10})
+	stalling := gorec.NewClient("k", gorec.WithBackend(stallBackend{}), gorec.WithLanguages(gorec.English), gorec.WithTimeout(time.Minute))
+	defer stalling.Close()
+	stallURL, stallClient := serve(t, &Server{Recognizer: stalling, Token: "secret"})
+
+	audio := make([]byte, 100)
+	auth := http.Header{"Authorization": {"Bearer secret"}}
+	for _, tc := range []struct {
+		name   string
+		url    string
+		client *http.Client
+		path   string
+		header http.Header
+		req    *RecognizeRequest
+		code   int
+	}{
+		{"no speech", url, client, RecognizePath, nil, &RecognizeRequest{Config: &RecognizeConfig{Languages: []string{"en-US"}}, Audio: audio}, CodeNotFound},
+		{"unknown language", url, client, RecognizePath, nil, &RecognizeRequest{Config: &RecognizeConfig{Languages: []string{"xx-XX"}}, Audio: audio}, CodeInvalidArgument},
+		{"empty audio", url, client, RecognizePath, nil, &RecognizeRequest{}, CodeInvalidArgument},
+		{"too large", url, client, RecognizePath, nil, &RecognizeRequest{Audio: make([]byte, 2<<10)}, CodeResourceExhausted},
+		{"unknown method", url, client, "/gorec.v1.Transcription/Translate", nil, &RecognizeRequest{Audio: audio}, CodeUnimplemented},
+		{"no token", stallURL, stallClient, RecognizePath, nil, &RecognizeRequest{Audio: audio}, CodeUnauthenticated},
+		{"timeout", stallURL, stallClient, RecognizePath, http.Header{"Authorization": auth["Authorization"], "Grpc-Timeout": {"50m"}}, &RecognizeRequest{Audio: audio}, CodeDeadlineExceeded},
+	} {
+		if _, code, msg := call(t, tc.url, tc.client, tc.path, tc.header, tc.req); code != tc.code {
+			t.Errorf("%s: ended with %d %q, want %d", tc.name, code, msg, tc.code)
+		}
+	}
+}
+
+func TestStatusMessageEncoded(t *testing.T) {
+	if got, want := encodeMessage("100% sûr\n"), "100%25 s%C3%BBr%0A"; got != want {
+		t.Errorf("encoded %q, want %q", got, want)
+	}
+}
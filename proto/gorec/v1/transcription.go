@@ -0,0 +1,229 @@
+// Package gorecv1 is the Transcription gRPC service of transcription.proto:
+// its messages, encoded in the protobuf wire format, and Server, serving it
+// with a gorec.Client. It is written by hand rather than generated so that
+// gorec keeps depending on the standard library only; its messages encode
+// as those protoc generates do, so any gRPC client can call it.
+package gorecv1
+
+import "math"
+
+// RecognizeConfig is what to recognize the audio of a request as.
+type RecognizeConfig struct {
+	// Languages are the language codes to try, such as "en-US" or "es".
+	// Empty tries the client's default languages.
+	Languages []string
+
+	// SampleRateHertz is the sample rate of raw PCM audio, 16000 if zero.
+	SampleRateHertz int32
+}
+
+// RecognizeRequest is the request of Recognize, and each message of
+// StreamingRecognize.
+type RecognizeRequest struct {
+	Config *RecognizeConfig
+	Audio  []byte
+}
+
+// Word is a word of an Alternative, timed in milliseconds from the start of
+// the audio.
+type Word struct {
+	Word    string
+	StartMs int64
+	EndMs   int64
+	Speaker int32
+}
+
+// Alternative is a transcript of the audio.
+type Alternative struct {
+	Transcript string
+	Confidence float64
+	Words      []*Word
+}
+
+// RecognizeResponse is the winning hypothesis.
+type RecognizeResponse struct {
+	// Language is the language code of the hypothesis.
+	Language     string
+	Alternative  *Alternative
+	Alternatives []*Alternative
+
+	// Partial is set when the hypothesis was chosen before every language
+	// answered.
+	Partial bool
+}
+
+// Marshal encodes m in the protobuf wire format.
+func (m *RecognizeConfig) Marshal() []byte {
+	var b []byte
+	for _, l := range m.Languages {
+		b = appendLen(b, 1, []byte(l))
+	}
+	return appendVarint(b, 2, uint64(m.SampleRateHertz))
+}
+
+// Unmarshal decodes m from the protobuf wire format, skipping unknown
+// fields.
+func (m *RecognizeConfig) Unmarshal(b []byte) error {
+	*m = RecognizeConfig{}
+	return decode(b, func(d *decoder, field, wire int) error {
+		switch {
+		case field == 1 && wire == wireBytes:
+			p, err := d.bytes()
+			m.Languages = append(m.Languages, string(p))
+			return err
+		case field == 2 && wire == wireVarint:
+			v, err := d.varint()
+			m.SampleRateHertz = int32(v)
+			return err
+		}
+		return d.skip(wire)
+	})
+}
+
+// Marshal encodes m in the protobuf wire format.
+func (m *RecognizeRequest) Marshal() []byte {
+	var b []byte
+	if m.Config != nil {
+		b = appendLen(b, 1, m.Config.Marshal())
+	}
+	if len(m.Audio) > 0 {
+		b = appendLen(b, 2, m.Audio)
+	}
+	return b
+}
+
+// Unmarshal decodes m from the protobuf wire format, skipping unknown
+// fields.
+func (m *RecognizeRequest) Unmarshal(b []byte) error {
+	*m = RecognizeRequest{}
+	return decode(b, func(d *decoder, field, wire int) error {
+		switch {
+		case field == 1 && wire == wireBytes:
+			p, err := d.bytes()
+			if err != nil {
+				return err
+			}
+			m.Config = new(RecognizeConfig)
+			return m.Config.Unmarshal(p)
+		case field == 2 && wire == wireBytes:
+			p, err := d.bytes()
+			m.Audio = p
+			return err
+		}
+		return d.skip(wire)
+	})
+}
+
+// Marshal encodes m in the protobuf wire format.
+func (m *Word) Marshal() []byte {
+	b := appendString(nil, 1, m.Word)
+	b = appendVarint(b, 2, uint64(m.StartMs))
+	b = appendVarint(b, 3, uint64(m.EndMs))
+	return appendVarint(b, 4, uint64(m.Speaker))
+}
+
+// Unmarshal decodes m from the protobuf wire format, skipping unknown
+// fields.
+func (m *Word) Unmarshal(b []byte) error {
+	*m = Word{}
+	return decode(b, func(d *decoder, field, wire int) error {
+		if field == 1 && wire == wireBytes {
+			p, err := d.bytes()
+			m.Word = string(p)
+			return err
+		}
+		if field < 2 || field > 4 || wire != wireVarint {
+			return d.skip(wire)
+		}
+		v, err := d.varint()
+		switch field {
+		case 2:
+			m.StartMs = int64(v)
+		case 3:
+			m.EndMs = int64(v)
+		case 4:
+			m.Speaker = int32(v)
+		}
+		return err
+	})
+}
+
+// Marshal encodes m in the protobuf wire format.
+func (m *Alternative) Marshal() []byte {
+	b := appendString(nil, 1, m.Transcript)
+	b = appendDouble(b, 2, m.Confidence)
+	for _, w := range m.Words {
+		b = appendLen(b, 3, w.Marshal())
+	}
+	return b
+}
+
+// Unmarshal decodes m from the protobuf wire format, skipping unknown
+// fields.
+func (m *Alternative) Unmarshal(b []byte) error {
+	*m = Alternative{}
+	return decode(b, func(d *decoder, field, wire int) error {
+		switch {
+		case field == 1 && wire == wireBytes:
+			p, err := d.bytes()
+			m.Transcript = string(p)
+			return err
+		case field == 2 && wire == wireFixed64:
+			v, err := d.fixed64()
+			m.Confidence = math.Float64frombits(v)
+			return err
+		case field == 3 && wire == wireBytes:
+			p, err := d.bytes()
+			if err != nil {
+				return err
+			}
+			w := new(Word)
+			m.Words = append(m.Words, w)
+			return w.Unmarshal(p)
+		}
+		return d.skip(wire)
+	})
+}
+
+// Marshal encodes m in the protobuf wire format.
+func (m *RecognizeResponse) Marshal() []byte {
+	b := appendString(nil, 1, m.Language)
+	if m.Alternative != nil {
+		b = appendLen(b, 2, m.Alternative.Marshal())
+	}
+	for _, a := range m.Alternatives {
+		b = appendLen(b, 3, a.Marshal())
+	}
+	return appendBool(b, 4, m.Partial)
+}
+
+// Unmarshal decodes m from the protobuf wire format, skipping unknown
+// fields.
+func (m *RecognizeResponse) Unmarshal(b []byte) error {
+	*m = RecognizeResponse{}
+	return decode(b, func(d *decoder, field, wire int) error {
+		switch {
+		case field == 1 && wire == wireBytes:
+			p, err := d.bytes()
+			m.Language = string(p)
+			return err
+		case (field == 2 || field == 3) && wire == wireBytes:
+			p, err := d.bytes()
+			if err != nil {
+				return err
+			}
+			a := new(Alternative)
+			if field == 2 {
+				m.Alternative = a
+			} else {
+				m.Alternatives = append(m.Alternatives, a)
+			}
+			return a.Unmarshal(p)
+		case field == 4 && wire == wireVarint:
+			v, err := d.varint()
+			m.Partial = v != 0
+			return err
+		}
+		return d.skip(wire)
+	})
+}
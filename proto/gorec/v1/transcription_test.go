@@ -0,0 +1,56 @@
+package gorecv1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMarshalAsProtoc(t *testing.T) {
+	cfg := &RecognizeConfig{Languages: []string{"en"}, SampleRateHertz: 16000}
+	if got, want := string(cfg.Marshal()), "\x0a\x02en\x10\x80\x7d"; got != want {
+		t.Errorf("RecognizeConfig encoded %q, want %q", got, want)
+	}
+	alt := &Alternative{Confidence: 0.5}
+	if got, want := string(alt.Marshal()), "\x11\x00\x00\x00\x00\x00\x00\xe0\x3f"; got != want {
+		t.Errorf("Alternative encoded %q, want %q", got, want)
+	}
+	if b := (&RecognizeResponse{}).Marshal(); len(b) != 0 {
+		t.Errorf("empty RecognizeResponse encoded %q", b)
+	}
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	req := &RecognizeRequest{
+		Config: &RecognizeConfig{Languages: []string{"en-US", "fr-FR"}, SampleRateHertz: 8000},
+		Audio:  []byte{1, 2, 3},
+	}
+	var gotReq RecognizeRequest
+	if err := gotReq.Unmarshal(req.Marshal()); err != nil || !reflect.DeepEqual(&gotReq, req) {
+		t.Errorf("RecognizeRequest decoded %+v, %v", gotReq, err)
+	}
+
+	resp := &RecognizeResponse{
+		Language: "fr-FR",
+		Alternative: &Alternative{Transcript: "bonjour", Confidence: 0.9, Words: []*Word{
+			{Word: "bonjour", StartMs: 100, EndMs: 600, Speaker: 2},
+		}},
+		Alternatives: []*Alternative{{Transcript: "bonjour"}, {Transcript: "bon jour", Confidence: 0.1}},
+		Partial:      true,
+	}
+	var gotResp RecognizeResponse
+	if err := gotResp.Unmarshal(resp.Marshal()); err != nil || !reflect.DeepEqual(&gotResp, resp) {
+		t.Errorf("RecognizeResponse decoded %+v, %v", gotResp, err)
+	}
+}
+
+func TestUnmarshalSkipsUnknownFields(t *testing.T) {
+	// Field 9 as a varint, a fixed32, a fixed64 and bytes, then language.
+	b := []byte("\x48\x96\x01\x4d\x01\x02\x03\x04\x49\x01\x02\x03\x04\x05\x06\x07\x08\x4a\x02hi\x0a\x02fr")
+	var resp RecognizeResponse
+	if err := resp.Unmarshal(b); err != nil || resp.Language != "fr" {
+		t.Errorf("decoded %+v, %v", resp, err)
+	}
+	if err := resp.Unmarshal([]byte("\x0a\x05fr")); err == nil {
+		t.Error("truncated message decoded")
+	}
+}
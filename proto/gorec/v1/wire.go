@@ -0,0 +1,143 @@
+package gorecv1
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Wire types of the protobuf encoding.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errMalformed = errors.New("Malformed protobuf message")
+
+func appendTag(b []byte, field, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wire))
+}
+
+// appendVarint appends v as field, unless it is zero as proto3 leaves out.
+func appendVarint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.AppendUvarint(appendTag(b, field, wireVarint), v)
+}
+
+func appendBool(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+	return appendVarint(b, field, 1)
+}
+
+func appendDouble(b []byte, field int, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.LittleEndian.AppendUint64(appendTag(b, field, wireFixed64), math.Float64bits(v))
+}
+
+func appendString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	return appendLen(b, field, []byte(s))
+}
+
+// appendLen appends p as the length-delimited field, even if it is empty, as
+// messages are.
+func appendLen(b []byte, field int, p []byte) []byte {
+	b = binary.AppendUvarint(appendTag(b, field, wireBytes), uint64(len(p)))
+	return append(b, p...)
+}
+
+// decoder reads the fields of an encoded message in turn.
+type decoder struct{ b []byte }
+
+// next reads the tag of the next field, reporting false at the end of the
+// message.
+func (d *decoder) next() (field, wire int, ok bool, err error) {
+	if len(d.b) == 0 {
+		return 0, 0, false, nil
+	}
+	tag, err := d.varint()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if tag>>3 == 0 || tag>>3 > math.MaxInt32 {
+		return 0, 0, false, errMalformed
+	}
+	return int(tag >> 3), int(tag & 7), true, nil
+}
+
+func (d *decoder) varint() (uint64, error) {
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		return 0, errMalformed
+	}
+	d.b = d.b[n:]
+	return v, nil
+}
+
+func (d *decoder) fixed64() (uint64, error) {
+	if len(d.b) < 8 {
+		return 0, errMalformed
+	}
+	v := binary.LittleEndian.Uint64(d.b)
+	d.b = d.b[8:]
+	return v, nil
+}
+
+func (d *decoder) bytes() ([]byte, error) {
+	n, err := d.varint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.b)) {
+		return nil, errMalformed
+	}
+	p := d.b[:n:n]
+	d.b = d.b[n:]
+	return p, nil
+}
+
+// skip skips a field of an unknown number, or of a wire type its number
+// doesn't take.
+func (d *decoder) skip(wire int) error {
+	var err error
+	switch wire {
+	case wireVarint:
+		_, err = d.varint()
+	case wireFixed64:
+		_, err = d.fixed64()
+	case wireBytes:
+		_, err = d.bytes()
+	case wireFixed32:
+		if len(d.b) < 4 {
+			return errMalformed
+		}
+		d.b = d.b[4:]
+	default:
+		return errMalformed
+	}
+	return err
+}
+
+// decode calls field for every field of b, which reads it or skips it.
+func decode(b []byte, field func(d *decoder, field, wire int) error) error {
+	d := &decoder{b}
+	for {
+		f, w, ok, err := d.next()
+		if err != nil || !ok {
+			return err
+		}
+		if err := field(d, f, w); err != nil {
+			return err
+		}
+	}
+}
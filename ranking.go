@@ -0,0 +1,112 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"sort"
+)
+
+type LanguageScore struct {
+	Lang  Language `json:"language"`
+	Score float64  `json:"score"`
+}
+
+func LanguageRanking(audio []byte, key string, opts ...Option) ([]LanguageScore, error) {
+	return NewClient(key, opts...).LanguageRanking(audio)
+}
+
+func LanguageRankingContext(ctx context.Context, audio []byte, key string, opts ...Option) ([]LanguageScore, error) {
+	return NewClient(key, opts...).LanguageRankingContext(ctx, audio)
+}
+
+// LanguageRanking scores every queried language by the confidence of its
+// transcript and returns them best first. Languages that failed or heard
+// nothing score zero; ties keep the SupportedLanguages order.
+func (c *Client) LanguageRanking(audio []byte, opts ...Option) ([]LanguageScore, error) {
+	return c.LanguageRankingContext(context.Background(), audio, opts...)
+}
+
+// LanguageRankingContext is LanguageRanking abandoning the requests in
+// flight once ctx is done.
+func (c *Client) LanguageRankingContext(ctx context.Context, audio []byte, opts ...Option) ([]LanguageScore, error) {
+	c = c.with(opts)
+	c, audio, err := c.prepare(audio)
+	if err != nil {
+		return nil, err
+	}
+	hs, err := c.listenAll(ctx, bytes.NewReader(audio), int64(len(audio)), nil)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[Language]float64, len(hs))
+	var answered bool
+	for _, h := range hs {
+		if h.Err == nil {
+			scores[h.Language] = h.Alternative.Confidence
+			answered = true
+		}
+	}
+	if !answered {
+		return nil, newSummaryError(c.languages(), hs)
+	}
+	var ranking []LanguageScore
+	for _, lang := range c.languages() {
+		ranking = append(ranking, LanguageScore{Lang: lang, Score: scores[lang]})
+	}
+	sort.SliceStable(ranking, func(i, j int) bool { return ranking[i].Score > ranking[j].Score })
+	return ranking, nil
+}
+
+func DetectLanguage(audio []byte, key string, opts ...Option) (Language, float64, error) {
+	return NewClient(key, opts...).DetectLanguage(audio)
+}
+
+func DetectLanguageContext(ctx context.Context, audio []byte, key string, opts ...Option) (Language, float64, error) {
+	return NewClient(key, opts...).DetectLanguageContext(ctx, audio)
+}
+
+// DetectLanguage returns the language audio is most likely spoken in, for
+// callers that don't need a usable transcript. Its confidence is the share
+// of the LanguageRanking scores that language holds, from 0 to 1, so it is
+// low when several languages were heard about as well.
+func (c *Client) DetectLanguage(audio []byte, opts ...Option) (Language, float64, error) {
+	return c.DetectLanguageContext(context.Background(), audio, opts...)
+}
+
+// DetectLanguageContext is DetectLanguage abandoning the requests in flight
+// once ctx is done.
+func (c *Client) DetectLanguageContext(ctx context.Context, audio []byte, opts ...Option) (Language, float64, error) {
+	ranking, err := c.LanguageRankingContext(ctx, audio, opts...)
+	if err != nil {
+		return 0, 0, err
+	}
+	var total float64
+	for _, s := range ranking {
+		total += s.Score
+	}
+	if total == 0 {
+		return ranking[0].Lang, 0, nil
+	}
+	return ranking[0].Lang, ranking[0].Score / total, nil
+}
+
+// RankHypotheses orders the hypotheses of ListenFileAll best first, for
+// showing runner-up guesses: those that succeeded by confidence, then the
+// failed ones, ties in the order of the Language constants.
+func RankHypotheses(all map[Language]Hypothesis) []Hypothesis {
+	hs := make([]Hypothesis, 0, len(all))
+	for _, h := range all {
+		hs = append(hs, h)
+	}
+	sort.Slice(hs, func(i, j int) bool {
+		a, b := hs[i], hs[j]
+		switch {
+		case (a.Err == nil) != (b.Err == nil):
+			return a.Err == nil
+		case a.Alternative.Confidence != b.Alternative.Confidence:
+			return a.Alternative.Confidence > b.Alternative.Confidence
+		}
+		return a.Language < b.Language
+	})
+	return hs
+}
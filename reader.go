@@ -0,0 +1,140 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// defaultMaxReadDuration bounds ListenLive when WithMaxDuration is not
+// given, about as much as Google accepts in a single request.
+const defaultMaxReadDuration = 15 * time.Second
+
+// ListenLive reads audio from a live source with a Client made of key and
+// opts; see Client.ListenLive.
+func ListenLive(r io.Reader, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenLive(r)
+}
+
+// ListenLiveContext is ListenLive abandoning the reading and the requests
+// in flight once ctx is done.
+func ListenLiveContext(ctx context.Context, r io.Reader, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenLiveContext(ctx, r)
+}
+
+// ListenLive reads audio from a live source such as a microphone until it
+// returns EOF or the maximum duration is reached, then recognizes what was
+// read. A push-to-talk UI would start it on key-down and close the source
+// on key-up.
+func (c *Client) ListenLive(r io.Reader, opts ...Option) (*Hypothesis, error) {
+	return c.ListenLiveContext(context.Background(), r, opts...)
+}
+
+// ListenLiveContext is ListenLive abandoning both the reading and the
+// recognition once ctx is done; a Read already blocked on r returns only
+// when r does.
+func (c *Client) ListenLiveContext(ctx context.Context, r io.Reader, opts ...Option) (*Hypothesis, error) {
+	c = c.with(opts)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	max := c.cfg.maxDuration
+	if max <= 0 {
+		max = defaultMaxReadDuration
+	}
+	audio, err := readBounded(ctx, r, int64(bytesFor(max, c.sampleRate())))
+	if err != nil {
+		return nil, err
+	}
+	return c.listen(ctx, audio)
+}
+
+func ListenReader(r io.Reader, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenReader(r)
+}
+
+// ListenReaderContext is ListenReader abandoning the reading and the
+// requests in flight once ctx is done.
+func ListenReaderContext(ctx context.Context, r io.Reader, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenReaderContext(ctx, r)
+}
+
+// ListenReader recognizes all the audio r yields, such as piped standard
+// input. The audio is spooled to a temporary file, removed on return, which
+// every language request then reads like ListenPath does, so memory stays
+// flat however long the recording. Unlike ListenLive, meant for live
+// sources, it has no duration limit and reads r to the end.
+func (c *Client) ListenReader(r io.Reader, opts ...Option) (*Hypothesis, error) {
+	return c.ListenReaderContext(context.Background(), r, opts...)
+}
+
+// ListenReaderContext is ListenReader giving up once ctx is done: the
+// spooling stops after the Read in progress, and the requests in flight
+// are abandoned.
+func (c *Client) ListenReaderContext(ctx context.Context, r io.Reader, opts ...Option) (*Hypothesis, error) {
+	c = c.with(opts)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp("", "gorec-*.raw")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := io.Copy(f, ctxReader{ctx, r}); err != nil {
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return c.ListenPathContext(ctx, f.Name())
+}
+
+// ctxReader reads from r until ctx is done, failing with ctx.Err() then.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr ctxReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// readBounded reads up to max bytes of r. Once ctx is done it returns
+// straight away, and the reading stops after the Read in progress.
+func readBounded(ctx context.Context, r io.Reader, max int64) ([]byte, error) {
+	type result struct {
+		audio []byte
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var buf bytes.Buffer
+		chunk := make([]byte, 32*1024)
+		lr := io.LimitReader(r, max)
+		for ctx.Err() == nil {
+			n, err := lr.Read(chunk)
+			buf.Write(chunk[:n])
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				done <- result{nil, err}
+				return
+			}
+		}
+		done <- result{buf.Bytes(), nil}
+	}()
+	select {
+	case res := <-done:
+		return res.audio, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,62 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadBounded(t *testing.T) {
+	b, err := readBounded(context.Background(), bytes.NewReader(make([]byte, 100)), 10)
+	if err != nil || len(b) != 10 {
+		t.Fatalf("readBounded = %d bytes, %v", len(b), err)
+	}
+	pr, _ := io.Pipe()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := readBounded(ctx, pr, 10); err != context.DeadlineExceeded {
+		t.Fatalf("readBounded on a blocked reader = %v", err)
+	}
+}
+
+// tickReader yields a byte per Read every millisecond, forever.
+type tickReader struct{ reads int32 }
+
+func (r *tickReader) Read(p []byte) (int, error) {
+	atomic.AddInt32(&r.reads, 1)
+	time.Sleep(time.Millisecond)
+	p[0] = 1
+	return 1, nil
+}
+
+func TestReadBoundedStopsOnCancel(t *testing.T) {
+	r := &tickReader{}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := readBounded(ctx, r, 1<<30); err != context.DeadlineExceeded {
+		t.Fatalf("readBounded = %v", err)
+	}
+	stopped := atomic.LoadInt32(&r.reads)
+	time.Sleep(50 * time.Millisecond)
+	if reads := atomic.LoadInt32(&r.reads); reads > stopped+1 {
+		t.Errorf("r was read %d more times after cancellation", reads-stopped)
+	}
+}
+
+func TestListenReaderStopsOnCancel(t *testing.T) {
+	r := &tickReader{}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	c := NewClient("k", WithBackend(&echoBackend{}), WithLanguages(English))
+	if _, err := c.ListenReaderContext(ctx, r); err != context.DeadlineExceeded {
+		t.Fatalf("ListenReaderContext of an endless reader = %v", err)
+	}
+	stopped := atomic.LoadInt32(&r.reads)
+	time.Sleep(50 * time.Millisecond)
+	if reads := atomic.LoadInt32(&r.reads); reads != stopped {
+		t.Errorf("r was read %d more times after ListenReaderContext returned", reads-stopped)
+	}
+}
@@ -0,0 +1,193 @@
+package gorec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Fetcher opens remote audio, such as objects in a storage bucket.
+type Fetcher interface {
+	Fetch(ctx context.Context, u *url.URL) (io.ReadCloser, error)
+}
+
+// WithFetcher has ListenURL open URLs of scheme, such as "s3", with f.
+// URLs of http and https are opened with an HTTPFetcher unless another is
+// given.
+func WithFetcher(scheme string, f Fetcher) Option {
+	return func(c *config) {
+		fetchers := make(map[string]Fetcher, len(c.fetchers)+1)
+		for s, f := range c.fetchers {
+			fetchers[s] = f
+		}
+		fetchers[strings.ToLower(scheme)] = f
+		c.fetchers = fetchers
+	}
+}
+
+func ListenURL(ctx context.Context, rawURL string, key string, opts ...Option) (*Hypothesis, error) {
+	return NewClient(key, opts...).ListenURL(ctx, rawURL)
+}
+
+// ListenURL recognizes the audio at rawURL, opened with the Fetcher
+// WithFetcher gave for its scheme, such as an S3Fetcher for s3:// URLs or
+// a GCSFetcher for gs:// ones. The audio is read into memory, never to
+// disk, and at most WithMaxUploadBytes of it are read. The hypothesis'
+// Source is rawURL.
+func (c *Client) ListenURL(ctx context.Context, rawURL string, opts ...Option) (*Hypothesis, error) {
+	c = c.with(opts)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := c.cfg.fetchers[strings.ToLower(u.Scheme)]
+	if !ok {
+		switch strings.ToLower(u.Scheme) {
+		case "http", "https":
+			f = HTTPFetcher{}
+		default:
+			return nil, fmt.Errorf("No fetcher for %s URLs", u.Scheme)
+		}
+	}
+	body, err := f.Fetch(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("Fetching %s: %w", u.Redacted(), err)
+	}
+	defer body.Close()
+	var r io.Reader = body
+	if c.cfg.maxUpload > 0 {
+		// One byte more tells a file of the maximum size from a larger one.
+		r = io.LimitReader(body, c.cfg.maxUpload+1)
+	}
+	audio, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Fetching %s: %w", u.Redacted(), err)
+	}
+	if err := c.checkSize(int64(len(audio))); err != nil {
+		return nil, err
+	}
+	h, err := c.listen(ctx, audio)
+	if h != nil {
+		h.Source = rawURL
+	}
+	return h, err
+}
+
+// HTTPFetcher is a Fetcher getting http and https URLs with Client, a
+// default client if nil, sending Header with each request.
+type HTTPFetcher struct {
+	Client *http.Client
+	Header http.Header
+}
+
+func (f HTTPFetcher) Fetch(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+	r, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range f.Header {
+		r.Header[k] = v
+	}
+	return openBody(f.Client, r)
+}
+
+// S3Fetcher is a Fetcher for s3://bucket/key URLs, getting the objects
+// from Amazon S3 with requests signed with the given credentials, as
+// AWSBackend signs its own.
+type S3Fetcher struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Region          string
+
+	// Endpoint, such as that of an S3-compatible store, is where buckets
+	// are found by path, https://BUCKET.s3.REGION.amazonaws.com by default.
+	Endpoint   string
+	HTTPClient *http.Client
+
+	clock clock
+}
+
+func (f *S3Fetcher) Fetch(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+	bucket, key := u.Host, strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("Invalid S3 URL %s", u)
+	}
+	base := "https://" + bucket + ".s3." + f.Region + ".amazonaws.com"
+	if f.Endpoint != "" {
+		base = strings.TrimSuffix(f.Endpoint, "/") + "/" + bucket
+	}
+	r, err := http.NewRequestWithContext(ctx, "GET", base+"/"+(&url.URL{Path: key}).EscapedPath(), nil)
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("X-Amz-Content-Sha256", hashHex(nil))
+	if f.SessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", f.SessionToken)
+	}
+	clk := f.clock
+	if clk == nil {
+		clk = realClock{}
+	}
+	signV4(r, nil, f.AccessKeyID, f.SecretAccessKey, f.Region, "s3", clk.Now())
+	return openBody(f.HTTPClient, r)
+}
+
+// GCSEndpoint is the JSON API of Google Cloud Storage.
+const GCSEndpoint = "https://storage.googleapis.com/storage/v1"
+
+// GCSFetcher is a Fetcher for gs://bucket/object URLs, getting the objects
+// from Google Cloud Storage with the access tokens of Tokens, such as a
+// ServiceAccount, or unauthenticated, for public objects, if nil.
+type GCSFetcher struct {
+	Tokens TokenSource
+
+	// Endpoint defaults to GCSEndpoint and HTTPClient to a default client.
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+func (f *GCSFetcher) Fetch(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+	bucket, object := u.Host, strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || object == "" {
+		return nil, fmt.Errorf("Invalid GCS URL %s", u)
+	}
+	endpoint := f.Endpoint
+	if endpoint == "" {
+		endpoint = GCSEndpoint
+	}
+	r, err := http.NewRequestWithContext(ctx, "GET", endpoint+"/b/"+url.PathEscape(bucket)+"/o/"+url.PathEscape(object)+"?alt=media", nil)
+	if err != nil {
+		return nil, err
+	}
+	if f.Tokens != nil {
+		token, err := f.Tokens.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("Getting an access token: %w", err)
+		}
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return openBody(f.HTTPClient, r)
+}
+
+// openBody sends r with client, a default client if nil, returning the body of
+// a successful response.
+func openBody(client *http.Client, r *http.Request) (io.ReadCloser, error) {
+	if client == nil {
+		client = sharedClient
+	}
+	resp, err := client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+	return resp.Body, nil
+}
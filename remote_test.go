@@ -0,0 +1,87 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// audioServer serves loud PCM, checking each request with check.
+func audioServer(t *testing.T, check func(r *http.Request)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check(r)
+		if r.URL.Path == "/missing" {
+			http.Error(w, "no such object", http.StatusNotFound)
+			return
+		}
+		w.Write(speechPCM(2*time.Second, [2]time.Duration{0, 2 * time.Second}))
+	}))
+}
+
+func TestListenURL(t *testing.T) {
+	srv := audioServer(t, func(r *http.Request) {
+		if r.Header.Get("X-Token") != "t" {
+			t.Errorf("request header %v", r.Header)
+		}
+	})
+	defer srv.Close()
+	opts := []Option{WithBackend(durationBackend{}), WithLanguages(English), WithFetcher("http", HTTPFetcher{Header: http.Header{"X-Token": {"t"}}})}
+	h, err := ListenURL(context.Background(), srv.URL+"/a.raw", "k", opts...)
+	if err != nil || h.Alternative.Transcript != "s2" || h.Source != srv.URL+"/a.raw" {
+		t.Fatalf("ListenURL = %v, %v", h, err)
+	}
+	if _, err := ListenURL(context.Background(), srv.URL+"/missing", "k", opts...); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("missing object returned %v", err)
+	}
+	if _, err := ListenURL(context.Background(), srv.URL+"/a.raw", "k", append(opts, WithMaxUploadBytes(1000))...); !errors.Is(err, ErrAudioTooLarge) {
+		t.Errorf("large object returned %v", err)
+	}
+	if _, err := ListenURL(context.Background(), "ftp://host/a.raw", "k", opts...); err == nil {
+		t.Error("ListenURL opened an ftp URL")
+	}
+}
+
+func TestS3Fetcher(t *testing.T) {
+	srv := audioServer(t, func(r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if r.URL.EscapedPath() != "/bucket/calls/a%20b.raw" {
+			t.Errorf("path %s", r.URL.EscapedPath())
+		}
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20200101/eu-west-1/s3/aws4_request") || r.Header.Get("X-Amz-Security-Token") != "session" {
+			t.Errorf("request header %v", r.Header)
+		}
+	})
+	defer srv.Close()
+	f := &S3Fetcher{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session", Region: "eu-west-1", Endpoint: srv.URL,
+		clock: newFakeClock()}
+	h, err := ListenURL(context.Background(), "s3://bucket/calls/a b.raw", "k", WithBackend(durationBackend{}), WithLanguages(English), WithFetcher("s3", f))
+	if err != nil || h.Alternative.Transcript != "s2" {
+		t.Errorf("ListenURL = %v, %v", h, err)
+	}
+	if _, err := f.Fetch(context.Background(), &url.URL{Scheme: "s3", Host: "bucket"}); err == nil {
+		t.Error("fetched a bucket")
+	}
+}
+
+type staticToken string
+
+func (s staticToken) Token(ctx context.Context) (string, error) { return string(s), nil }
+
+func TestGCSFetcher(t *testing.T) {
+	srv := audioServer(t, func(r *http.Request) {
+		if r.URL.EscapedPath() != "/b/bucket/o/calls%2Fa.raw" || r.URL.Query().Get("alt") != "media" || r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("request %s %v", r.URL, r.Header)
+		}
+	})
+	defer srv.Close()
+	f := &GCSFetcher{Tokens: staticToken("tok"), Endpoint: srv.URL}
+	h, err := ListenURL(context.Background(), "gs://bucket/calls/a.raw", "k", WithBackend(durationBackend{}), WithLanguages(English), WithFetcher("gs", f))
+	if err != nil || h.Alternative.Transcript != "s2" {
+		t.Errorf("ListenURL = %v, %v", h, err)
+	}
+}
@@ -0,0 +1,107 @@
+package gorec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// decodeResponse merges the results of every JSON object in body. Google
+// answers with several objects, typically an empty one first, and has
+// separated them by newlines as well as not at all; a Decoder reads both.
+func decodeResponse(body []byte) (*GoogleResponse, error) {
+	gr := &GoogleResponse{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for {
+		var part GoogleResponse
+		err := dec.Decode(&part)
+		if err == io.EOF {
+			return gr, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Decoding response: %w", err)
+		}
+		if len(part.Results) > 0 {
+			if len(gr.Results) == 0 {
+				gr.ResultIndex = part.ResultIndex
+			}
+			gr.Results = append(gr.Results, part.Results...)
+		}
+	}
+}
+
+// UnmarshalJSON accepts the field names Google has used over time, so a
+// rename decodes to the same results instead of silently to none.
+func (gr *GoogleResponse) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Result      []Result `json:"result"`
+		Results     []Result `json:"results"`
+		ResultIndex *int     `json:"result_index"`
+		Index       *int     `json:"resultIndex"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*gr = GoogleResponse{Results: append(v.Result, v.Results...)}
+	if v.ResultIndex != nil {
+		gr.ResultIndex = *v.ResultIndex
+	} else if v.Index != nil {
+		gr.ResultIndex = *v.Index
+	}
+	return nil
+}
+
+func (r *Result) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Alternative  []Alternative `json:"alternative"`
+		Alternatives []Alternative `json:"alternatives"`
+		Final        bool          `json:"final"`
+		IsFinal      bool          `json:"is_final"`
+		IsFinalCamel bool          `json:"isFinal"`
+		Channel      int           `json:"channel"`
+
+		Intents       []Intent       `json:"intents"`
+		Entities      []Entity       `json:"entities"`
+		Disagreements []Disagreement `json:"disagreements"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*r = Result{
+		Alternatives:  append(v.Alternative, v.Alternatives...),
+		Final:         v.Final || v.IsFinal || v.IsFinalCamel,
+		Channel:       v.Channel,
+		Intents:       v.Intents,
+		Entities:      v.Entities,
+		Disagreements: v.Disagreements,
+	}
+	return nil
+}
+
+// Segment is the best alternative of one of the results in a response.
+type Segment struct {
+	Alternative Alternative `json:"text"`
+	ResultIndex int         `json:"result_index"`
+	Final       bool        `json:"final"`
+}
+
+// Segments returns the most confident alternative of every result, in order,
+// for responses that split an utterance across several results. Results
+// without alternatives are skipped.
+func (gr *GoogleResponse) Segments() []Segment {
+	var segments []Segment
+	for i, r := range gr.Results {
+		if len(r.Alternatives) == 0 {
+			continue
+		}
+		best := r.Alternatives[0]
+		for _, a := range r.Alternatives[1:] {
+			if a.Confidence > best.Confidence {
+				best = a
+			}
+		}
+		segments = append(segments, Segment{Alternative: best, ResultIndex: i, Final: r.Final})
+	}
+	return segments
+}
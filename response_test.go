@@ -0,0 +1,63 @@
+package gorec
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecodeResponseDelimiters(t *testing.T) {
+	const (
+		empty  = `{"result":[]}`
+		result = `{"result":[{"alternative":[{"transcript":"a","confidence":0.5}],"final":true}],"result_index":0}`
+	)
+	for name, body := range map[string]string{
+		"newline-delimited": empty + "\n" + result + "\n",
+		"CRLF-delimited":    empty + "\r\n" + result + "\r\n",
+		"concatenated":      empty + result,
+		"space-separated":   empty + "  " + result + " ",
+		"single object":     result,
+	} {
+		gr, err := decodeResponse([]byte(body))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(gr.Results) != 1 || gr.Results[0].Alternatives[0].Transcript != "a" {
+			t.Errorf("%s: decoded to %+v", name, gr)
+		}
+	}
+	if gr, err := decodeResponse(nil); err != nil || len(gr.Results) != 0 {
+		t.Errorf("empty body decoded to %+v, %v", gr, err)
+	}
+	if _, err := decodeResponse([]byte(empty + "\n{bad")); err == nil {
+		t.Error("a malformed object decoded without error")
+	}
+}
+
+func TestResultRoundTrip(t *testing.T) {
+	want := Result{
+		Alternatives: []Alternative{{
+			Transcript: "wake me tomorrow",
+			Confidence: 0.9,
+			Words:      []Word{{Word: "wake", Start: time.Second, End: 2 * time.Second, Estimated: true, Speaker: 1, Confidence: 0.8}},
+		}},
+		Final:         true,
+		Channel:       2,
+		Intents:       []Intent{{Name: "set_alarm", Confidence: 0.7}},
+		Entities:      []Entity{{Name: "wit$datetime", Role: "datetime", Body: "tomorrow", Value: "2024-01-02", Confidence: 0.6, Start: 8, End: 16}},
+		Disagreements: []Disagreement{{Word: 1, Chosen: "me", Heard: []string{"me", "be"}}},
+	}
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Result
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip of %s\ngot  %+v\nwant %+v", b, got, want)
+	}
+}
@@ -0,0 +1,93 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// RetryPolicy retries requests that failed transiently: with a 5xx status, a
+// network error or, from Google, an empty body. The n-th retry waits
+// Backoff doubled n-1 times, at most MaxBackoff if set, less a random share
+// of up to Jitter, from 0 to 1, so that clients failing together don't
+// retry together.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	Jitter      float64
+}
+
+// WithRetry retries transient failures as p says. By default every request
+// is attempted once.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *config) { c.retry = p }
+}
+
+// fetch is fetchOnce retried as the Client's RetryPolicy allows.
+func (c *Client) fetch(ctx context.Context, r io.ReaderAt, size int64, lang Language, h *Hypothesis) (gr *GoogleResponse, raw []byte, err error) {
+	for attempt := 1; ; attempt++ {
+		gr, raw, err = c.fetchOnce(ctx, io.NewSectionReader(r, 0, size), size, lang, h)
+		if c.failover(ctx, err) {
+			// Another key is tried at once, not counting as an attempt.
+			attempt--
+			continue
+		}
+		if attempt >= c.cfg.retry.MaxAttempts || !c.transient(ctx, err, raw) {
+			return gr, raw, err
+		}
+		c.retrying(lang, attempt, err)
+		select {
+		case <-c.cfg.clock.After(c.cfg.retry.delay(attempt)):
+		case <-ctx.Done():
+			return gr, raw, err
+		}
+	}
+}
+
+// transient reports whether a request that returned err and raw may succeed
+// if tried again.
+func (c *Client) transient(ctx context.Context, err error, raw []byte) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err == nil {
+		return c.cfg.backend == nil && len(raw) == 0
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode/100 == 5
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && !errors.Is(err, ErrRedirect)
+}
+
+// failover reports whether a request that returned err ran out of quota on
+// a key of the Client's KeyPool that has others to try.
+func (c *Client) failover(ctx context.Context, err error) bool {
+	return c.cfg.keyPool != nil && ctx.Err() == nil && c.cfg.backend == nil &&
+		errors.Is(err, ErrQuotaExceeded) && c.cfg.keyPool.available(c.cfg.clock.Now())
+}
+
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+	if shift := attempt - 1; shift > 0 {
+		// Doubling that would overflow waits as long as a Duration can.
+		if d > math.MaxInt64>>min(shift, 63) {
+			d = math.MaxInt64
+		} else {
+			d <<= shift
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+	return d
+}
@@ -0,0 +1,28 @@
+package gorec
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryDelayOverflow(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 70, Backoff: 100 * time.Millisecond}
+	prev := time.Duration(0)
+	for attempt := 1; attempt < p.MaxAttempts; attempt++ {
+		d := p.delay(attempt)
+		if d < prev {
+			t.Fatalf("attempt %d waits %v, less than the %v before", attempt, d, prev)
+		}
+		prev = d
+	}
+	if prev <= 0 {
+		t.Errorf("last retry waits %v", prev)
+	}
+	p.MaxBackoff = time.Minute
+	if d := p.delay(69); d != time.Minute {
+		t.Errorf("attempt 69 with MaxBackoff waits %v, want a minute", d)
+	}
+	if d := p.delay(3); d != 400*time.Millisecond {
+		t.Errorf("attempt 3 waits %v, want 400ms", d)
+	}
+}
@@ -0,0 +1,92 @@
+package gorec
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const mixedResults = `{"result":[` +
+	`{"alternative":[{"transcript":"interim","confidence":0.9}],"final":false},` +
+	`{"alternative":[{"transcript":"final","confidence":0.6},{"transcript":"final too"}],"final":true}` +
+	`],"result_index":0}`
+
+func TestCheckAlternativesPreferFinal(t *testing.T) {
+	gr := &GoogleResponse{}
+	if err := json.Unmarshal([]byte(mixedResults), gr); err != nil {
+		t.Fatal(err)
+	}
+	for preferFinal, want := range map[bool]string{true: "final", false: "interim"} {
+		ri, ai, ok := checkAlternatives(gr, preferFinal, confidence)
+		if !ok || gr.Results[ri].Alternatives[ai].Transcript != want {
+			t.Errorf("checkAlternatives(preferFinal %v) picked %d/%d, want %q", preferFinal, ri, ai, want)
+		}
+	}
+	if _, _, ok := checkAlternatives(&GoogleResponse{}, true, confidence); ok {
+		t.Error("checkAlternatives picked an alternative of an empty response")
+	}
+}
+
+func TestWithPreferFinal(t *testing.T) {
+	srv, eps := newLanguageServer(map[string]string{"en-gb": mixedResults})
+	defer srv.Close()
+	for preferFinal, want := range map[bool]string{true: "final", false: "interim"} {
+		h, err := Recognize([]byte{1, 2}, "k", English, WithLanguageEndpoint(eps), WithPreferFinal(preferFinal))
+		if err != nil || h.Alternative.Transcript != want {
+			t.Errorf("WithPreferFinal(%v) = %v, %v, want %q", preferFinal, h, err, want)
+		}
+	}
+}
+
+func TestSelectors(t *testing.T) {
+	hyp := func(lang Language, transcript string, conf float64) Hypothesis {
+		return Hypothesis{Language: lang, Alternative: Alternative{Transcript: transcript, Confidence: conf}}
+	}
+	hs := []Hypothesis{
+		hyp(French, "oui", 0.9),
+		hyp(Spanish, "buenos dias a todos", 0.85),
+		hyp(English, "good", 0.9),
+	}
+	tests := []struct {
+		name string
+		sel  Selector
+		want int
+	}{
+		{"MaxConfidence", MaxConfidence, 0},
+		{"Margin tie", Margin(0.1), -1},
+		{"Margin zero", Margin(0), 0},
+		{"Priors", Priors(map[Language]float64{French: 0.5, English: 0.8}), 1},
+		{"LengthWeighted", LengthWeighted(0.5), 1},
+		{"LengthWeighted zero", LengthWeighted(0), 0},
+	}
+	for _, tt := range tests {
+		if got := tt.sel.Select(hs); got != tt.want {
+			t.Errorf("%s picked %d, want %d", tt.name, got, tt.want)
+		}
+	}
+	if got := Margin(0.1).Select(hs[1:2]); got != 0 {
+		t.Errorf("Margin of a single hypothesis picked %d", got)
+	}
+	if got := MaxConfidence.Select(nil); got != -1 {
+		t.Errorf("MaxConfidence of none picked %d", got)
+	}
+}
+
+func TestWithSelector(t *testing.T) {
+	srv, eps := newLanguageServer(map[string]string{
+		"fr-fr": `{"result":[{"alternative":[{"transcript":"bonjour","confidence":0.9}],"final":true}],"result_index":0}`,
+		"es-es": `{"result":[{"alternative":[{"transcript":"buenos dias","confidence":0.85}],"final":true}],"result_index":0}`,
+	})
+	defer srv.Close()
+	c := NewClient("k", WithLanguageEndpoint(eps), WithLanguages(French, Spanish))
+	if h, err := c.ListenFile([]byte{1, 2}); err != nil || h.Language != French {
+		t.Errorf("default selection = %v, %v", h, err)
+	}
+	h, err := c.ListenFile([]byte{1, 2}, WithSelector(Priors(map[Language]float64{French: 0.5})))
+	if err != nil || h.Language != Spanish || h.Alternative.Transcript != "buenos dias" {
+		t.Errorf("Priors selection = %v, %v", h, err)
+	}
+	if _, err := c.ListenFile([]byte{1, 2}, WithSelector(Margin(0.2))); !errors.Is(err, ErrAmbiguous) {
+		t.Errorf("Margin selection err = %v, want ErrAmbiguous", err)
+	}
+}
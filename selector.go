@@ -0,0 +1,110 @@
+package gorec
+
+import (
+	"math"
+	"strings"
+)
+
+// Selector picks the best of the hypotheses of the languages that
+// succeeded and met their minimum confidence, returning its index in hs, or
+// -1 if none stands out enough to be chosen. MaxConfidence, Margin, Priors
+// and LengthWeighted are Selectors.
+type Selector interface {
+	Select(hs []Hypothesis) int
+}
+
+// SelectorFunc adapts a function to a Selector.
+type SelectorFunc func(hs []Hypothesis) int
+
+func (f SelectorFunc) Select(hs []Hypothesis) int { return f(hs) }
+
+// WithSelector picks the best hypothesis across languages with s. Without
+// it the hypothesis with the best WithScorer score wins, which is
+// MaxConfidence unless a Scorer is set. A call for which s chooses none
+// fails with ErrAmbiguous.
+func WithSelector(s Selector) Option {
+	return func(c *config) { c.selector = s }
+}
+
+// MaxConfidence picks the most confident hypothesis, the first of equals.
+var MaxConfidence Selector = SelectorFunc(func(hs []Hypothesis) int {
+	return maxBy(hs, func(h Hypothesis) float64 { return h.Alternative.Confidence })
+})
+
+// Margin picks the most confident hypothesis only if it is at least margin
+// more confident than the runner-up, so that a near tie between languages
+// fails rather than being settled by noise.
+func Margin(margin float64) Selector {
+	return SelectorFunc(func(hs []Hypothesis) int {
+		best := MaxConfidence.Select(hs)
+		for i, h := range hs {
+			if i != best && hs[best].Alternative.Confidence-h.Alternative.Confidence < margin {
+				return -1
+			}
+		}
+		return best
+	})
+}
+
+// Priors weighs the confidence of each hypothesis by how likely its
+// language is to be spoken, such as {English: 0.7, Spanish: 0.3} for a
+// British audience with many Spanish speakers. Languages missing from
+// priors weigh 1.
+func Priors(priors map[Language]float64) Selector {
+	return SelectorFunc(func(hs []Hypothesis) int {
+		return maxBy(hs, func(h Hypothesis) float64 {
+			prior, ok := priors[h.Language]
+			if !ok {
+				prior = 1
+			}
+			return h.Alternative.Confidence * prior
+		})
+	})
+}
+
+// LengthWeighted scores each hypothesis by its confidence times its number
+// of words raised to weight, favouring the languages that made more of the
+// audio out of the short, confident guesses a wrong language often gives. A
+// weight of 0 is MaxConfidence.
+func LengthWeighted(weight float64) Selector {
+	return SelectorFunc(func(hs []Hypothesis) int {
+		return maxBy(hs, func(h Hypothesis) float64 {
+			return h.Alternative.Confidence * math.Pow(float64(len(strings.Fields(h.Alternative.Transcript))), weight)
+		})
+	})
+}
+
+// maxBy returns the index of the first hypothesis of hs scoring highest, or
+// -1 if hs is empty.
+func maxBy(hs []Hypothesis, score func(Hypothesis) float64) int {
+	best := -1
+	for i, h := range hs {
+		if best < 0 || score(h) > score(hs[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// selectBest picks the best selectable hypothesis in hs with the Client's
+// Selector, returning nil if none is selectable.
+func (c *Client) selectBest(hs []Hypothesis) (*Hypothesis, error) {
+	var candidates []Hypothesis
+	for _, h := range hs {
+		if c.selectable(h) {
+			candidates = append(candidates, h)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	if c.cfg.selector == nil {
+		best := maxBy(candidates, func(h Hypothesis) float64 { return c.score(h.Alternative, h.Language) })
+		return &candidates[best], nil
+	}
+	best := c.cfg.selector.Select(candidates)
+	if best < 0 || best >= len(candidates) {
+		return nil, ErrAmbiguous
+	}
+	return &candidates[best], nil
+}
@@ -0,0 +1,378 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/carlescere/gorec"
+)
+
+// DefaultJobRetention is how long a finished job can be looked up when
+// Server.JobRetention is zero.
+const DefaultJobRetention = time.Hour
+
+// DefaultMaxJobs bounds the jobs running at once when Server.MaxJobs is
+// zero.
+const DefaultMaxJobs = 64
+
+// SignatureHeader is the header of webhook deliveries carrying their
+// signature, "sha256=" and the hex HMAC-SHA256 of the body keyed with
+// Server.WebhookSecret.
+const SignatureHeader = "X-Gorec-Signature"
+
+// The states of a job.
+const (
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// webhookAttempts is how many times a webhook is delivered before giving
+// up, webhookBackoff apart, when the receiver fails or can't be reached.
+var (
+	webhookAttempts = 3
+	webhookBackoff  = time.Second
+)
+
+// defaultWebhookClient delivers webhooks to any callback host, so it only
+// connects to public addresses; trustedWebhookClient delivers them to the
+// Server's CallbackHosts, wherever they are. Neither follows redirects,
+// which would lead a delivery elsewhere.
+var (
+	defaultWebhookClient = newWebhookClient(refuseInternal)
+	trustedWebhookClient = newWebhookClient(nil)
+)
+
+func newWebhookClient(control func(network, address string, c syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, Control: control}
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: 10 * time.Second,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// refuseInternal is a net.Dialer Control refusing to connect to addresses
+// that are not public, checked once the callback host has been resolved.
+func refuseInternal(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if internal(ap.Addr()) {
+		return fmt.Errorf("Callback address %s is not public", ap.Addr())
+	}
+	return nil
+}
+
+// internal reports whether ip is loopback, private, link-local, multicast
+// or unspecified.
+func internal(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// Job is what GET /v1/jobs/{id} answers and the webhook of a job is posted
+// once it finishes.
+type Job struct {
+	ID         string            `json:"id"`
+	Status     string            `json:"status"`
+	Hypothesis *gorec.Hypothesis `json:"hypothesis,omitempty"`
+	Error      string            `json:"error,omitempty"`
+
+	// WebhookError is why the webhook could not be delivered, if it
+	// couldn't.
+	WebhookError string `json:"webhook_error,omitempty"`
+}
+
+// submitJob answers POST /v1/jobs: it starts recognizing the upload in the
+// background and answers with the job at once.
+func (s *Server) submitJob(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := s.startJob()
+	if !ok {
+		writeError(w, http.StatusServiceUnavailable, errors.New("Too many jobs running"))
+		return
+	}
+	started := false
+	defer func() {
+		if !started {
+			s.endJob()
+		}
+	}()
+	audio, opts, ok := s.upload(w, r)
+	if !ok {
+		return
+	}
+	if len(audio) == 0 {
+		writeError(w, http.StatusBadRequest, gorec.ErrEmptyAudio)
+		return
+	}
+	callback := r.FormValue("callback")
+	if callback != "" {
+		if err := s.checkCallback(callback); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	id, err := newJobID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	job := Job{ID: id, Status: JobRunning}
+	s.jobsMu.Lock()
+	if s.jobs == nil {
+		s.jobs = make(map[string]Job)
+	}
+	s.jobs[id] = job
+	s.jobsMu.Unlock()
+
+	// The job outlives the request, until the Server is shut down.
+	started = true
+	go func() {
+		defer s.endJob()
+		s.runJob(ctx, job, audio, opts, callback)
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/v1/jobs/"+id)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// startJob reserves one of the Server's MaxJobs for a job to run under the
+// returned context, reporting false if they are all taken or the Server is
+// shutting down. endJob gives it back.
+func (s *Server) startJob() (context.Context, bool) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	max := s.MaxJobs
+	if max <= 0 {
+		max = DefaultMaxJobs
+	}
+	if s.shutdown || s.running >= max {
+		return nil, false
+	}
+	if s.jobsCtx == nil {
+		s.jobsCtx, s.cancelJobs = context.WithCancel(context.Background())
+	}
+	s.running++
+	s.jobsWG.Add(1)
+	return s.jobsCtx, true
+}
+
+func (s *Server) endJob() {
+	s.jobsMu.Lock()
+	s.running--
+	s.jobsMu.Unlock()
+	s.jobsWG.Done()
+}
+
+// Shutdown stops the Server taking jobs and waits for those running to
+// finish, webhooks included. If ctx is done first, it cancels them and
+// returns ctx's error once they have stopped.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.jobsMu.Lock()
+	s.shutdown = true
+	s.jobsMu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		s.jobsWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Close stops the Server taking jobs, cancels those running and waits for
+// them to stop.
+func (s *Server) Close() error {
+	s.jobsMu.Lock()
+	s.shutdown = true
+	s.jobsMu.Unlock()
+	s.cancel()
+	s.jobsWG.Wait()
+	return nil
+}
+
+func (s *Server) cancel() {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	if s.cancelJobs != nil {
+		s.cancelJobs()
+	}
+}
+
+// checkCallback checks callback is an http or https URL the Server may
+// post a webhook to: one of its CallbackHosts if it has any, or else a host
+// that is not the Server's own or an internal address. Host names that
+// resolve to internal addresses are refused when delivering instead.
+func (s *Server) checkCallback(callback string) error {
+	u, err := url.Parse(callback)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Invalid callback URL %q", callback)
+	}
+	host := strings.TrimSuffix(u.Hostname(), ".")
+	if len(s.CallbackHosts) > 0 {
+		if !slices.ContainsFunc(s.CallbackHosts, func(h string) bool { return strings.EqualFold(h, host) }) {
+			return fmt.Errorf("Callback host %q is not allowed", host)
+		}
+		return nil
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && internal(ip) || strings.EqualFold(host, "localhost") {
+		return fmt.Errorf("Callback host %q is not public", host)
+	}
+	return nil
+}
+
+// jobStatus answers GET /v1/jobs/{id}.
+func (s *Server) jobStatus(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/v1/jobs/")
+	s.jobsMu.Lock()
+	job, ok := s.jobs[id]
+	s.jobsMu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("No job %q", id))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+func (s *Server) runJob(ctx context.Context, job Job, audio []byte, opts []gorec.Option, callback string) {
+	h, err := s.Recognizer.ListenFileContext(ctx, audio, opts...)
+	if err != nil {
+		job.Status, job.Error = JobFailed, err.Error()
+	} else {
+		job.Status, job.Hypothesis = JobDone, h
+	}
+	s.setJob(job)
+	retention := s.JobRetention
+	if retention <= 0 {
+		retention = DefaultJobRetention
+	}
+	time.AfterFunc(retention, func() {
+		s.jobsMu.Lock()
+		delete(s.jobs, job.ID)
+		s.jobsMu.Unlock()
+	})
+	if callback == "" {
+		return
+	}
+	if err := s.deliver(ctx, callback, job); err != nil {
+		job.WebhookError = err.Error()
+		s.setJob(job)
+	}
+}
+
+// setJob records job, unless it has been forgotten already.
+func (s *Server) setJob(job Job) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	if _, ok := s.jobs[job.ID]; ok {
+		s.jobs[job.ID] = job
+	}
+}
+
+// deliver posts job to the webhook at callback, signed if the Server has a
+// WebhookSecret, retrying while the receiver fails.
+func (s *Server) deliver(ctx context.Context, callback string, job Job) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	client := s.WebhookClient
+	if client == nil {
+		client = defaultWebhookClient
+		if len(s.CallbackHosts) > 0 {
+			client = trustedWebhookClient
+		}
+	}
+	for attempt := 1; ; attempt++ {
+		err = s.post(ctx, client, callback, body)
+		var status webhookStatus
+		if err == nil || attempt == webhookAttempts || errors.As(err, &status) && status < 500 && status != http.StatusTooManyRequests {
+			return err
+		}
+		select {
+		case <-time.After(webhookBackoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// webhookStatus is the error of a webhook receiver answering with a status
+// other than 2xx.
+type webhookStatus int
+
+func (s webhookStatus) Error() string {
+	return fmt.Sprintf("Webhook answered %d %s", int(s), http.StatusText(int(s)))
+}
+
+func (s *Server) post(ctx context.Context, client *http.Client, callback string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callback, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.WebhookSecret != "" {
+		req.Header.Set(SignatureHeader, Sign(s.WebhookSecret, body))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return webhookStatus(resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the signature of a webhook delivering body keyed with
+// secret, as sent in SignatureHeader. Receivers check it with hmac.Equal
+// against their own.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Generating job ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
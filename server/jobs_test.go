@@ -0,0 +1,208 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/carlescere/gorec"
+)
+
+func submit(t *testing.T, url string, fields map[string]string) (int, Job) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("audio", "a.raw")
+	fw.Write([]byte{1, 2, 3, 4})
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	mw.Close()
+	resp, err := http.Post(url+"/v1/jobs", mw.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var job Job
+	json.NewDecoder(resp.Body).Decode(&job)
+	return resp.StatusCode, job
+}
+
+func lookup(t *testing.T, url, id string) (int, Job) {
+	resp, err := http.Get(url + "/v1/jobs/" + id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var job Job
+	json.NewDecoder(resp.Body).Decode(&job)
+	return resp.StatusCode, job
+}
+
+func TestJobWebhook(t *testing.T) {
+	defer func(d time.Duration) { webhookBackoff = d }(webhookBackoff)
+	webhookBackoff = time.Millisecond
+
+	var calls int32
+	delivered := make(chan Job, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if sig := r.Header.Get(SignatureHeader); !hmac.Equal([]byte(sig), []byte(Sign("shh", body))) {
+			t.Errorf("webhook signed %q", sig)
+		}
+		var job Job
+		json.Unmarshal(body, &job)
+		delivered <- job
+	}))
+	defer hook.Close()
+
+	c := gorec.NewClient("k", gorec.WithBackend(frenchBackend{}))
+	defer c.Close()
+	srv := httptest.NewServer(&Server{Recognizer: c, WebhookSecret: "shh", CallbackHosts: []string{"127.0.0.1"}})
+	defer srv.Close()
+
+	code, job := submit(t, srv.URL, map[string]string{"callback": hook.URL})
+	if code != http.StatusAccepted || job.ID == "" || job.Status != JobRunning {
+		t.Fatalf("submitting answered %d %+v", code, job)
+	}
+	select {
+	case got := <-delivered:
+		if got.ID != job.ID || got.Status != JobDone || got.Hypothesis == nil || got.Hypothesis.Alternative.Transcript != "bonjour" {
+			t.Errorf("webhook delivered %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("webhook called %d times, want 2", n)
+	}
+	if code, got := lookup(t, srv.URL, job.ID); code != http.StatusOK || got.Status != JobDone {
+		t.Errorf("looking up the job answered %d %+v", code, got)
+	}
+}
+
+func TestJobStatus(t *testing.T) {
+	c := gorec.NewClient("k", gorec.WithBackend(frenchBackend{}))
+	defer c.Close()
+	srv := httptest.NewServer(&Server{Recognizer: c})
+	defer srv.Close()
+
+	_, job := submit(t, srv.URL, map[string]string{"lang": "en-GB"})
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status == JobRunning && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		_, job = lookup(t, srv.URL, job.ID)
+	}
+	if job.Status != JobFailed || job.Error == "" {
+		t.Errorf("job without speech ended %+v", job)
+	}
+
+	if code, _ := lookup(t, srv.URL, "nope"); code != http.StatusNotFound {
+		t.Errorf("unknown job answered %d", code)
+	}
+	if code, _ := submit(t, srv.URL, map[string]string{"callback": "ftp://example.com"}); code != http.StatusBadRequest {
+		t.Errorf("bad callback answered %d", code)
+	}
+}
+
+func TestJobCallbackRefused(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("webhook delivered to a loopback address")
+	}))
+	defer hook.Close()
+
+	c := gorec.NewClient("k", gorec.WithBackend(frenchBackend{}))
+	defer c.Close()
+	srv := httptest.NewServer(&Server{Recognizer: c})
+	defer srv.Close()
+	for _, callback := range []string{hook.URL, "http://localhost/hook", "http://[::1]/hook", "http://169.254.169.254/latest", "http://10.0.0.1/hook"} {
+		if code, _ := submit(t, srv.URL, map[string]string{"callback": callback}); code != http.StatusBadRequest {
+			t.Errorf("callback %s answered %d", callback, code)
+		}
+	}
+
+	// Host names resolving to loopback get past submitting, but not dialing.
+	if err := (&Server{}).post(context.Background(), defaultWebhookClient, hook.URL, []byte("{}")); err == nil || !strings.Contains(err.Error(), "not public") {
+		t.Errorf("delivering to %s: err = %v", hook.URL, err)
+	}
+
+	allowed := httptest.NewServer(&Server{Recognizer: c, CallbackHosts: []string{"hooks.example.com"}})
+	defer allowed.Close()
+	if code, _ := submit(t, allowed.URL, map[string]string{"callback": "https://elsewhere.example.com/hook"}); code != http.StatusBadRequest {
+		t.Errorf("callback to a host not allowed answered %d", code)
+	}
+}
+
+// stallBackend hears nothing until its context is done, telling started
+// it was asked.
+type stallBackend struct{ started chan struct{} }
+
+func (b stallBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang gorec.Language, p gorec.BackendParams) (*gorec.GoogleResponse, error) {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestJobShutdown(t *testing.T) {
+	b := stallBackend{make(chan struct{}, 1)}
+	c := gorec.NewClient("k", gorec.WithBackend(b), gorec.WithLanguages(gorec.English), gorec.WithTimeout(time.Minute))
+	defer c.Close()
+	s := &Server{Recognizer: c, MaxJobs: 1}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	code, job := submit(t, srv.URL, nil)
+	if code != http.StatusAccepted {
+		t.Fatalf("submitting answered %d", code)
+	}
+	<-b.started
+	if code, _ := submit(t, srv.URL, nil); code != http.StatusServiceUnavailable {
+		t.Errorf("submitting beyond MaxJobs answered %d", code)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Shutdown = %v, want DeadlineExceeded", err)
+	}
+	if _, got := lookup(t, srv.URL, job.ID); got.Status != JobFailed {
+		t.Errorf("job cancelled by Shutdown ended %+v", got)
+	}
+	if code, _ := submit(t, srv.URL, nil); code != http.StatusServiceUnavailable {
+		t.Errorf("submitting after Shutdown answered %d", code)
+	}
+}
+
+func TestJobShutdownWaits(t *testing.T) {
+	c := gorec.NewClient("k", gorec.WithBackend(frenchBackend{}))
+	defer c.Close()
+	s := &Server{Recognizer: c}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	_, job := submit(t, srv.URL, nil)
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, got := lookup(t, srv.URL, job.ID); got.Status != JobDone {
+		t.Errorf("job after Shutdown %+v, want done", got)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Close after Shutdown = %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+// Package server serves gorec as a small transcription REST API.
+//
+// POST /v1/transcribe takes a multipart/form-data upload with the audio in
+// its "audio" file field and, optionally, comma-separated language codes to
+// try in a "lang" field. It answers with the winning gorec.Hypothesis as
+// JSON, or with {"error": "..."} and a status telling the failure apart.
+//
+// POST /v1/jobs takes the same upload to recognize in the background,
+// answering at once with 202 Accepted and the job's "id" and "status". The
+// job is looked up with GET /v1/jobs/{id}, its "status" "running", "done",
+// with the "hypothesis", or "failed", with the "error". If the upload has a
+// "callback" field, the job is also posted to that URL once finished,
+// signed in the X-Gorec-Signature header if Server.WebhookSecret is set.
+// Callbacks to loopback, private and link-local addresses are refused
+// unless their host is one of Server.CallbackHosts. At most Server.MaxJobs
+// run at once, and Server.Shutdown or Server.Close ends them.
+//
+// GET /ws/transcribe upgrades to a WebSocket for live transcription. The
+// client sends 16-bit mono PCM in binary messages and the text message "end"
+// once done; the "lang" and "rate" query parameters pick the languages and
+// the sample rate, and "token" may carry the bearer token. Each segment
+// recognized is answered with a {"type": "partial"} message holding its
+// "hypothesis", and the end of the audio with a {"type": "final"} message
+// holding the whole "transcript", before the server closes the connection.
+//
+// GET /metrics serves Server.Metrics in the Prometheus text format.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carlescere/gorec"
+)
+
+// DefaultMaxBodySize bounds uploads when Server.MaxBodySize is zero.
+const DefaultMaxBodySize = 10 << 20
+
+// Server is an http.Handler recognizing uploaded audio with Recognizer.
+type Server struct {
+	Recognizer *gorec.Client
+
+	// Token, when set, is the bearer token every request must carry in its
+	// Authorization header.
+	Token string
+
+	// MaxBodySize bounds the size of a request body, DefaultMaxBodySize if
+	// zero.
+	MaxBodySize int64
+
+	// Metrics, when set, is served at GET /metrics. It should be the one
+	// Recognizer records in with gorec.WithMetrics.
+	Metrics *gorec.Metrics
+
+	// WebhookSecret, when set, keys the signatures of webhook deliveries.
+	WebhookSecret string
+
+	// WebhookClient delivers webhooks, a client timing out after 30
+	// seconds if nil. Unless the Server has CallbackHosts, that client only
+	// connects to public addresses; one's own is trusted to connect where
+	// it should.
+	WebhookClient *http.Client
+
+	// CallbackHosts, when set, are the only hosts job callbacks may name,
+	// trusted wherever they resolve. Otherwise callbacks may name any host
+	// with a public address.
+	CallbackHosts []string
+
+	// JobRetention is how long a finished job can be looked up,
+	// DefaultJobRetention if zero.
+	JobRetention time.Duration
+
+	// MaxJobs bounds the jobs running at once, DefaultMaxJobs if zero.
+	// Jobs submitted beyond it are answered with 503 Service Unavailable.
+	MaxJobs int
+
+	jobsMu     sync.Mutex
+	jobs       map[string]Job
+	running    int
+	shutdown   bool
+	jobsCtx    context.Context
+	cancelJobs context.CancelFunc
+	jobsWG     sync.WaitGroup
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var method string
+	var handle func(http.ResponseWriter, *http.Request)
+	switch r.URL.Path {
+	case "/v1/transcribe":
+		method, handle = http.MethodPost, s.transcribe
+	case "/v1/jobs":
+		method, handle = http.MethodPost, s.submitJob
+	case "/ws/transcribe":
+		method, handle = http.MethodGet, s.transcribeLive
+	case "/metrics":
+		if s.Metrics == nil {
+			writeError(w, http.StatusNotFound, errors.New("Not found"))
+			return
+		}
+		method, handle = http.MethodGet, s.Metrics.ServeHTTP
+	default:
+		if !strings.HasPrefix(r.URL.Path, "/v1/jobs/") {
+			writeError(w, http.StatusNotFound, errors.New("Not found"))
+			return
+		}
+		method, handle = http.MethodGet, s.jobStatus
+	}
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("Method %s not allowed", r.Method))
+		return
+	}
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, errors.New("Missing or invalid token"))
+		return
+	}
+	handle(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.Token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok && r.URL.Path == "/ws/transcribe" {
+		// Browsers cannot set headers on a WebSocket handshake.
+		token, ok = r.URL.Query().Get("token"), true
+	}
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+func (s *Server) transcribe(w http.ResponseWriter, r *http.Request) {
+	audio, opts, ok := s.upload(w, r)
+	if !ok {
+		return
+	}
+	h, err := s.Recognizer.ListenFileContext(r.Context(), audio, opts...)
+	if err != nil {
+		writeError(w, status(err), err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h)
+}
+
+// upload reads the upload of r, answering with the error if it is not one.
+func (s *Server) upload(w http.ResponseWriter, r *http.Request) ([]byte, []gorec.Option, bool) {
+	max := s.MaxBodySize
+	if max <= 0 {
+		max = DefaultMaxBodySize
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, max)
+	audio, opts, err := readUpload(r, max)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("Upload larger than %d bytes", max))
+			return nil, nil, false
+		}
+		writeError(w, http.StatusBadRequest, err)
+		return nil, nil, false
+	}
+	return audio, opts, true
+}
+
+// readUpload returns the audio of the upload and the options its fields ask
+// for.
+func readUpload(r *http.Request, max int64) ([]byte, []gorec.Option, error) {
+	if err := r.ParseMultipartForm(max); err != nil {
+		return nil, nil, err
+	}
+	f, _, err := r.FormFile("audio")
+	if err != nil {
+		return nil, nil, fmt.Errorf("No audio file: %w", err)
+	}
+	defer f.Close()
+	audio, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts, err := languages(r.FormValue("lang"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return audio, opts, nil
+}
+
+// languages returns the option trying the comma-separated language codes,
+// none if codes is empty.
+func languages(codes string) ([]gorec.Option, error) {
+	if codes == "" {
+		return nil, nil
+	}
+	var langs []gorec.Language
+	for _, code := range strings.Split(codes, ",") {
+		l, err := gorec.LanguageFromCode(strings.TrimSpace(code))
+		if err != nil {
+			return nil, err
+		}
+		langs = append(langs, l)
+	}
+	return []gorec.Option{gorec.WithLanguages(langs...)}, nil
+}
+
+// status is the HTTP status reporting err.
+func status(err error) int {
+	switch {
+	case errors.Is(err, gorec.ErrEmptyAudio), errors.Is(err, gorec.ErrUnsupportedFormat), errors.Is(err, gorec.ErrNotWAV),
+		errors.Is(err, gorec.ErrFormatMismatch), errors.Is(err, gorec.ErrNoLanguages):
+		return http.StatusBadRequest
+	case errors.Is(err, gorec.ErrAudioTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, gorec.ErrNoSpeech):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, gorec.ErrTimeout):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/carlescere/gorec"
+)
+
+type frenchBackend struct{}
+
+func (frenchBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang gorec.Language, p gorec.BackendParams) (*gorec.GoogleResponse, error) {
+	if lang != gorec.French {
+		return &gorec.GoogleResponse{}, nil
+	}
+	alt := gorec.Alternative{Transcript: "bonjour", Confidence: 0.9}
+	return &gorec.GoogleResponse{Results: []gorec.Result{{Alternatives: []gorec.Alternative{alt}, Final: true}}}, nil
+}
+
+func upload(t *testing.T, audio []byte, lang string) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if audio != nil {
+		fw, err := mw.CreateFormFile("audio", "a.raw")
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write(audio)
+	}
+	if lang != "" {
+		mw.WriteField("lang", lang)
+	}
+	mw.Close()
+	return &body, mw.FormDataContentType()
+}
+
+func TestTranscribe(t *testing.T) {
+	c := gorec.NewClient("k", gorec.WithBackend(frenchBackend{}))
+	defer c.Close()
+	srv := httptest.NewServer(&Server{Recognizer: c, Token: "secret", MaxBodySize: 1 << 10})
+	defer srv.Close()
+
+	post := func(token string, body io.Reader, contentType string) (int, map[string]any) {
+		req, _ := http.NewRequest("POST", srv.URL+"/v1/transcribe", body)
+		req.Header.Set("Content-Type", contentType)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var v map[string]any
+		json.NewDecoder(resp.Body).Decode(&v)
+		return resp.StatusCode, v
+	}
+
+	body, ct := upload(t, []byte{1, 2, 3, 4}, "")
+	if code, v := post("secret", body, ct); code != http.StatusOK || v["text"].(map[string]any)["transcript"] != "bonjour" || v["language"] != "French" {
+		t.Errorf("upload answered %d %v", code, v)
+	}
+	for _, c := range []struct {
+		name  string
+		token string
+		audio []byte
+		lang  string
+		want  int
+	}{
+		{"no token", "", []byte{1, 2}, "", http.StatusUnauthorized},
+		{"wrong token", "guess", []byte{1, 2}, "", http.StatusUnauthorized},
+		{"no audio", "secret", nil, "", http.StatusBadRequest},
+		{"empty audio", "secret", []byte{}, "", http.StatusBadRequest},
+		{"too large", "secret", make([]byte, 2<<10), "", http.StatusRequestEntityTooLarge},
+		{"unknown language", "secret", []byte{1, 2}, "xx-yy", http.StatusBadRequest},
+		{"no speech", "secret", []byte{1, 2}, "en-GB,es", http.StatusUnprocessableEntity},
+	} {
+		body, ct := upload(t, c.audio, c.lang)
+		if code, v := post(c.token, body, ct); code != c.want || v["error"] == nil {
+			t.Errorf("%s: answered %d %v, want %d", c.name, code, v, c.want)
+		}
+	}
+
+	resp, err := http.Get(srv.URL + "/v1/transcribe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET answered %d", resp.StatusCode)
+	}
+}
+
+func TestTranscribeClientErrors(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		opt  gorec.Option
+		want int
+	}{
+		{"format mismatch", gorec.WithAudioFormat(gorec.AudioFormat{Encoding: gorec.FLAC}), http.StatusBadRequest},
+		{"no languages", gorec.WithLanguages(), http.StatusBadRequest},
+		{"too large for the client", gorec.WithMaxUploadBytes(2), http.StatusRequestEntityTooLarge},
+	} {
+		rec := gorec.NewClient("k", gorec.WithBackend(frenchBackend{}), c.opt)
+		srv := httptest.NewServer(&Server{Recognizer: rec})
+		body, ct := upload(t, []byte{1, 2, 3, 4}, "")
+		resp, err := http.Post(srv.URL+"/v1/transcribe", ct, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != c.want {
+			t.Errorf("%s: answered %d, want %d", c.name, resp.StatusCode, c.want)
+		}
+		srv.Close()
+		rec.Close()
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	m := gorec.NewMetrics()
+	c := gorec.NewClient("k", gorec.WithBackend(frenchBackend{}), gorec.WithLanguages(gorec.French), gorec.WithMetrics(m))
+	defer c.Close()
+	srv := httptest.NewServer(&Server{Recognizer: c, Metrics: m})
+	defer srv.Close()
+
+	body, ct := upload(t, []byte{1, 2, 3, 4}, "")
+	resp, err := http.Post(srv.URL+"/v1/transcribe", ct, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	resp, err = http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	out, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(out), `gorec_requests_total{language="fr-fr",outcome="ok"} 1`) {
+		t.Errorf("/metrics answered %d:\n%s", resp.StatusCode, out)
+	}
+
+	srv = httptest.NewServer(&Server{Recognizer: c})
+	defer srv.Close()
+	if resp, err := http.Get(srv.URL + "/metrics"); err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("/metrics without Metrics answered %v, %v", resp, err)
+	}
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/carlescere/gorec"
+	"github.com/carlescere/gorec/internal/websocket"
+)
+
+// liveMessage is a message sent over /ws/transcribe: a "partial" one per
+// segment recognized, holding its hypothesis or error, then a "final" one
+// holding the whole transcript.
+type liveMessage struct {
+	Type       string            `json:"type"`
+	Hypothesis *gorec.Hypothesis `json:"hypothesis,omitempty"`
+	Transcript string            `json:"transcript,omitempty"`
+}
+
+func (s *Server) transcribeLive(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts, err := languages(q.Get("lang"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if rate := q.Get("rate"); rate != "" {
+		n, err := strconv.Atoi(rate)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid sample rate %q", rate))
+			return
+		}
+		opts = append(opts, gorec.WithSampleRate(n))
+	}
+	max := s.MaxBodySize
+	if max <= 0 {
+		max = DefaultMaxBodySize
+	}
+	conn, err := websocket.Upgrade(w, r, max)
+	var he *websocket.HandshakeError
+	if errors.As(err, &he) {
+		writeError(w, he.Status, he.Err)
+	}
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	stream := s.Recognizer.Stream(ctx, opts...)
+	var transcript []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for h := range stream.Results() {
+			h := h
+			if errors.Is(h.Err, gorec.ErrNoSpeech) {
+				continue
+			}
+			if h.Err == nil {
+				transcript = append(transcript, h.Alternative.Transcript)
+			}
+			writeJSON(conn, liveMessage{Type: "partial", Hypothesis: &h})
+		}
+	}()
+
+	err = readAudio(conn, stream)
+	if err != nil {
+		cancel()
+	}
+	if cerr := stream.Close(); err == nil {
+		err = cerr
+	}
+	<-done
+	var ce *websocket.CloseError
+	switch {
+	case err == nil:
+		writeJSON(conn, liveMessage{Type: "final", Transcript: strings.Join(transcript, " ")})
+		conn.WriteClose(websocket.CloseNormal, "")
+	case errors.As(err, &ce):
+		conn.WriteClose(ce.Code, ce.Reason)
+	case errors.Is(err, io.EOF), errors.Is(err, net.ErrClosed):
+	default:
+		conn.WriteClose(websocket.CloseInternal, err.Error())
+	}
+}
+
+// readAudio writes the binary messages received to stream until the text
+// message "end". It fails with a *websocket.CloseError if the client
+// closes the connection or breaks the protocol.
+func readAudio(c *websocket.Conn, stream *gorec.Stream) error {
+	for {
+		op, payload, err := c.ReadMessage()
+		if err != nil {
+			return err
+		}
+		switch {
+		case op == websocket.OpBinary:
+			if _, err := stream.Write(payload); err != nil {
+				return err
+			}
+		case op == websocket.OpText && string(payload) == "end":
+			return nil
+		default:
+			return &websocket.CloseError{Code: websocket.CloseUnsupported, Reason: "Expected binary audio or \"end\""}
+		}
+	}
+}
+
+func writeJSON(c *websocket.Conn, msg liveMessage) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(websocket.OpText, b)
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carlescere/gorec"
+	"github.com/carlescere/gorec/internal/websocket"
+)
+
+// dialWS opens a WebSocket to path on srv.
+func dialWS(t *testing.T, srv *httptest.Server, path string) (net.Conn, *bufio.Reader) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	io.WriteString(conn, "GET "+path+" HTTP/1.1\r\nHost: gorec\r\n"+
+		"Upgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("handshake answered %d %v", resp.StatusCode, resp.Header)
+	}
+	return conn, br
+}
+
+func sendFrame(conn net.Conn, op byte, payload []byte) {
+	mask := []byte{1, 2, 3, 4}
+	h := []byte{0x80 | op}
+	if len(payload) < 126 {
+		h = append(h, 0x80|byte(len(payload)))
+	} else {
+		h = binary.BigEndian.AppendUint16(append(h, 0x80|126), uint16(len(payload)))
+	}
+	masked := make([]byte, len(payload))
+	for i := range payload {
+		masked[i] = payload[i] ^ mask[i%4]
+	}
+	conn.Write(append(append(h, mask...), masked...))
+}
+
+func receiveFrame(t *testing.T, br *bufio.Reader) (byte, []byte) {
+	var h [2]byte
+	if _, err := io.ReadFull(br, h[:]); err != nil {
+		t.Fatal(err)
+	}
+	n := int(h[1] & 0x7f)
+	if n == 126 {
+		var ext [2]byte
+		io.ReadFull(br, ext[:])
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		t.Fatal(err)
+	}
+	return h[0] & 0x0f, payload
+}
+
+func TestTranscribeLive(t *testing.T) {
+	c := gorec.NewClient("k", gorec.WithBackend(frenchBackend{}), gorec.WithMaxDuration(100*time.Millisecond))
+	defer c.Close()
+	srv := httptest.NewServer(&Server{Recognizer: c, Token: "secret"})
+	defer srv.Close()
+
+	conn, br := dialWS(t, srv, "/ws/transcribe?token=secret&lang=fr")
+	defer conn.Close()
+	sendFrame(conn, websocket.OpPing, []byte("hi"))
+	if op, payload := receiveFrame(t, br); op != websocket.OpPong || string(payload) != "hi" {
+		t.Errorf("ping answered %#x %q", op, payload)
+	}
+	// Two segments of 100 ms at 16 kHz and a remainder sent on "end".
+	sendFrame(conn, websocket.OpBinary, bytes.Repeat([]byte{1}, 4000))
+	sendFrame(conn, websocket.OpBinary, bytes.Repeat([]byte{1}, 3000))
+	sendFrame(conn, websocket.OpText, []byte("end"))
+
+	var partials int
+	for {
+		op, payload := receiveFrame(t, br)
+		if op == websocket.OpClose {
+			if code := binary.BigEndian.Uint16(payload); code != websocket.CloseNormal {
+				t.Errorf("closed with %d %q", code, payload[2:])
+			}
+			break
+		}
+		var msg struct {
+			Type       string
+			Transcript string
+			Hypothesis *struct {
+				Text struct{ Transcript string }
+			}
+		}
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			t.Fatal(err)
+		}
+		switch msg.Type {
+		case "partial":
+			partials++
+			if msg.Hypothesis == nil || msg.Hypothesis.Text.Transcript != "bonjour" {
+				t.Errorf("partial %s", payload)
+			}
+		case "final":
+			if msg.Transcript != "bonjour bonjour bonjour" {
+				t.Errorf("final %s", payload)
+			}
+		default:
+			t.Errorf("unexpected message %s", payload)
+		}
+	}
+	if partials != 3 {
+		t.Errorf("got %d partials, want 3", partials)
+	}
+}
+
+func TestTranscribeLiveRejects(t *testing.T) {
+	c := gorec.NewClient("k", gorec.WithBackend(frenchBackend{}))
+	defer c.Close()
+	srv := httptest.NewServer(&Server{Recognizer: c, Token: "secret", MaxBodySize: 100})
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/ws/transcribe?token=guess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong token answered %d", resp.StatusCode)
+	}
+	resp, err = http.Get(srv.URL + "/ws/transcribe?token=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("plain GET answered %d", resp.StatusCode)
+	}
+
+	for _, c := range []struct {
+		name    string
+		op      byte
+		payload []byte
+		want    uint16
+	}{
+		{"too large", websocket.OpBinary, make([]byte, 200), websocket.CloseTooLarge},
+		{"unknown text", websocket.OpText, []byte("hello"), websocket.CloseUnsupported},
+	} {
+		conn, br := dialWS(t, srv, "/ws/transcribe?token=secret")
+		sendFrame(conn, c.op, c.payload)
+		op, payload := receiveFrame(t, br)
+		if op != websocket.OpClose || binary.BigEndian.Uint16(payload) != c.want {
+			t.Errorf("%s: got %#x %q, want close %d", c.name, op, payload, c.want)
+		}
+		conn.Close()
+	}
+}
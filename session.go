@@ -0,0 +1,251 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Utterance is the hypothesis of one utterance of a Session, timed from the
+// start of the Session.
+type Utterance struct {
+	Hypothesis
+
+	// Start and End are where the utterance's audio starts and ends.
+	Start time.Duration
+	End   time.Duration
+}
+
+// Session transcribes audio written to it over a long time, such as a
+// meeting, one utterance at a time. Utterances are cut on pauses of half a
+// second, and speech running on for longer than the Client's
+// WithMaxDuration, 15 seconds by default, is cut where it gets that long.
+// Each utterance is recognized as soon as it ends, in order, while the
+// Session keeps a rolling transcript of those recognized so far.
+//
+// Write, Pause, Resume, Flush and Close must not be called concurrently;
+// Transcript and Utterances may be called at any time.
+type Session struct {
+	c       *Client
+	ctx     context.Context
+	gap     int
+	segment int
+
+	// buf is the audio written that is not recognized yet, and base how
+	// many bytes were written before it.
+	buf  []byte
+	base int64
+
+	paused  bool
+	closed  bool
+	jobs    chan sessionJob
+	results chan Utterance
+
+	mu         sync.Mutex
+	utterances []Utterance
+}
+
+// sessionJob is an utterance for a Session to recognize.
+type sessionJob struct {
+	audio      []byte
+	start, end time.Duration
+}
+
+// NewSession starts a Session taking 16-bit mono linear PCM at the
+// Client's sample rate. Its utterances must be received from Results until
+// that is closed, or writing eventually blocks. Cancelling ctx abandons the
+// utterances not recognized yet and closes Results; otherwise the Session
+// recognizes until Close is called.
+func (c *Client) NewSession(ctx context.Context, opts ...Option) *Session {
+	c = c.with(opts)
+	max := c.cfg.maxDuration
+	if max <= 0 {
+		max = defaultMaxReadDuration
+	}
+	s := &Session{
+		c:       c,
+		ctx:     ctx,
+		gap:     bytesFor(longPause, c.sampleRate()),
+		segment: bytesFor(max, c.sampleRate()),
+		jobs:    make(chan sessionJob, 1),
+		results: make(chan Utterance),
+	}
+	go s.recognize()
+	return s
+}
+
+// Write buffers p, handing every utterance it completes over for
+// recognition. Audio written while the Session is paused is discarded,
+// though it still counts towards the time of later utterances.
+func (s *Session) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("Write on closed Session")
+	}
+	if err := s.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if !isL16(s.c.cfg.contentType) {
+		return 0, fmt.Errorf("%w: a Session needs linear PCM, not %s", ErrUnsupportedFormat, s.c.cfg.contentType)
+	}
+	if s.paused {
+		s.base += int64(len(p))
+		return len(p), nil
+	}
+	s.buf = append(s.buf, p...)
+	return len(p), s.cut(false)
+}
+
+// Pause recognizes the speech written so far and discards what is written
+// until Resume.
+func (s *Session) Pause() error {
+	if s.paused {
+		return nil
+	}
+	err := s.Flush()
+	s.paused = true
+	return err
+}
+
+// Resume makes a paused Session take audio again.
+func (s *Session) Resume() {
+	s.paused = false
+}
+
+// Flush hands the speech written so far over for recognition without
+// waiting for a pause, as when a speaker is known to have finished.
+func (s *Session) Flush() error {
+	if s.closed {
+		return nil
+	}
+	return s.cut(true)
+}
+
+// Close flushes the Session and closes Results once every utterance has been
+// delivered.
+func (s *Session) Close() error {
+	if s.closed {
+		return nil
+	}
+	err := s.Flush()
+	s.closed = true
+	close(s.jobs)
+	return err
+}
+
+// Results delivers every utterance in order, those that
+// failed with their Err set. Utterances that hear no speech are left out.
+func (s *Session) Results() <-chan Utterance {
+	return s.results
+}
+
+// Utterances returns the utterances recognized so far, in order, leaving out
+// those that failed.
+func (s *Session) Utterances() []Utterance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Utterance(nil), s.utterances...)
+}
+
+// Transcript consolidates the utterances recognized so far into one
+// hypothesis, their transcripts stitched with the Client's ChunkMerger and
+// their Words timed from the start of the Session. It fails with
+// ErrNoSpeech until an utterance has been recognized.
+func (s *Session) Transcript() (*Hypothesis, error) {
+	s.mu.Lock()
+	hs := make([]Hypothesis, len(s.utterances))
+	for i, u := range s.utterances {
+		hs[i] = u.Hypothesis
+	}
+	s.mu.Unlock()
+	if len(hs) == 0 {
+		return nil, ErrNoSpeech
+	}
+	return s.c.mergeChunks(hs), nil
+}
+
+// cut sends the utterances buf completes, or all of its speech if all,
+// dropping the silence around them.
+func (s *Session) cut(all bool) error {
+	rate := s.c.sampleRate()
+	for {
+		spans := (VAD{SampleRate: rate}).Split(s.buf, longPause)
+		if len(spans) == 0 {
+			s.drop(len(s.buf) &^ 1)
+			return nil
+		}
+		start := bytesFor(spans[0].Start, rate)
+		end := start + len(spans[0].Audio)
+		switch {
+		case end-start > s.segment:
+			// Speech without a pause long enough to cut on.
+			end = start + s.segment
+		case !all && len(s.buf)-end < s.gap:
+			// The utterance may go on.
+			s.drop(start)
+			return nil
+		}
+		job := sessionJob{
+			audio: append([]byte(nil), s.buf[start:end]...),
+			start: s.at(start),
+			end:   s.at(end),
+		}
+		select {
+		case s.jobs <- job:
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		}
+		s.drop(end)
+	}
+}
+
+// drop removes the first n bytes of buf.
+func (s *Session) drop(n int) {
+	s.buf = append(s.buf[:0], s.buf[n:]...)
+	s.base += int64(n)
+}
+
+// at returns the time n bytes into buf is at from the start of the Session.
+func (s *Session) at(n int) time.Duration {
+	return time.Duration((s.base+int64(n))/2) * time.Second / time.Duration(s.c.sampleRate())
+}
+
+func (s *Session) recognize() {
+	defer close(s.results)
+	for {
+		var job sessionJob
+		select {
+		case j, ok := <-s.jobs:
+			if !ok {
+				return
+			}
+			job = j
+		case <-s.ctx.Done():
+			return
+		}
+		h, err := s.c.listen(s.ctx, job.audio)
+		if errors.Is(err, ErrNoSpeech) {
+			continue
+		}
+		u := Utterance{Start: job.start, End: job.end}
+		if err != nil {
+			u.Err = err
+		} else {
+			u.Hypothesis = *h
+			if words := h.Alternative.Words; len(words) == 0 {
+				u.Alternative.Words = estimateWords(h.Alternative.Transcript, job.start, job.end)
+			} else {
+				u.Alternative.Words = shiftWords(words, job.start)
+			}
+			s.mu.Lock()
+			s.utterances = append(s.utterances, u)
+			s.mu.Unlock()
+		}
+		select {
+		case s.results <- u:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package gorec
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSession(t *testing.T) {
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+	pcm := speechPCM(4*time.Second,
+		[2]time.Duration{ms(200), ms(800)},
+		[2]time.Duration{ms(1600), ms(2200)},
+		[2]time.Duration{ms(2500), ms(2700)},
+		[2]time.Duration{ms(3000), ms(3400)})
+	c := NewClient("k", WithBackend(lengthBackend{}), WithLanguages(English))
+	s := c.NewSession(context.Background())
+	var got []Utterance
+	done := make(chan struct{})
+	go func() {
+		for seg := range s.Results() {
+			got = append(got, seg)
+		}
+		close(done)
+	}()
+	piece := bytesFor(ms(100), defaultSampleRate)
+	for off := 0; off < len(pcm); off += piece {
+		switch off {
+		case bytesFor(ms(2400), defaultSampleRate):
+			if err := s.Pause(); err != nil {
+				t.Fatal(err)
+			}
+		case bytesFor(ms(2800), defaultSampleRate):
+			s.Resume()
+		}
+		if _, err := s.Write(pcm[off : off+piece]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+
+	want := []struct{ start, end time.Duration }{{ms(200), ms(800)}, {ms(1600), ms(2200)}, {ms(3000), ms(3400)}}
+	if len(got) != len(want) {
+		t.Fatalf("%d utterances, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		seg := got[i]
+		if seg.Err != nil || seg.Start < w.start-ms(30) || seg.Start > w.start+ms(30) || seg.End < w.end-ms(30) || seg.End > w.end+ms(30) {
+			t.Errorf("utterance %d at %v-%v (%v), want %v-%v", i, seg.Start, seg.End, seg.Err, w.start, w.end)
+		}
+		if words := seg.Alternative.Words; len(words) != 1 || words[0].Start != seg.Start {
+			t.Errorf("utterance %d words %+v, want one at %v", i, words, seg.Start)
+		}
+	}
+	if segs := s.Utterances(); len(segs) != len(got) {
+		t.Errorf("Utterances returned %d, want %d", len(segs), len(got))
+	}
+	h, err := s.Transcript()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := got[0].Alternative.Transcript + " " + got[1].Alternative.Transcript + " " + got[2].Alternative.Transcript; h.Alternative.Transcript != want {
+		t.Errorf("transcript %q, want %q", h.Alternative.Transcript, want)
+	}
+	if len(h.Alternative.Words) != 3 {
+		t.Errorf("transcript has %d words, want 3", len(h.Alternative.Words))
+	}
+}
+
+func TestSessionCutsLongSpeech(t *testing.T) {
+	c := NewClient("k", WithBackend(lengthBackend{}), WithLanguages(English), WithMaxDuration(time.Second))
+	s := c.NewSession(context.Background())
+	go func() {
+		s.Write(speechPCM(2500*time.Millisecond, [2]time.Duration{0, 2500 * time.Millisecond}))
+		s.Close()
+	}()
+	var lens []string
+	for seg := range s.Results() {
+		lens = append(lens, seg.Alternative.Transcript)
+	}
+	if len(lens) != 3 || lens[0] != "32000" || lens[1] != "32000" || lens[2] != "16000" {
+		t.Errorf("utterances of %v bytes, want 32000, 32000 and 16000", lens)
+	}
+	if _, err := c.NewSession(context.Background()).Transcript(); err != ErrNoSpeech {
+		t.Errorf("empty session's transcript failed with %v, want ErrNoSpeech", err)
+	}
+}
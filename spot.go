@@ -0,0 +1,339 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Spotting is an occurrence of a keyword that Spot found, between the
+// offsets Start and End from the start of the audio.
+type Spotting struct {
+	Keyword    string        `json:"keyword"`
+	Start      time.Duration `json:"start"`
+	End        time.Duration `json:"end"`
+	Confidence float64       `json:"confidence"`
+}
+
+// Spotter finds keywords in 16-bit mono PCM sampled at rate, locally and
+// without transcribing it, such as a TemplateSpotter.
+type Spotter interface {
+	Spot(pcm []byte, rate int, keywords []string) []Spotting
+}
+
+// WithSpotter has Spot find keywords with s instead of in a transcript.
+func WithSpotter(s Spotter) Option {
+	return func(c *config) { c.spotter = s }
+}
+
+func Spot(ctx context.Context, audio []byte, keywords []string, key string, opts ...Option) ([]Spotting, error) {
+	return NewClient(key, opts...).Spot(ctx, audio, keywords)
+}
+
+// Spot reports where in audio the keywords, of one or more words each, are
+// said, in order, for voice commands and wake words. Linear PCM in which
+// the VAD hears no speech has none, and costs no request. Otherwise, with
+// WithSpotter, its Spotter looks for them locally; without, the audio is
+// transcribed with the keywords as phrase hints and they are found in the
+// transcript, ignoring case and punctuation. Their offsets then come from
+// the word timings of backends that return them, and are estimated from
+// the position of the words in the transcript otherwise.
+func (c *Client) Spot(ctx context.Context, audio []byte, keywords []string, opts ...Option) ([]Spotting, error) {
+	c = c.with(opts)
+	if len(keywords) == 0 {
+		return nil, nil
+	}
+	if isL16(c.cfg.contentType) {
+		pc, pcm, err := c.prepare(audio)
+		if err != nil {
+			return nil, err
+		}
+		if len((VAD{SampleRate: pc.sampleRate()}).Trim(pcm)) == 0 {
+			return nil, nil
+		}
+		if c.cfg.spotter != nil {
+			return c.cfg.spotter.Spot(pcm, pc.sampleRate(), keywords), nil
+		}
+	} else if c.cfg.spotter != nil {
+		return nil, fmt.Errorf("%w: spotting needs linear PCM, not %s", ErrUnsupportedFormat, c.cfg.contentType)
+	}
+	hints := append(append([]string(nil), c.cfg.phraseHints...), keywords...)
+	h, err := c.with([]Option{WithPhraseHints(hints)}).listen(ctx, audio)
+	if errors.Is(err, ErrNoSpeech) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return spotTranscript(h, keywords), nil
+}
+
+// spotTranscript finds keywords in the transcript of h.
+func spotTranscript(h *Hypothesis, keywords []string) []Spotting {
+	words := strings.Fields(h.Alternative.Transcript)
+	keys := make([]string, len(words))
+	for i, w := range words {
+		keys[i] = wordKey(w)
+	}
+	timed := len(h.Alternative.Words) == len(words)
+	at := func(i int) time.Duration {
+		return h.Duration * time.Duration(i) / time.Duration(len(words))
+	}
+	var spots []Spotting
+	for i := range keys {
+		for _, keyword := range keywords {
+			kw := strings.Fields(keyword)
+			if len(kw) == 0 || i+len(kw) > len(keys) || !matchKeys(keys[i:i+len(kw)], kw) {
+				continue
+			}
+			s := Spotting{Keyword: keyword, Start: at(i), End: at(i + len(kw)), Confidence: h.Alternative.Confidence}
+			if timed {
+				s.Start, s.End = h.Alternative.Words[i].Start, h.Alternative.Words[i+len(kw)-1].End
+			}
+			spots = append(spots, s)
+		}
+	}
+	return spots
+}
+
+func matchKeys(keys, keyword []string) bool {
+	for i, w := range keyword {
+		if keys[i] != wordKey(w) {
+			return false
+		}
+	}
+	return true
+}
+
+// TemplateSpotter is a Spotter comparing the audio with recordings of the
+// keywords added with Add, by dynamic time warping of their spectra. It
+// costs no request and little CPU, but only recognizes keywords said much
+// like one of their recordings: a few by each speaker make it reliable
+// enough for a wake word. It is safe for concurrent use.
+type TemplateSpotter struct {
+	// Threshold is the highest distance, from 0 to 1, a stretch of audio
+	// may have from a recording to be reported, 0.2 if zero.
+	Threshold float64
+
+	mu        sync.RWMutex
+	templates map[string][][][]float64
+}
+
+const defaultSpotThreshold = 0.2
+
+// Add adds pcm, 16-bit mono PCM sampled at rate, as a recording of
+// keyword, trimmed to its speech.
+func (s *TemplateSpotter) Add(keyword string, pcm []byte, rate int) error {
+	speech := (VAD{SampleRate: rate}).Trim(pcm)
+	if len(speech) == 0 {
+		return ErrNoSpeech
+	}
+	features, err := spectra(speech, rate)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.templates == nil {
+		s.templates = make(map[string][][][]float64)
+	}
+	s.templates[keyword] = append(s.templates[keyword], features)
+	return nil
+}
+
+func (s *TemplateSpotter) Spot(pcm []byte, rate int, keywords []string) []Spotting {
+	audio, err := spectra(pcm, rate)
+	if err != nil || len(audio) == 0 {
+		return nil
+	}
+	threshold := s.Threshold
+	if threshold <= 0 {
+		threshold = defaultSpotThreshold
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var spots []Spotting
+	for _, keyword := range keywords {
+		var found []Spotting
+		for _, template := range s.templates[keyword] {
+			for _, m := range warpMatches(template, audio, threshold) {
+				found = addSpotting(found, Spotting{
+					Keyword:    keyword,
+					Start:      time.Duration(m.start) * spectrumHop,
+					End:        time.Duration(m.end) * spectrumHop,
+					Confidence: 1 - m.cost,
+				})
+			}
+		}
+		spots = append(spots, found...)
+	}
+	sort.SliceStable(spots, func(i, j int) bool { return spots[i].Start < spots[j].Start })
+	return spots
+}
+
+// addSpotting adds s to spots, unless it overlaps a better one, replacing
+// those it overlaps that are worse.
+func addSpotting(spots []Spotting, s Spotting) []Spotting {
+	kept := spots[:0]
+	for _, o := range spots {
+		if o.Start < s.End && s.Start < o.End {
+			if o.Confidence >= s.Confidence {
+				return spots
+			}
+			continue
+		}
+		kept = append(kept, o)
+	}
+	return append(kept, s)
+}
+
+const (
+	spectrumRate  = 16000
+	spectrumFrame = 400 // 25 ms at spectrumRate
+	spectrumSize  = 512
+	spectrumBands = 24
+	spectrumHop   = 10 * time.Millisecond
+)
+
+// spectra returns the shape of the spectrum of every 25 ms of pcm, every
+// 10 ms: the log energy of bands of frequencies, less their mean so that
+// loudness does not matter, as a unit vector, or zeros for silence.
+func spectra(pcm []byte, rate int) ([][]float64, error) {
+	s, err := samples(Resample(pcm, rate, spectrumRate))
+	if err != nil {
+		return nil, err
+	}
+	hop := spectrumRate * int(spectrumHop) / int(time.Second)
+	var frames [][]float64
+	buf := make([]complex128, spectrumSize)
+	for start := 0; start+spectrumFrame <= len(s); start += hop {
+		for i := range buf {
+			buf[i] = 0
+		}
+		for i := 0; i < spectrumFrame; i++ {
+			w := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(spectrumFrame-1))
+			buf[i] = complex(s[start+i]*w, 0)
+		}
+		fft(buf, false)
+		bands := make([]float64, spectrumBands)
+		var energy, mean float64
+		per := spectrumSize / 2 / spectrumBands
+		for b := range bands {
+			var e float64
+			for k := 1 + b*per; k < 1+(b+1)*per; k++ {
+				e += real(buf[k])*real(buf[k]) + imag(buf[k])*imag(buf[k])
+			}
+			energy += e
+			bands[b] = math.Log(e + 1e-10)
+			mean += bands[b]
+		}
+		mean /= spectrumBands
+		var norm float64
+		for b := range bands {
+			bands[b] -= mean
+			norm += bands[b] * bands[b]
+		}
+		if energy < silentSpectrum || norm == 0 {
+			frames = append(frames, make([]float64, spectrumBands))
+			continue
+		}
+		norm = math.Sqrt(norm)
+		for b := range bands {
+			bands[b] /= norm
+		}
+		frames = append(frames, bands)
+	}
+	return frames, nil
+}
+
+// silentSpectrum is the energy below which a frame is silence, about that
+// of samples at the VAD's lowest threshold.
+const silentSpectrum = 1e-3
+
+// spectrumDistance is the distance between two frames of spectra, from 0
+// for the same shape to 1 for unrelated ones, such as silence and anything
+// but silence.
+func spectrumDistance(a, b []float64) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 && nb == 0 {
+		return 0
+	}
+	return min(1, 1-dot)
+}
+
+type warpMatch struct {
+	start, end int
+	cost       float64
+}
+
+// warpMatches finds the stretches of audio that template matches by
+// subsequence dynamic time warping with a mean frame distance below
+// threshold, the best of each run of overlapping ones.
+func warpMatches(template, audio [][]float64, threshold float64) []warpMatch {
+	n, m := len(template), len(audio)
+	if n == 0 {
+		return nil
+	}
+	// For the previous and the current frame of template, cost and steps
+	// hold the summed distance and length of the best path ending at each
+	// frame of audio, and start the frame of audio it starts at.
+	prevCost, cost := make([]float64, m), make([]float64, m)
+	prevSteps, steps := make([]int, m), make([]int, m)
+	prevStart, start := make([]int, m), make([]int, m)
+	for i := 0; i < n; i++ {
+		for j := 0; j < m; j++ {
+			d := spectrumDistance(template[i], audio[j])
+			switch {
+			case i == 0:
+				// A match may start anywhere in the audio.
+				cost[j], steps[j], start[j] = d, 1, j
+			default:
+				best, bs, bst := prevCost[j], prevSteps[j], prevStart[j]
+				if j > 0 && mean(prevCost[j-1], prevSteps[j-1]) <= mean(best, bs) {
+					best, bs, bst = prevCost[j-1], prevSteps[j-1], prevStart[j-1]
+				}
+				if j > 0 && mean(cost[j-1], steps[j-1]) < mean(best, bs) {
+					best, bs, bst = cost[j-1], steps[j-1], start[j-1]
+				}
+				cost[j], steps[j], start[j] = best+d, bs+1, bst
+			}
+		}
+		prevCost, cost = cost, prevCost
+		prevSteps, steps = steps, prevSteps
+		prevStart, start = start, prevStart
+	}
+	var matches []warpMatch
+	for j := 0; j < m; j++ {
+		c := mean(prevCost[j], prevSteps[j])
+		if c >= threshold {
+			continue
+		}
+		// Stretches much shorter than the template match too easily.
+		if j+1-prevStart[j] < n/2 {
+			continue
+		}
+		match := warpMatch{start: prevStart[j], end: j + 1, cost: c}
+		if k := len(matches) - 1; k >= 0 && matches[k].end > match.start {
+			if match.cost < matches[k].cost {
+				matches[k] = match
+			}
+			continue
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+func mean(sum float64, n int) float64 {
+	return sum / float64(n)
+}
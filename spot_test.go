@@ -0,0 +1,103 @@
+package gorec
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// wordsBackend hears "Hey gorec, turn on the lights" with timed words when
+// timed, recording the phrase hints it was sent.
+type wordsBackend struct {
+	timed bool
+	calls int
+	hints []string
+}
+
+func (b *wordsBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	b.calls++
+	b.hints = p.PhraseHints
+	alt := Alternative{Transcript: "Hey gorec, turn on the lights", Confidence: 0.8}
+	if b.timed {
+		for i, w := range []string{"Hey", "gorec,", "turn", "on", "the", "lights"} {
+			start := time.Duration(i) * 100 * time.Millisecond
+			alt.Words = append(alt.Words, Word{Word: w, Start: start, End: start + 80*time.Millisecond})
+		}
+	}
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{alt}, Final: true}}}, nil
+}
+
+func TestSpotTranscript(t *testing.T) {
+	audio := speechPCM(600*time.Millisecond, [2]time.Duration{0, 600 * time.Millisecond})
+	keywords := []string{"hey Gorec", "lights", "kitchen"}
+	b := &wordsBackend{timed: true}
+	c := NewClient("k", WithBackend(b), WithLanguages(English))
+	spots, err := c.Spot(context.Background(), audio, keywords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Spotting{
+		{Keyword: "hey Gorec", Start: 0, End: 180 * time.Millisecond, Confidence: 0.8},
+		{Keyword: "lights", Start: 500 * time.Millisecond, End: 580 * time.Millisecond, Confidence: 0.8},
+	}
+	if !reflect.DeepEqual(spots, want) {
+		t.Errorf("Spot = %+v, want %+v", spots, want)
+	}
+	if !reflect.DeepEqual(b.hints, keywords) {
+		t.Errorf("phrase hints = %q", b.hints)
+	}
+
+	// Without word timings the offsets are estimated.
+	spots, err = Spot(context.Background(), audio, []string{"lights"}, "k", WithBackend(&wordsBackend{}), WithLanguages(English))
+	if err != nil || len(spots) != 1 || spots[0].Start != 500*time.Millisecond || spots[0].End != 600*time.Millisecond {
+		t.Errorf("estimated Spot = %+v, %v", spots, err)
+	}
+}
+
+func TestSpotSilence(t *testing.T) {
+	b := &wordsBackend{}
+	spots, err := NewClient("k", WithBackend(b)).Spot(context.Background(), make([]byte, 3200), []string{"lights"})
+	if err != nil || spots != nil || b.calls != 0 {
+		t.Errorf("Spot of silence = %v, %v after %d requests", spots, err, b.calls)
+	}
+}
+
+// tones returns 16 kHz PCM of sines of the given frequencies, 0 for
+// silence, each lasting step, over faint noise.
+func tones(amplitude float64, step time.Duration, rng *rand.Rand, freqs ...float64) []byte {
+	n := int(step * 16000 / time.Second)
+	s := make([]float64, n*len(freqs))
+	for i := range s {
+		s[i] = 0.001 * rng.NormFloat64()
+		if f := freqs[i/n]; f > 0 {
+			s[i] += amplitude * math.Sin(2*math.Pi*f*float64(i)/16000)
+		}
+	}
+	return encodePCM(s)
+}
+
+func TestTemplateSpotter(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	step := 150 * time.Millisecond
+	s := &TemplateSpotter{}
+	if err := s.Add("wake", tones(0.3, step, rng, 500, 2000), 16000); err != nil {
+		t.Fatal(err)
+	}
+	// The keyword, louder, then the same tones the other way round.
+	audio := tones(0.6, step, rng, 0, 0, 500, 2000, 0, 0, 2000, 500, 0)
+	b := &wordsBackend{}
+	spots, err := NewClient("k", WithBackend(b), WithSpotter(s)).Spot(context.Background(), audio, []string{"wake", "sleep"})
+	if err != nil || len(spots) != 1 {
+		t.Fatalf("Spot = %+v, %v", spots, err)
+	}
+	if got := spots[0]; got.Keyword != "wake" || (got.Start-300*time.Millisecond).Abs() > 30*time.Millisecond || (got.End-600*time.Millisecond).Abs() > 30*time.Millisecond || got.Confidence < 0.8 {
+		t.Errorf("spotted %+v, want wake from 300ms to 600ms", got)
+	}
+	if b.calls != 0 {
+		t.Errorf("local spotting sent %d requests", b.calls)
+	}
+}
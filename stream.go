@@ -0,0 +1,146 @@
+package gorec
+
+import (
+	"context"
+	"errors"
+)
+
+// Stream recognizes audio written to it piece by piece, such as live
+// capture, one segment at a time. Each segment is as long as the Client's
+// WithMaxDuration, 15 seconds by default, and is recognized once written in
+// full; Close recognizes what is left. With WithInterim it also delivers
+// interim hypotheses of the segment being written. Write and Close must not
+// be called concurrently.
+type Stream struct {
+	c        *Client
+	ctx      context.Context
+	segment  int
+	buf      []byte
+	segments chan streamJob
+	results  chan Hypothesis
+	closed   bool
+
+	// interim is how many bytes a segment grows by between interim
+	// recognitions, and interimAt its length at the last one.
+	interim   int
+	interimAt int
+}
+
+// streamJob is audio for a Stream to recognize, a whole segment or, if
+// interim, the start of one.
+type streamJob struct {
+	audio   []byte
+	interim bool
+}
+
+// Stream starts a Stream. Its hypotheses must be received from Results
+// until that is closed, or writing eventually blocks. Cancelling ctx
+// abandons the segments not recognized yet and closes Results; otherwise
+// the Stream recognizes until Close is called.
+func (c *Client) Stream(ctx context.Context, opts ...Option) *Stream {
+	c = c.with(opts)
+	max := c.cfg.maxDuration
+	if max <= 0 {
+		max = defaultMaxReadDuration
+	}
+	s := &Stream{
+		c:        c,
+		ctx:      ctx,
+		segment:  bytesFor(max, c.sampleRate()),
+		segments: make(chan streamJob, 1),
+		results:  make(chan Hypothesis),
+		interim:  bytesFor(c.cfg.interim, c.sampleRate()),
+	}
+	go s.recognize()
+	return s
+}
+
+// Write buffers p, handing every segment it completes over for recognition,
+// and the segment so far if an interim recognition is due.
+func (s *Stream) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("Write on closed Stream")
+	}
+	if err := s.ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.buf = append(s.buf, p...)
+	for len(s.buf) >= s.segment {
+		if err := s.send(s.buf[:s.segment:s.segment]); err != nil {
+			return len(p), err
+		}
+		s.buf = append([]byte(nil), s.buf[s.segment:]...)
+		s.interimAt = 0
+	}
+	if s.interim > 0 && len(s.buf)-s.interimAt >= s.interim {
+		select {
+		case s.segments <- streamJob{audio: append([]byte(nil), s.buf...), interim: true}:
+			s.interimAt = len(s.buf)
+		default:
+			// Still busy: try again on the next Write.
+		}
+	}
+	return len(p), nil
+}
+
+// Close recognizes the audio written since the last segment, if any, and
+// closes Results once every segment has been delivered.
+func (s *Stream) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	var err error
+	if len(s.buf) > 0 {
+		err = s.send(s.buf)
+		s.buf = nil
+	}
+	close(s.segments)
+	return err
+}
+
+// Results delivers the hypothesis of every segment in order, those that
+// failed with their Err set, each preceded by its interim hypotheses, if
+// any, that succeeded.
+func (s *Stream) Results() <-chan Hypothesis {
+	return s.results
+}
+
+func (s *Stream) send(segment []byte) error {
+	select {
+	case s.segments <- streamJob{audio: segment}:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+func (s *Stream) recognize() {
+	defer close(s.results)
+	for {
+		var job streamJob
+		select {
+		case j, ok := <-s.segments:
+			if !ok {
+				return
+			}
+			job = j
+		case <-s.ctx.Done():
+			return
+		}
+		h, err := s.c.listen(s.ctx, job.audio)
+		if job.interim {
+			if err != nil {
+				continue
+			}
+			h.Interim = true
+		} else if err != nil {
+			h = &Hypothesis{Err: err}
+		}
+		select {
+		case s.results <- *h:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
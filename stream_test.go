@@ -0,0 +1,48 @@
+package gorec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+// lengthBackend transcribes audio as its size in bytes.
+type lengthBackend struct{}
+
+func (lengthBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{{Transcript: fmt.Sprint(size), Confidence: 0.9}}, Final: true}}}, nil
+}
+
+func TestStreamInterim(t *testing.T) {
+	c := NewClient("k", WithBackend(lengthBackend{}), WithLanguages(English),
+		WithMaxDuration(100*time.Millisecond), WithInterim(25*time.Millisecond))
+	s := c.Stream(context.Background())
+	piece := make([]byte, bytesFor(25*time.Millisecond, defaultSampleRate))
+	for i, want := range []struct {
+		transcript string
+		interim    bool
+	}{
+		{"800", true},
+		{"1600", true},
+		{"2400", true},
+		{"3200", false},
+		{"800", true},
+	} {
+		if _, err := s.Write(piece); err != nil {
+			t.Fatal(err)
+		}
+		h := <-s.Results()
+		if h.Err != nil || h.Alternative.Transcript != want.transcript || h.Interim != want.interim {
+			t.Errorf("write %d: got %q interim %v (%v), want %q interim %v", i, h.Alternative.Transcript, h.Interim, h.Err, want.transcript, want.interim)
+		}
+	}
+	s.Close()
+	if h := <-s.Results(); h.Alternative.Transcript != "800" || h.Interim {
+		t.Errorf("Close delivered %q interim %v, want the final 800", h.Alternative.Transcript, h.Interim)
+	}
+	if _, ok := <-s.Results(); ok {
+		t.Error("Results still open after the last segment")
+	}
+}
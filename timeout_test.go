@@ -0,0 +1,89 @@
+package gorec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// clockBackend answers Spanish straight away, after advancing clk by
+// latency, and stalls the other languages until their request is cancelled.
+type clockBackend struct {
+	clk     *fakeClock
+	latency time.Duration
+	started chan struct{}
+	once    sync.Once
+}
+
+func (b *clockBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	if lang != Spanish {
+		b.once.Do(func() { close(b.started) })
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	b.clk.Advance(b.latency)
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{{Transcript: "hola", Confidence: 0.8}}, Final: true}}}, nil
+}
+
+func TestTimeoutWithClock(t *testing.T) {
+	clk := newFakeClock()
+	b := &clockBackend{clk: clk, started: make(chan struct{})}
+	c := NewClient("k", WithBackend(b), withClock(clk))
+	defer c.Close()
+	heard := make(chan struct{})
+	done := make(chan error, 1)
+	var got []Hypothesis
+	go func() {
+		done <- c.ListenFileFunc([]byte{1, 2}, func(h Hypothesis) {
+			got = append(got, h)
+			close(heard)
+		})
+	}()
+	<-b.started
+	<-heard
+	clk.Advance(defaultTimeout)
+	select {
+	case err := <-done:
+		if err != nil || len(got) != 1 || got[0].Language != Spanish {
+			t.Errorf("ListenFileFunc = %v after %d hypotheses", err, len(got))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ListenFileFunc outlived its timeout on the fake clock")
+	}
+}
+
+func TestLatencyWithClock(t *testing.T) {
+	clk := newFakeClock()
+	b := &clockBackend{clk: clk, latency: 250 * time.Millisecond}
+	h, err := Recognize([]byte{1, 2}, "k", Spanish, WithBackend(b), withClock(clk))
+	if err != nil || h.Latency != 250*time.Millisecond {
+		t.Fatalf("Recognize = %v, %v, want a 250ms latency", h, err)
+	}
+}
+
+func TestWithTimeoutPartial(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("lang") != "es-es" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(500 * time.Millisecond):
+			}
+			return
+		}
+		fmt.Fprint(w, `{"result":[{"alternative":[{"transcript":"hola","confidence":0.8}],"final":true}]}`)
+	}))
+	defer srv.Close()
+	eps := map[Language]string{}
+	for _, l := range SupportedLanguages() {
+		eps[l] = srv.URL + "/?lang=%s&key=%s"
+	}
+	h, err := New("k", WithLanguageEndpoint(eps), WithTimeout(200*time.Millisecond)).ListenFile([]byte{1, 2})
+	if err != nil || h.Language != Spanish || !h.Partial {
+		t.Fatal(h, err)
+	}
+}
@@ -0,0 +1,37 @@
+package gorec
+
+import "context"
+
+// Tracer starts the spans of a recognition, for distributed tracing: one
+// "gorec.listen" span per call, a "gorec.language" span under it per
+// language probed and a "gorec.request" span under that per request sent,
+// retries included. Attributes alternate keys and values as with slog. An
+// adapter to OpenTelemetry's trace.Tracer takes a few lines.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...any) (context.Context, TraceSpan)
+}
+
+// TraceSpan is a span started by a Tracer.
+type TraceSpan interface {
+	SetAttributes(attrs ...any)
+
+	// End ends the span, recording err as its failure if not nil.
+	End(err error)
+}
+
+// WithTracer traces every recognition with t.
+func WithTracer(t Tracer) Option {
+	return func(c *config) { c.tracer = t }
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(attrs ...any) {}
+func (nopSpan) End(err error)              {}
+
+func (c *Client) startSpan(ctx context.Context, name string, attrs ...any) (context.Context, TraceSpan) {
+	if c.cfg.tracer == nil {
+		return ctx, nopSpan{}
+	}
+	return c.cfg.tracer.Start(ctx, name, attrs...)
+}
@@ -0,0 +1,82 @@
+package gorec
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+type spanKey struct{}
+
+// recordingTracer records its spans with the name of their parent.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	tracer *recordingTracer
+	name   string
+	parent string
+	attrs  []any
+	ended  bool
+	err    error
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...any) (context.Context, TraceSpan) {
+	s := &recordedSpan{tracer: t, name: name, attrs: attrs}
+	if parent, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		s.parent = parent.name
+	}
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...any) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+func (s *recordedSpan) End(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.ended, s.err = true, err
+}
+
+func TestTracer(t *testing.T) {
+	srv, eps := newLanguageServer(map[string]string{
+		"fr-fr": `{"result":[{"alternative":[{"transcript":"bonjour","confidence":0.9}],"final":true}]}`,
+	})
+	defer srv.Close()
+	tr := &recordingTracer{}
+	if _, err := ListenFile([]byte{1, 2}, "k", WithLanguageEndpoint(eps), WithLanguages(French, Spanish), WithTracer(tr)); err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int{}
+	for _, s := range tr.spans {
+		counts[s.name+" < "+s.parent]++
+		if !s.ended {
+			t.Errorf("%s not ended", s.name)
+		}
+		attrs := fmt.Sprint(s.attrs...)
+		switch {
+		case s.name == "gorec.language" && attrs == fmt.Sprint("language", "fr-fr", "confidence", 0.9):
+			counts["confident french"]++
+		case s.name == "gorec.language" && s.err != ErrNoSpeech:
+			t.Errorf("%s %v ended with %v", s.name, attrs, s.err)
+		}
+	}
+	want := map[string]int{
+		"gorec.listen < ":                1,
+		"gorec.language < gorec.listen":  2,
+		"gorec.request < gorec.language": 2,
+		"confident french":               1,
+	}
+	if fmt.Sprint(counts) != fmt.Sprint(want) {
+		t.Errorf("spans %v, want %v", counts, want)
+	}
+}
@@ -0,0 +1,165 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Translator translates text spoken in one language into another, such as
+// GoogleTranslator or DeepLTranslator.
+type Translator interface {
+	Translate(ctx context.Context, text string, from, to Language) (string, error)
+}
+
+// WithTranslation translates the transcript of the best hypothesis into
+// to with t, setting its Translated. Transcripts already in to's base
+// language are kept as they are. A failed translation fails the
+// recognition.
+func WithTranslation(t Translator, to Language) Option {
+	return func(c *config) { c.translator, c.translateTo = t, to }
+}
+
+// translate sets h.Translated, if the Client translates.
+func (c *Client) translate(ctx context.Context, h *Hypothesis) error {
+	if c.cfg.translator == nil {
+		return nil
+	}
+	if baseCode(h.Language.StringCode()) == baseCode(c.cfg.translateTo.StringCode()) {
+		h.Translated = h.Alternative.Transcript
+		return nil
+	}
+	translated, err := c.cfg.translator.Translate(ctx, h.Alternative.Transcript, h.Language, c.cfg.translateTo)
+	if err != nil {
+		return fmt.Errorf("Translating transcript: %w", err)
+	}
+	h.Translated = translated
+	return nil
+}
+
+// GoogleTranslateEndpoint is the Cloud Translation v2 REST API.
+const GoogleTranslateEndpoint = "https://translation.googleapis.com/language/translate/v2"
+
+// GoogleTranslator is a Translator using Google's Cloud Translation API
+// with the API key Key.
+type GoogleTranslator struct {
+	Key string
+
+	// Endpoint defaults to GoogleTranslateEndpoint and HTTPClient to a
+	// default client.
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+func (g *GoogleTranslator) Translate(ctx context.Context, text string, from, to Language) (string, error) {
+	body, _ := json.Marshal(map[string]string{
+		"q":      text,
+		"source": baseCode(from.StringCode()),
+		"target": baseCode(to.StringCode()),
+		"format": "text",
+	})
+	endpoint := g.Endpoint
+	if endpoint == "" {
+		endpoint = GoogleTranslateEndpoint
+	}
+	var resp struct {
+		Data struct {
+			Translations []struct {
+				TranslatedText string `json:"translatedText"`
+			} `json:"translations"`
+		} `json:"data"`
+	}
+	if err := postJSON(ctx, g.HTTPClient, endpoint+"?"+url.Values{"key": {g.Key}}.Encode(), http.Header{}, body, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Data.Translations) == 0 {
+		return "", errors.New("No translation returned")
+	}
+	return resp.Data.Translations[0].TranslatedText, nil
+}
+
+// DeepLEndpoint is the translation method of DeepL's free API; paid plans
+// use https://api.deepl.com/v2/translate.
+const DeepLEndpoint = "https://api-free.deepl.com/v2/translate"
+
+// DeepLTranslator is a Translator using DeepL with the authentication key
+// Key.
+type DeepLTranslator struct {
+	Key string
+
+	// Endpoint defaults to DeepLEndpoint and HTTPClient to a default
+	// client.
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+func (d *DeepLTranslator) Translate(ctx context.Context, text string, from, to Language) (string, error) {
+	body, _ := json.Marshal(map[string]any{
+		"text":        []string{text},
+		"source_lang": strings.ToUpper(baseCode(from.StringCode())),
+		"target_lang": deepLTarget(to),
+	})
+	endpoint := d.Endpoint
+	if endpoint == "" {
+		endpoint = DeepLEndpoint
+	}
+	var resp struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+	header := http.Header{"Authorization": {"DeepL-Auth-Key " + d.Key}}
+	if err := postJSON(ctx, d.HTTPClient, endpoint, header, body, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Translations) == 0 {
+		return "", errors.New("No translation returned")
+	}
+	return resp.Translations[0].Text, nil
+}
+
+// deepLTarget names to as DeepL does: by base language, but for the
+// variants of English and Portuguese it tells apart.
+func deepLTarget(to Language) string {
+	code := strings.ToUpper(to.Code())
+	switch code {
+	case "EN-GB", "EN-US", "PT-BR", "PT-PT":
+		return code
+	}
+	return strings.ToUpper(baseCode(to.StringCode()))
+}
+
+// postJSON posts body to endpoint, decoding the JSON response into out.
+func postJSON(ctx context.Context, client *http.Client, endpoint string, header http.Header, body []byte, out any) error {
+	r, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header = header
+	r.Header.Set("Content-Type", "application/json")
+	if client == nil {
+		client = sharedClient
+	}
+	resp, err := client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("Reading response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return newAPIError(resp.StatusCode, respBody)
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("Decoding response: %w", err)
+	}
+	return nil
+}
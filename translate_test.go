@@ -0,0 +1,80 @@
+package gorec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// upperTranslator "translates" by prefixing the target language's code.
+type upperTranslator struct{ calls int }
+
+func (u *upperTranslator) Translate(ctx context.Context, text string, from, to Language) (string, error) {
+	u.calls++
+	if text == "fail" {
+		return "", errors.New("Translation failed")
+	}
+	return to.StringCode() + ": " + text, nil
+}
+
+func TestWithTranslation(t *testing.T) {
+	tr := &upperTranslator{}
+	h, err := ListenFile([]byte("bonjour"), "k", WithBackend(&echoBackend{}), WithLanguages(French), WithTranslation(tr, English))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Alternative.Transcript != "bonjour" || h.Translated != "en-gb: bonjour" {
+		t.Errorf("hypothesis %+v", h)
+	}
+
+	h, err = ListenFile([]byte("hello"), "k", WithBackend(&echoBackend{}), WithLanguages(English), WithTranslation(tr, English))
+	if err != nil || h.Translated != "hello" || tr.calls != 1 {
+		t.Errorf("translated English into English: %+v, %v, %d calls", h, err, tr.calls)
+	}
+
+	if _, err := ListenFile([]byte("fail"), "k", WithBackend(&echoBackend{}), WithLanguages(French), WithTranslation(tr, English)); err == nil {
+		t.Error("failed translation returned no error")
+	}
+}
+
+func TestGoogleTranslator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Query().Get("key") != "gk" || req["q"] != "bonjour" || req["source"] != "fr" || req["target"] != "en" {
+			t.Errorf("request %s %v", r.URL, req)
+		}
+		fmt.Fprint(w, `{"data": {"translations": [{"translatedText": "hello"}]}}`)
+	}))
+	defer srv.Close()
+
+	g := &GoogleTranslator{Key: "gk", Endpoint: srv.URL}
+	if got, err := g.Translate(context.Background(), "bonjour", French, English); err != nil || got != "hello" {
+		t.Errorf("Translate = %q, %v", got, err)
+	}
+}
+
+func TestDeepLTranslator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Text       []string `json:"text"`
+			SourceLang string   `json:"source_lang"`
+			TargetLang string   `json:"target_lang"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.Header.Get("Authorization") != "DeepL-Auth-Key dk" || req.SourceLang != "FR" || req.TargetLang != "EN-GB" || req.Text[0] != "bonjour" {
+			t.Errorf("request %v %+v", r.Header, req)
+		}
+		fmt.Fprint(w, `{"translations": [{"detected_source_language": "FR", "text": "hello"}]}`)
+	}))
+	defer srv.Close()
+
+	d := &DeepLTranslator{Key: "dk", Endpoint: srv.URL}
+	if got, err := d.Translate(context.Background(), "bonjour", French, English); err != nil || got != "hello" {
+		t.Errorf("Translate = %q, %v", got, err)
+	}
+}
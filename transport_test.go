@@ -0,0 +1,109 @@
+package gorec
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+func TestInsecureSkipVerify(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":[{"alternative":[{"transcript":"hi","confidence":0.8}],"final":true}]}`)
+	}))
+	defer srv.Close()
+	ep := map[Language]string{English: srv.URL + "/?lang=%s&key=%s"}
+	if _, err := Recognize([]byte{1, 2}, "k", English, WithLanguageEndpoint(ep)); err == nil {
+		t.Fatal("Recognize trusted an unknown certificate")
+	}
+	c := NewClient("k", WithLanguageEndpoint(ep), WithInsecureSkipVerify(true))
+	defer c.Close()
+	if _, err := c.Recognize([]byte{1, 2}, English); err != nil {
+		t.Fatal(err)
+	}
+	pool := srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	if _, err := c.Recognize([]byte{1, 2}, English, WithInsecureSkipVerify(false), WithRootCAs(pool)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPerCallTransport(t *testing.T) {
+	c := NewClient("k", WithInsecureSkipVerify(true))
+	defer c.Close()
+	if got := c.with([]Option{WithTimeout(0)}); got.cfg.transport != c.cfg.transport {
+		t.Error("a call without transport options built its own transport")
+	}
+	proxy, _ := url.Parse("http://127.0.0.1:3128")
+	got := c.with([]Option{WithProxy(proxy)})
+	if got.cfg.transport == c.cfg.transport || !got.cfg.transport.DisableKeepAlives {
+		t.Error("a call with WithProxy shares the Client's transport or keeps idle connections")
+	}
+	if c.cfg.transport.DisableKeepAlives {
+		t.Error("the Client's own transport doesn't keep connections alive")
+	}
+}
+
+func TestConnectionReuse(t *testing.T) {
+	var mu sync.Mutex
+	conns := 0
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":[{"alternative":[{"transcript":"hi","confidence":0.8}],"final":true}]}`)
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+	eps := map[Language]string{}
+	for _, l := range SupportedLanguages() {
+		eps[l] = srv.URL + "/?lang=%s&key=%s"
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := ListenFile([]byte{1, 2}, "k", WithLanguageEndpoint(eps)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if conns > len(SupportedLanguages()) {
+		t.Errorf("3 recognitions of %d languages opened %d connections", len(SupportedLanguages()), conns)
+	}
+}
+
+func TestHTTP2(t *testing.T) {
+	var mu sync.Mutex
+	var protos []string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		protos = append(protos, r.Proto)
+		mu.Unlock()
+		fmt.Fprint(w, `{"result":[{"alternative":[{"transcript":"hi","confidence":0.8}],"final":true}]}`)
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+	eps := map[Language]string{}
+	for _, l := range SupportedLanguages() {
+		eps[l] = srv.URL + "/?lang=%s&key=%s"
+	}
+	pool := srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	c := NewClient("k", WithLanguageEndpoint(eps), WithRootCAs(pool))
+	defer c.Close()
+	if _, err := c.ListenFile([]byte{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, proto := range protos {
+		if proto != "HTTP/2.0" {
+			t.Errorf("request sent over %s", proto)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+)
+
+// defaultTwoPassTop is how many languages the second pass of WithTwoPass
+// queries unless told otherwise.
+const defaultTwoPassTop = 2
+
+// WithTwoPass saves quota when querying many languages: the first prefix of
+// the audio is sent to every language, and the whole of it only to the top
+// languages most confident about the prefix, two if top is zero. If no
+// language hears anything in the prefix, the whole audio goes to all of
+// them. It applies to linear PCM given as byte slices longer than prefix.
+func WithTwoPass(prefix time.Duration, top int) Option {
+	return func(c *config) { c.twoPassPrefix, c.twoPassTop = prefix, top }
+}
+
+// listenPrepared is listenBest for prepared audio, in two passes if the
+// Client is set to.
+func (c *Client) listenPrepared(ctx context.Context, audio []byte) (*Hypothesis, error) {
+	top := c.cfg.twoPassTop
+	if top <= 0 {
+		top = defaultTwoPassTop
+	}
+	prefix := bytesFor(c.cfg.twoPassPrefix, c.sampleRate())
+	if prefix <= 0 || !isL16(c.cfg.contentType) || len(audio) <= prefix || len(c.languages()) <= top {
+		return c.listenBest(ctx, bytes.NewReader(audio), int64(len(audio)))
+	}
+	hs, err := c.listenAll(ctx, bytes.NewReader(audio[:prefix]), int64(prefix), nil)
+	if err != nil {
+		return nil, err
+	}
+	if candidates := c.topLanguages(hs, top); len(candidates) > 0 {
+		c = c.with([]Option{WithLanguages(candidates...)})
+	}
+	return c.listenBest(ctx, bytes.NewReader(audio), int64(len(audio)))
+}
+
+// topLanguages returns the languages of the n best selectable hypotheses in
+// hs, best first.
+func (c *Client) topLanguages(hs []Hypothesis, n int) []Language {
+	var selectable []Hypothesis
+	for _, h := range hs {
+		if c.selectable(h) {
+			selectable = append(selectable, h)
+		}
+	}
+	sort.SliceStable(selectable, func(i, j int) bool {
+		return c.score(selectable[i].Alternative, selectable[i].Language) > c.score(selectable[j].Alternative, selectable[j].Language)
+	})
+	var langs []Language
+	for i := 0; i < len(selectable) && i < n; i++ {
+		langs = append(langs, selectable[i].Language)
+	}
+	return langs
+}
@@ -0,0 +1,61 @@
+package gorec
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sizeBackend records the size of the audio each language got, answering
+// with a confidence of its own per language.
+type sizeBackend struct {
+	confidence map[Language]float64
+
+	mu    sync.Mutex
+	sizes map[Language][]int64
+}
+
+func (b *sizeBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	b.mu.Lock()
+	b.sizes[lang] = append(b.sizes[lang], size)
+	b.mu.Unlock()
+	conf, ok := b.confidence[lang]
+	if !ok {
+		return &GoogleResponse{}, nil
+	}
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{{Transcript: lang.StringCode(), Confidence: conf}}, Final: true}}}, nil
+}
+
+func TestTwoPass(t *testing.T) {
+	b := &sizeBackend{confidence: map[Language]float64{French: 0.9, Spanish: 0.6, English: 0.3}, sizes: map[Language][]int64{}}
+	audio := make([]byte, bytesFor(3*time.Second, defaultSampleRate))
+	c := NewClient("k", WithBackend(b), WithLanguages(English, French, Spanish, German), WithTwoPass(time.Second, 2))
+	h, err := c.ListenFile(audio)
+	if err != nil || h.Language != French {
+		t.Fatalf("ListenFile = %v, %v", h, err)
+	}
+	prefix, full := int64(bytesFor(time.Second, defaultSampleRate)), int64(len(audio))
+	want := map[Language][]int64{English: {prefix}, French: {prefix, full}, Spanish: {prefix, full}, German: {prefix}}
+	for lang, sizes := range want {
+		got := b.sizes[lang]
+		sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+		if len(got) != len(sizes) || got[0] != sizes[0] || got[len(got)-1] != sizes[len(sizes)-1] {
+			t.Errorf("%v got %v bytes, want %v", lang, got, sizes)
+		}
+	}
+
+	// Nothing heard in the prefix sends the whole audio everywhere.
+	silent := &sizeBackend{confidence: map[Language]float64{}, sizes: map[Language][]int64{}}
+	c = NewClient("k", WithBackend(silent), WithLanguages(English, French, Spanish), WithTwoPass(time.Second, 1))
+	if _, err := c.ListenFile(audio); err == nil {
+		t.Fatal("ListenFile of silence succeeded")
+	}
+	for _, lang := range []Language{English, French, Spanish} {
+		if got := silent.sizes[lang]; len(got) != 2 {
+			t.Errorf("%v got %v bytes, want the prefix and the whole", lang, got)
+		}
+	}
+}
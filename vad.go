@@ -0,0 +1,140 @@
+package gorec
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// VAD is an energy-based voice activity detector for 16-bit little-endian
+// mono PCM. It measures the loudness of the audio frame by frame and counts
+// a frame as speech when it is above a threshold set by Aggressiveness.
+type VAD struct {
+	// SampleRate is the rate of the PCM, 16 kHz if zero.
+	SampleRate int
+
+	// Aggressiveness, from 0 to 3, raises the loudness a frame needs to
+	// count as speech. Noisy recordings need a higher one.
+	Aggressiveness int
+
+	// Frame is the length loudness is measured over, 30 ms if zero.
+	Frame time.Duration
+}
+
+// vadThresholds are the RMS sample values a frame must reach to count as
+// speech, by aggressiveness.
+var vadThresholds = [...]float64{150, 300, 600, 1200}
+
+const defaultVADFrame = 30 * time.Millisecond
+
+// Span is a stretch of speech a VAD found, with its offsets from the start
+// of the audio it was found in.
+type Span struct {
+	Start time.Duration
+	End   time.Duration
+	Audio []byte
+}
+
+// Trim removes the leading and trailing frames of pcm that aren't speech.
+// Audio without any speech trims to nothing.
+func (v VAD) Trim(pcm []byte) []byte {
+	voiced, frame := v.frames(pcm)
+	first, last := -1, -1
+	for i, speech := range voiced {
+		if speech {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return pcm[:0]
+	}
+	return pcm[first*frame : min((last+1)*frame, len(pcm))]
+}
+
+// Split cuts pcm on every pause of at least gap, returning the spans of
+// speech between them in order. Shorter pauses are kept within a span.
+func (v VAD) Split(pcm []byte, gap time.Duration) []Span {
+	voiced, frame := v.frames(pcm)
+	minGap := int(math.Ceil(float64(gap) / float64(v.frame())))
+	if minGap < 1 {
+		minGap = 1
+	}
+	var spans []Span
+	start, silent := -1, 0
+	flush := func(end int) {
+		spans = append(spans, v.span(pcm, start*frame, min(end*frame, len(pcm))))
+		start = -1
+	}
+	for i, speech := range voiced {
+		switch {
+		case speech:
+			if start < 0 {
+				start = i
+			}
+			silent = 0
+		case start >= 0:
+			silent++
+			if silent >= minGap {
+				flush(i - silent + 1)
+			}
+		}
+	}
+	if start >= 0 {
+		flush(len(voiced) - silent)
+	}
+	return spans
+}
+
+func (v VAD) span(pcm []byte, from, to int) Span {
+	return Span{
+		Start: DurationOf(pcm[:from], v.sampleRate(), 1, 16),
+		End:   DurationOf(pcm[:to], v.sampleRate(), 1, 16),
+		Audio: pcm[from:to],
+	}
+}
+
+// frames reports which frames of pcm are speech, along with the length of
+// a frame in bytes.
+func (v VAD) frames(pcm []byte) ([]bool, int) {
+	frame := bytesFor(v.frame(), v.sampleRate())
+	if frame < 2 {
+		frame = 2
+	}
+	threshold := vadThresholds[max(0, min(v.Aggressiveness, len(vadThresholds)-1))]
+	voiced := make([]bool, 0, (len(pcm)+frame-1)/frame)
+	for off := 0; off+1 < len(pcm); off += frame {
+		voiced = append(voiced, rms(pcm[off:min(off+frame, len(pcm))]) >= threshold)
+	}
+	return voiced, frame
+}
+
+func (v VAD) sampleRate() int {
+	if v.SampleRate > 0 {
+		return v.SampleRate
+	}
+	return defaultSampleRate
+}
+
+func (v VAD) frame() time.Duration {
+	if v.Frame > 0 {
+		return v.Frame
+	}
+	return defaultVADFrame
+}
+
+// rms returns the root mean square of the 16-bit samples in pcm.
+func rms(pcm []byte) float64 {
+	n := len(pcm) / 2
+	if n == 0 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		s := float64(int16(binary.LittleEndian.Uint16(pcm[2*i:])))
+		sum += s * s
+	}
+	return math.Sqrt(sum / float64(n))
+}
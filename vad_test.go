@@ -0,0 +1,63 @@
+package gorec
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// speechPCM returns 16 kHz PCM of total length with a square wave of amplitude 1000
+// during each of the given [start, end) intervals and silence elsewhere.
+func speechPCM(total time.Duration, loud ...[2]time.Duration) []byte {
+	pcm := make([]byte, bytesFor(total, defaultSampleRate))
+	for _, l := range loud {
+		for i := bytesFor(l[0], defaultSampleRate); i < bytesFor(l[1], defaultSampleRate); i += 2 {
+			v := int16(1000)
+			if i/2%2 == 0 {
+				v = -v
+			}
+			binary.LittleEndian.PutUint16(pcm[i:], uint16(v))
+		}
+	}
+	return pcm
+}
+
+func TestVADTrim(t *testing.T) {
+	pcm := speechPCM(time.Second, [2]time.Duration{300 * time.Millisecond, 600 * time.Millisecond})
+	if got := DurationOf(VAD{}.Trim(pcm), defaultSampleRate, 1, 16); got != 300*time.Millisecond {
+		t.Errorf("Trim kept %v, want 300ms", got)
+	}
+	if got := len(VAD{}.Trim(make([]byte, 3200))); got != 0 {
+		t.Errorf("Trim of silence kept %d bytes", got)
+	}
+	if got := len(VAD{Aggressiveness: 3}.Trim(pcm)); got != 0 {
+		t.Errorf("the most aggressive VAD heard speech in a quiet tone, kept %d bytes", got)
+	}
+}
+
+func TestVADSplit(t *testing.T) {
+	pcm := speechPCM(2*time.Second,
+		[2]time.Duration{0, 300 * time.Millisecond},
+		[2]time.Duration{360 * time.Millisecond, 600 * time.Millisecond},
+		[2]time.Duration{1200 * time.Millisecond, 1500 * time.Millisecond})
+	spans := VAD{}.Split(pcm, 300*time.Millisecond)
+	if len(spans) != 2 {
+		t.Fatalf("Split found %d spans, want 2", len(spans))
+	}
+	for i, want := range [][2]time.Duration{{0, 600 * time.Millisecond}, {1200 * time.Millisecond, 1500 * time.Millisecond}} {
+		s := spans[i]
+		if s.Start != want[0] || s.End != want[1] || DurationOf(s.Audio, defaultSampleRate, 1, 16) != s.End-s.Start {
+			t.Errorf("span %d = %v-%v with %d bytes, want %v-%v", i, s.Start, s.End, len(s.Audio), want[0], want[1])
+		}
+	}
+}
+
+func TestWithVAD(t *testing.T) {
+	pcm := speechPCM(time.Second, [2]time.Duration{300 * time.Millisecond, 600 * time.Millisecond})
+	c := NewClient("k", WithVAD(VAD{}))
+	defer c.Close()
+	_, audio, err := c.prepare(pcm)
+	if err != nil || len(audio) != bytesFor(300*time.Millisecond, defaultSampleRate) {
+		t.Errorf("prepare = %d bytes, %v", len(audio), err)
+	}
+}
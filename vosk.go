@@ -0,0 +1,145 @@
+package gorec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/carlescere/gorec/internal/websocket"
+)
+
+// voskChunk is how much audio VoskBackend sends per message, as vosk-server
+// clients conventionally do.
+const voskChunk = 8000
+
+// voskMaxMessage bounds the results vosk-server sends back.
+const voskMaxMessage = 1 << 20
+
+// VoskBackend is a Backend for Vosk, recognizing speech offline with no API
+// key through vosk-server's WebSocket API. A vosk-server loads the model of
+// a single language, so Servers maps each language installed to the ws://
+// URL of its server. Languages it lacks are recognized by Fallback, such as
+// GoogleBackend(key), and fail when Fallback is nil.
+//
+// Vosk takes linear PCM only. It reports one transcript per utterance with
+// no alternatives; their words carry the confidence, averaged into the
+// hypothesis's.
+type VoskBackend struct {
+	Servers  map[Language]string
+	Fallback Backend
+}
+
+type voskResult struct {
+	Text   string `json:"text"`
+	Result []struct {
+		Conf  float64 `json:"conf"`
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Word  string  `json:"word"`
+	} `json:"result"`
+}
+
+func (b *VoskBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, params BackendParams) (*GoogleResponse, error) {
+	url, ok := b.Servers[lang]
+	if !ok {
+		if b.Fallback == nil {
+			return nil, fmt.Errorf("No Vosk model installed for %s", lang.StringCode())
+		}
+		return b.Fallback.Recognize(ctx, audio, size, lang, params)
+	}
+	if !isL16(params.ContentType) {
+		return nil, fmt.Errorf("%w: Vosk needs linear PCM, not %s", ErrUnsupportedFormat, params.ContentType)
+	}
+	rate := contentTypeRate(params.ContentType)
+	if rate <= 0 {
+		rate = defaultSampleRate
+	}
+
+	conn, err := websocket.Dial(ctx, url, voskMaxMessage)
+	if err != nil {
+		return nil, fmt.Errorf("Connecting to Vosk: %w", err)
+	}
+	defer conn.Close()
+	cfg, _ := json.Marshal(map[string]any{"config": map[string]any{"sample_rate": rate, "words": 1}})
+	if err := conn.WriteMessage(websocket.OpText, cfg); err != nil {
+		return nil, err
+	}
+	var results []voskResult
+	buf := make([]byte, voskChunk)
+	for {
+		n, rerr := io.ReadFull(audio, buf)
+		if n > 0 {
+			if err := conn.WriteMessage(websocket.OpBinary, buf[:n]); err != nil {
+				return nil, err
+			}
+			// Every chunk is answered with a partial result, or a final
+			// one when it ends an utterance.
+			if results, err = readVosk(conn, results); err != nil {
+				return nil, err
+			}
+		}
+		if errors.Is(rerr, io.EOF) || errors.Is(rerr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if rerr != nil {
+			return nil, rerr
+		}
+	}
+	if err := conn.WriteMessage(websocket.OpText, []byte(`{"eof" : 1}`)); err != nil {
+		return nil, err
+	}
+	if results, err = readVosk(conn, results); err != nil {
+		return nil, err
+	}
+	conn.WriteClose(websocket.CloseNormal, "")
+	return voskResponse(results), nil
+}
+
+// readVosk reads the answer to the last message sent, appending it to
+// results when it is a final result.
+func readVosk(conn *websocket.Conn, results []voskResult) ([]voskResult, error) {
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		return results, fmt.Errorf("Reading Vosk result: %w", err)
+	}
+	var res voskResult
+	if err := json.Unmarshal(msg, &res); err != nil {
+		return results, fmt.Errorf("Decoding Vosk result: %w", err)
+	}
+	if res.Text != "" {
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// voskResponse joins the utterances of results into a single final result.
+func voskResponse(results []voskResult) *GoogleResponse {
+	gr := &GoogleResponse{}
+	if len(results) == 0 {
+		return gr
+	}
+	var texts []string
+	var alt Alternative
+	for _, res := range results {
+		texts = append(texts, res.Text)
+		for _, w := range res.Result {
+			alt.Words = append(alt.Words, Word{
+				Word:       w.Word,
+				Start:      time.Duration(w.Start * float64(time.Second)),
+				End:        time.Duration(w.End * float64(time.Second)),
+				Confidence: w.Conf,
+			})
+			alt.Confidence += w.Conf
+		}
+	}
+	alt.Transcript = strings.Join(texts, " ")
+	if len(alt.Words) > 0 {
+		alt.Confidence /= float64(len(alt.Words))
+	}
+	gr.Results = []Result{{Final: true, Alternatives: []Alternative{alt}}}
+	return gr
+}
@@ -0,0 +1,93 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carlescere/gorec/internal/websocket"
+)
+
+// newVoskServer mimics vosk-server, finishing an utterance every two
+// chunks of audio.
+func newVoskServer(t *testing.T, received *bytes.Buffer) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r, 1<<20)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		_, msg, err := conn.ReadMessage()
+		if err != nil || string(msg) != `{"config":{"sample_rate":8000,"words":1}}` {
+			t.Errorf("config %s, %v", msg, err)
+			return
+		}
+		for chunks := 1; ; chunks++ {
+			op, msg, err := conn.ReadMessage()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			switch {
+			case op == websocket.OpText:
+				conn.WriteMessage(websocket.OpText, []byte(`{"text": "world", "result": [{"conf": 0.5, "start": 1.5, "end": 2, "word": "world"}]}`))
+				return
+			case chunks == 2:
+				conn.WriteMessage(websocket.OpText, []byte(`{"text": "hello there", "result": [{"conf": 1, "start": 0, "end": 0.5, "word": "hello"}, {"conf": 0.9, "start": 0.5, "end": 1, "word": "there"}]}`))
+			default:
+				conn.WriteMessage(websocket.OpText, []byte(`{"partial": "hello"}`))
+			}
+			received.Write(msg)
+		}
+	}))
+}
+
+func TestVoskBackend(t *testing.T) {
+	var received bytes.Buffer
+	srv := newVoskServer(t, &received)
+	defer srv.Close()
+	pcm := bytes.Repeat([]byte{1, 2}, 10000)
+	b := &VoskBackend{Servers: map[Language]string{English: "ws" + strings.TrimPrefix(srv.URL, "http")}}
+
+	h, err := ListenFile(pcm, "", WithBackend(b), WithLanguages(English), WithSampleRate(8000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(received.Bytes(), pcm) {
+		t.Errorf("received %d bytes, want %d", received.Len(), len(pcm))
+	}
+	if h.Alternative.Transcript != "hello there world" || h.Alternative.Confidence < 0.79 || h.Alternative.Confidence > 0.81 {
+		t.Errorf("hypothesis %v", h)
+	}
+	if words := h.Alternative.Words; len(words) != 3 || words[2].Start != 1500*time.Millisecond || words[2].End != 2*time.Second {
+		t.Errorf("Words = %v", words)
+	}
+}
+
+func TestVoskBackendFallback(t *testing.T) {
+	b := &VoskBackend{}
+	_, err := b.Recognize(context.Background(), strings.NewReader("bonjour"), 7, French, BackendParams{ContentType: "audio/l16; rate=16000"})
+	if err == nil {
+		t.Error("Recognized with no model and no fallback")
+	}
+
+	b.Fallback = &echoBackend{}
+	gr, err := b.Recognize(context.Background(), strings.NewReader("bonjour"), 7, French, BackendParams{ContentType: "audio/l16; rate=16000"})
+	if err != nil || gr.Results[0].Alternatives[0].Transcript != "bonjour" {
+		t.Errorf("fallback got %v, %v", gr, err)
+	}
+}
+
+func TestVoskBackendFormat(t *testing.T) {
+	b := &VoskBackend{Servers: map[Language]string{English: "ws://127.0.0.1:1"}}
+	_, err := b.Recognize(context.Background(), strings.NewReader("fLaC"), 4, English, BackendParams{ContentType: "audio/x-flac; rate=16000"})
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("err = %v, want ErrUnsupportedFormat", err)
+	}
+}
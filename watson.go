@@ -0,0 +1,220 @@
+package gorec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WatsonIAMEndpoint is where WatsonBackend exchanges its API key for IBM
+// Cloud IAM access tokens.
+const WatsonIAMEndpoint = "https://iam.cloud.ibm.com/identity/token"
+
+// WatsonBackend is a Backend for IBM Watson Speech to Text's REST API. URL
+// is the service instance's, such as
+// https://api.us-south.speech-to-text.watson.cloud.ibm.com/instances/ID,
+// and Key its API key, exchanged for IAM access tokens kept until shortly
+// before they expire.
+//
+// Each language is recognized with its broadband model, or narrowband for
+// audio sampled below 16 kHz, unless Models names another. Phrase hints
+// are sent as keywords. Watson's word timings and confidences are reported
+// in Alternative.Words.
+type WatsonBackend struct {
+	Key string
+	URL string
+
+	Models map[Language]string
+
+	// IAMEndpoint defaults to WatsonIAMEndpoint and HTTPClient to a default
+	// client.
+	IAMEndpoint string
+	HTTPClient  *http.Client
+
+	clock clock
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+type watsonResponse struct {
+	Results []struct {
+		Final        bool `json:"final"`
+		Alternatives []struct {
+			Transcript string  `json:"transcript"`
+			Confidence float64 `json:"confidence"`
+			// Timestamps are [word, start, end] and WordConfidence
+			// [word, confidence].
+			Timestamps     [][]any `json:"timestamps"`
+			WordConfidence [][]any `json:"word_confidence"`
+		} `json:"alternatives"`
+	} `json:"results"`
+}
+
+func (b *WatsonBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, params BackendParams) (*GoogleResponse, error) {
+	rate := contentTypeRate(params.ContentType)
+	if rate <= 0 {
+		rate = defaultSampleRate
+	}
+	var contentType string
+	switch {
+	case isL16(params.ContentType):
+		contentType = "audio/l16; rate=" + strconv.Itoa(rate) + "; endianness=little-endian"
+	case cloudEncoding(params.ContentType) == "FLAC":
+		contentType = "audio/flac"
+	case cloudEncoding(params.ContentType) == "OGG_OPUS":
+		contentType = "audio/ogg; codecs=opus"
+	default:
+		return nil, fmt.Errorf("%w: Watson takes no %s", ErrUnsupportedFormat, params.ContentType)
+	}
+	model, ok := b.Models[lang]
+	if !ok {
+		band := "BroadbandModel"
+		if rate < 16000 {
+			band = "NarrowbandModel"
+		}
+		model = lang.Code() + "_" + band
+	}
+	q := url.Values{
+		"model":           {model},
+		"timestamps":      {"true"},
+		"word_confidence": {"true"},
+	}
+	if params.MaxAlternatives > 0 {
+		q.Set("max_alternatives", strconv.Itoa(params.MaxAlternatives))
+	}
+	if len(params.PhraseHints) > 0 {
+		q.Set("keywords", strings.Join(params.PhraseHints, ","))
+		q.Set("keywords_threshold", "0.5")
+	}
+	switch params.Profanity {
+	case ProfanityOff:
+		q.Set("profanity_filter", "false")
+	case ProfanityMask, ProfanityDrop:
+		q.Set("profanity_filter", "true")
+	}
+
+	client := b.HTTPClient
+	if client == nil {
+		client = sharedClient
+	}
+	token, err := b.accessToken(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("Getting an access token: %w", err)
+	}
+	r, err := http.NewRequestWithContext(ctx, "POST", strings.TrimSuffix(b.URL, "/")+"/v1/recognize?"+q.Encode(), audio)
+	if err != nil {
+		return nil, err
+	}
+	r.ContentLength = size
+	r.Header.Set("Content-Type", contentType)
+	r.Header.Set("Authorization", "Bearer "+token)
+	resp, err := client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Reading response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, newAPIError(resp.StatusCode, respBody)
+	}
+	var wr watsonResponse
+	if err := json.Unmarshal(respBody, &wr); err != nil {
+		return nil, fmt.Errorf("Decoding response: %w", err)
+	}
+	return wr.googleResponse(), nil
+}
+
+func (wr *watsonResponse) googleResponse() *GoogleResponse {
+	gr := &GoogleResponse{}
+	for _, res := range wr.Results {
+		r := Result{Final: res.Final}
+		for _, a := range res.Alternatives {
+			alt := Alternative{Transcript: strings.TrimSpace(a.Transcript), Confidence: a.Confidence}
+			for i, ts := range a.Timestamps {
+				if len(ts) < 3 {
+					continue
+				}
+				word, _ := ts[0].(string)
+				start, _ := ts[1].(float64)
+				end, _ := ts[2].(float64)
+				w := Word{Word: word, Start: time.Duration(start * float64(time.Second)), End: time.Duration(end * float64(time.Second))}
+				// Both lists hold the words of the alternative in order.
+				if i < len(a.WordConfidence) && len(a.WordConfidence[i]) == 2 {
+					w.Confidence, _ = a.WordConfidence[i][1].(float64)
+				}
+				alt.Words = append(alt.Words, w)
+			}
+			r.Alternatives = append(r.Alternatives, alt)
+		}
+		gr.Results = append(gr.Results, r)
+	}
+	return gr
+}
+
+// accessToken returns the cached IAM token, fetching a new one for Key
+// when it is missing or about to expire.
+func (b *WatsonBackend) accessToken(ctx context.Context, client *http.Client) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	clk := b.clock
+	if clk == nil {
+		clk = realClock{}
+	}
+	now := clk.Now()
+	if b.token != "" && now.Before(b.expires) {
+		return b.token, nil
+	}
+	if b.Key == "" {
+		return "", errors.New("No Watson API key")
+	}
+	endpoint := b.IAMEndpoint
+	if endpoint == "" {
+		endpoint = WatsonIAMEndpoint
+	}
+	form := url.Values{
+		"grant_type": {"urn:ibm:params:oauth:grant-type:apikey"},
+		"apikey":     {b.Key},
+	}
+	r, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Accept", "application/json")
+	resp, err := client.Do(r)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode/100 != 2 {
+		return "", newAPIError(resp.StatusCode, body)
+	}
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return "", err
+	}
+	b.token = tok.AccessToken
+	b.expires = now.Add(time.Duration(tok.ExpiresIn)*time.Second - time.Minute)
+	return b.token, nil
+}
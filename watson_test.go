@@ -0,0 +1,63 @@
+package gorec
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatsonBackend(t *testing.T) {
+	var tokens int32
+	iam := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokens, 1)
+		if r.FormValue("apikey") != "ibm" || r.FormValue("grant_type") != "urn:ibm:params:oauth:grant-type:apikey" {
+			t.Errorf("token request %v", r.Form)
+		}
+		fmt.Fprint(w, `{"access_token": "iam-token", "expires_in": 3600}`)
+	}))
+	defer iam.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/instances/1/v1/recognize" || r.Header.Get("Authorization") != "Bearer iam-token" {
+			t.Errorf("%s with %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		if got := r.Header.Get("Content-Type"); got != "audio/l16; rate=8000; endianness=little-endian" {
+			t.Errorf("Content-Type %q", got)
+		}
+		q := r.URL.Query()
+		if q.Get("model") != "fr-FR_NarrowbandModel" || q.Get("max_alternatives") != "2" || q.Get("keywords") != "gorec" {
+			t.Errorf("query %s", r.URL.RawQuery)
+		}
+		if audio, _ := io.ReadAll(r.Body); len(audio) != 4 {
+			t.Errorf("%d bytes of audio", len(audio))
+		}
+		fmt.Fprint(w, `{"result_index": 0, "results": [{"final": true, "alternatives": [
+			{"transcript": "bonjour gorec ", "confidence": 0.8,
+			 "timestamps": [["bonjour", 0.1, 0.6], ["gorec", 0.6, 1.2]],
+			 "word_confidence": [["bonjour", 0.95], ["gorec", 0.65]]},
+			{"transcript": "bonjour go rec "}]}]}`)
+	}))
+	defer srv.Close()
+
+	b := &WatsonBackend{Key: "ibm", URL: srv.URL + "/instances/1/", IAMEndpoint: iam.URL}
+	for i := 0; i < 2; i++ {
+		h, err := ListenFile([]byte{1, 2, 3, 4}, "", WithBackend(b), WithLanguages(French), WithSampleRate(8000),
+			WithMaxAlternatives(2), WithPhrases([]string{"gorec"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.Alternative.Transcript != "bonjour gorec" || h.Alternative.Confidence != 0.8 {
+			t.Errorf("hypothesis %v", h)
+		}
+		words := h.Alternative.Words
+		if len(words) != 2 || words[1].Start != 600*time.Millisecond || words[1].End != 1200*time.Millisecond || words[1].Confidence != 0.65 {
+			t.Errorf("Words = %+v", words)
+		}
+	}
+	if n := atomic.LoadInt32(&tokens); n != 1 {
+		t.Errorf("%d tokens fetched, want 1", n)
+	}
+}
@@ -0,0 +1,130 @@
+package gorec
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// WAV is the audio of a WAV file, its PCM samples with the header stripped.
+type WAV struct {
+	SampleRate    int
+	BitsPerSample int
+	Channels      int
+	Data          []byte
+}
+
+const (
+	wavFormatPCM        = 1
+	wavFormatExtensible = 0xfffe
+)
+
+// ParseWAV reads the RIFF header of a WAV file and returns its samples. Only
+// 16-bit mono linear PCM can be sent to Google; anything else is rejected
+// wrapping ErrUnsupportedFormat.
+func ParseWAV(b []byte) (*WAV, error) {
+	return parseWAV(b, false)
+}
+
+// parseWAV is ParseWAV accepting interleaved multichannel PCM as well when
+// anyChannels is set.
+func parseWAV(b []byte, anyChannels bool) (*WAV, error) {
+	w, offset, size, err := wavHeader(b, anyChannels)
+	if err != nil {
+		return nil, err
+	}
+	w.Data = b[offset : offset+min(size, len(b)-offset)]
+	return w, nil
+}
+
+// wavHeader parses the format of the WAV file starting with b, returning
+// where its samples start and how many bytes of them its data chunk
+// declares, which may run past b: streamed recordings often leave it unset,
+// and b may be only the head of a file.
+func wavHeader(b []byte, anyChannels bool) (w *WAV, offset, size int, err error) {
+	if !isWAV(b) {
+		return nil, 0, 0, ErrNotWAV
+	}
+	w = &WAV{}
+	var format int
+	var haveFormat bool
+	for offset = 12; len(b)-offset >= 8; {
+		chunk := b[offset:]
+		id, size := string(chunk[:4]), int(binary.LittleEndian.Uint32(chunk[4:8]))
+		body := chunk[8:]
+		offset += 8
+		if id == "data" {
+			if !haveFormat {
+				return nil, 0, 0, fmt.Errorf("%w: data before fmt chunk", ErrNotWAV)
+			}
+			if err := w.check(format, anyChannels); err != nil {
+				return nil, 0, 0, err
+			}
+			return w, offset, size, nil
+		}
+		if size > len(body) {
+			return nil, 0, 0, fmt.Errorf("%w: truncated %q chunk", ErrNotWAV, id)
+		}
+		if id == "fmt " {
+			if size < 16 {
+				return nil, 0, 0, fmt.Errorf("%w: short fmt chunk", ErrNotWAV)
+			}
+			format = int(binary.LittleEndian.Uint16(body[0:2]))
+			w.Channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			w.SampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			w.BitsPerSample = int(binary.LittleEndian.Uint16(body[14:16]))
+			if format == wavFormatExtensible && size >= 26 {
+				format = int(binary.LittleEndian.Uint16(body[24:26]))
+			}
+			haveFormat = true
+		}
+		// Chunks are padded to an even size.
+		size += size & 1
+		if size > len(body) {
+			break
+		}
+		offset += size
+	}
+	return nil, 0, 0, fmt.Errorf("%w: no data chunk", ErrNotWAV)
+}
+
+func (w *WAV) check(format int, anyChannels bool) error {
+	switch {
+	case format != wavFormatPCM:
+		return fmt.Errorf("%w: WAV format %#x, not linear PCM", ErrUnsupportedFormat, format)
+	case w.BitsPerSample != 16:
+		return fmt.Errorf("%w: %d-bit samples, not 16-bit", ErrUnsupportedFormat, w.BitsPerSample)
+	case w.Channels != 1 && !anyChannels, w.Channels < 1:
+		return fmt.Errorf("%w: %d channels, not mono", ErrUnsupportedFormat, w.Channels)
+	case w.SampleRate <= 0:
+		return fmt.Errorf("%w: sample rate %d", ErrUnsupportedFormat, w.SampleRate)
+	}
+	return nil
+}
+
+func isWAV(b []byte) bool {
+	return len(b) >= 12 && string(b[:4]) == "RIFF" && string(b[8:12]) == "WAVE"
+}
+
+// ContentType returns the Content-Type to send w.Data with.
+func (w *WAV) ContentType() string {
+	return withRate(ContentType, w.SampleRate)
+}
+
+// Bytes returns w as a WAV file.
+func (w *WAV) Bytes() []byte {
+	blockAlign := w.Channels * w.BitsPerSample / 8
+	b := make([]byte, 44, 44+len(w.Data))
+	copy(b[0:], "RIFF")
+	binary.LittleEndian.PutUint32(b[4:], uint32(36+len(w.Data)))
+	copy(b[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(b[16:], 16)
+	binary.LittleEndian.PutUint16(b[20:], wavFormatPCM)
+	binary.LittleEndian.PutUint16(b[22:], uint16(w.Channels))
+	binary.LittleEndian.PutUint32(b[24:], uint32(w.SampleRate))
+	binary.LittleEndian.PutUint32(b[28:], uint32(w.SampleRate*blockAlign))
+	binary.LittleEndian.PutUint16(b[32:], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(b[34:], uint16(w.BitsPerSample))
+	copy(b[36:], "data")
+	binary.LittleEndian.PutUint32(b[40:], uint32(len(w.Data)))
+	return append(b, w.Data...)
+}
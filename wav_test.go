@@ -0,0 +1,95 @@
+package gorec
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func wav(format, ch, rate, bits int, data []byte) []byte {
+	b := []byte("RIFF\x00\x00\x00\x00WAVE")
+	b = append(b, "LIST\x03\x00\x00\x00abc\x00"...)
+	f := make([]byte, 24)
+	copy(f, "fmt ")
+	binary.LittleEndian.PutUint32(f[4:], 16)
+	binary.LittleEndian.PutUint16(f[8:], uint16(format))
+	binary.LittleEndian.PutUint16(f[10:], uint16(ch))
+	binary.LittleEndian.PutUint32(f[12:], uint32(rate))
+	binary.LittleEndian.PutUint16(f[22:], uint16(bits))
+	b = append(b, f...)
+	d := make([]byte, 8)
+	copy(d, "data")
+	binary.LittleEndian.PutUint32(d[4:], uint32(len(data)))
+	return append(append(b, d...), data...)
+}
+
+func TestParseWAV(t *testing.T) {
+	w, err := ParseWAV(wav(1, 1, 8000, 16, []byte{1, 2, 3, 4}))
+	if err != nil || w.SampleRate != 8000 || len(w.Data) != 4 || w.ContentType() != "audio/l16; rate=8000;" {
+		t.Fatal(w, err)
+	}
+	if _, err := ParseWAV(wav(1, 2, 8000, 16, nil)); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatal(err)
+	}
+	if _, err := ParseWAV([]byte("nope")); err != ErrNotWAV {
+		t.Fatal(err)
+	}
+}
+
+func TestParseWAVAnyChannels(t *testing.T) {
+	w, err := parseWAV(wav(1, 2, 8000, 16, []byte{1, 2, 3, 4}), true)
+	if err != nil || w.Channels != 2 || len(w.Data) != 4 {
+		t.Fatal(w, err)
+	}
+	if _, err := parseWAV(wav(1, 0, 8000, 16, nil), true); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatal(err)
+	}
+}
+
+func TestListenPathWAVSection(t *testing.T) {
+	dir := t.TempDir()
+	declared := filepath.Join(dir, "declared.wav")
+	if err := os.WriteFile(declared, append(wav(1, 1, 16000, 16, []byte("abcd")), "tail"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	streamed := wav(1, 1, 16000, 16, []byte("abcdef"))
+	binary.LittleEndian.PutUint32(streamed[len(streamed)-10:], 0xffffffff)
+	unset := filepath.Join(dir, "unset.wav")
+	if err := os.WriteFile(unset, streamed, 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient("k", WithBackend(&echoBackend{}), WithLanguages(English))
+	for path, want := range map[string]string{declared: "abcd", unset: "abcdef"} {
+		h, err := c.ListenPath(path)
+		if err != nil || h.Alternative.Transcript != want || h.Source != path {
+			t.Errorf("ListenPath(%s) = %v, %v, want %q", filepath.Base(path), h, err, want)
+		}
+	}
+
+	// Other rates are resampled from the whole file unless kept.
+	other := filepath.Join(dir, "other.wav")
+	if err := os.WriteFile(other, wav(1, 1, 8000, 16, []byte("abcd")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b := &rateBackend{}
+	if _, err := NewClient("k", WithBackend(b), WithLanguages(English), WithKeepSampleRate(true)).ListenPath(other); err != nil || b.contentType != "audio/l16; rate=8000;" {
+		t.Errorf("kept rate sent %q, %v", b.contentType, err)
+	}
+	if h, err := c.ListenPath(other); err != nil || len(h.Alternative.Transcript) != 8 {
+		t.Errorf("resampled = %v, %v", h, err)
+	}
+}
+
+// rateBackend records the Content-Type it was sent.
+type rateBackend struct {
+	contentType string
+}
+
+func (b *rateBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, p BackendParams) (*GoogleResponse, error) {
+	b.contentType = p.ContentType
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{{Transcript: "ok", Confidence: 0.9}}, Final: true}}}, nil
+}
@@ -0,0 +1,214 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WhisperEndpoint is OpenAI's transcription method, which self-hosted
+// Whisper servers commonly mimic.
+const WhisperEndpoint = "https://api.openai.com/v1/audio/transcriptions"
+
+// WhisperTranslationEndpoint is OpenAI's method translating speech into
+// English text.
+const WhisperTranslationEndpoint = "https://api.openai.com/v1/audio/translations"
+
+// WhisperBackend is a Backend for Whisper behind an OpenAI-compatible
+// transcription API. Phrase hints are sent as its prompt, and linear PCM is
+// wrapped in a WAV header since Whisper wants audio files. Whisper reports
+// no confidence: the hypotheses carry the mean probability of its segments
+// instead.
+//
+// Whisper detects the language spoken by itself, so with
+// WithSingleRequestMultiLang a single request serves every language
+// queried, the hypothesis getting the one Whisper reports.
+type WhisperBackend struct {
+	Key string
+
+	// Model defaults to "whisper-1", Endpoint to WhisperEndpoint and
+	// HTTPClient to a default client.
+	Model      string
+	Endpoint   string
+	HTTPClient *http.Client
+
+	// Translate has the speech translated into English text rather than
+	// transcribed, from Endpoint with its /transcriptions method replaced
+	// by /translations, WhisperTranslationEndpoint by default. Whisper
+	// reports no word timings for translations.
+	Translate bool
+}
+
+type whisperResponse struct {
+	Text     string `json:"text"`
+	Language string `json:"language"`
+	Words    []struct {
+		Word  string  `json:"word"`
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+	} `json:"words"`
+	Segments []struct {
+		AvgLogprob float64 `json:"avg_logprob"`
+	} `json:"segments"`
+}
+
+func (b *WhisperBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, params BackendParams) (*GoogleResponse, error) {
+	base, _, _ := strings.Cut(lang.StringCode(), "-")
+	wr, err := b.transcribe(ctx, audio, base, params)
+	if err != nil {
+		return nil, err
+	}
+	return wr.googleResponse(), nil
+}
+
+// RecognizeAny leaves Whisper to detect the language, among langs or not.
+// Audio in a language outside langs fails.
+func (b *WhisperBackend) RecognizeAny(ctx context.Context, audio io.Reader, size int64, langs []Language, params BackendParams) (*GoogleResponse, Language, error) {
+	wr, err := b.transcribe(ctx, audio, "", params)
+	if err != nil {
+		return nil, langs[0], err
+	}
+	lang, ok := whisperLanguage(wr.Language, langs)
+	if !ok {
+		return nil, langs[0], fmt.Errorf("Whisper heard %s, none of the languages queried", wr.Language)
+	}
+	return wr.googleResponse(), lang, nil
+}
+
+// whisperLanguage finds the language of langs Whisper names detected, by
+// its lowercase English name such as "french" or, for some servers, by its
+// code.
+func whisperLanguage(detected string, langs []Language) (Language, bool) {
+	detected = strings.ToLower(detected)
+	for _, l := range langs {
+		name := strings.ToLower(l.String())
+		if i := strings.IndexAny(name, " (,"); i >= 0 {
+			name = name[:i]
+		}
+		if detected == name || detected == baseCode(l.StringCode()) {
+			return l, true
+		}
+	}
+	return 0, false
+}
+
+// transcribe sends audio to Whisper, in the language whose ISO 639-1 code
+// is lang or, when empty, for Whisper to detect.
+func (b *WhisperBackend) transcribe(ctx context.Context, audio io.Reader, lang string, params BackendParams) (*whisperResponse, error) {
+	content, err := ioutil.ReadAll(audio)
+	if err != nil {
+		return nil, err
+	}
+	name := "audio"
+	switch {
+	case isL16(params.ContentType):
+		rate := contentTypeRate(params.ContentType)
+		if rate <= 0 {
+			rate = defaultSampleRate
+		}
+		w := WAV{SampleRate: rate, BitsPerSample: 16, Channels: 1, Data: content}
+		content, name = w.Bytes(), "audio.wav"
+	case cloudEncoding(params.ContentType) == "FLAC":
+		name = "audio.flac"
+	case cloudEncoding(params.ContentType) == "OGG_OPUS":
+		name = "audio.ogg"
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", name)
+	if err != nil {
+		return nil, err
+	}
+	fw.Write(content)
+	model := b.Model
+	if model == "" {
+		model = "whisper-1"
+	}
+	fields := [][2]string{{"model", model}, {"response_format", "verbose_json"}}
+	if !b.Translate {
+		fields = append(fields, [2]string{"timestamp_granularities[]", "word"}, [2]string{"timestamp_granularities[]", "segment"})
+		if lang != "" {
+			fields = append(fields, [2]string{"language", lang})
+		}
+	}
+	if len(params.PhraseHints) > 0 {
+		fields = append(fields, [2]string{"prompt", strings.Join(params.PhraseHints, ", ")})
+	}
+	for _, f := range fields {
+		mw.WriteField(f[0], f[1])
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	endpoint := b.Endpoint
+	switch {
+	case b.Translate && endpoint == "":
+		endpoint = WhisperTranslationEndpoint
+	case b.Translate:
+		endpoint = strings.TrimSuffix(endpoint, "/transcriptions") + "/translations"
+	case endpoint == "":
+		endpoint = WhisperEndpoint
+	}
+	r, err := http.NewRequestWithContext(ctx, "POST", endpoint, &body)
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	if b.Key != "" {
+		r.Header.Set("Authorization", "Bearer "+b.Key)
+	}
+	client := b.HTTPClient
+	if client == nil {
+		client = sharedClient
+	}
+	resp, err := client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Reading response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, newAPIError(resp.StatusCode, respBody)
+	}
+	var wr whisperResponse
+	if err := json.Unmarshal(respBody, &wr); err != nil {
+		return nil, fmt.Errorf("Decoding response: %w", err)
+	}
+	return &wr, nil
+}
+
+func (wr *whisperResponse) googleResponse() *GoogleResponse {
+	text := strings.TrimSpace(wr.Text)
+	if text == "" {
+		return &GoogleResponse{}
+	}
+	alt := Alternative{Transcript: text}
+	if len(wr.Segments) > 0 {
+		var sum float64
+		for _, s := range wr.Segments {
+			sum += s.AvgLogprob
+		}
+		alt.Confidence = math.Min(1, math.Exp(sum/float64(len(wr.Segments))))
+	}
+	for _, w := range wr.Words {
+		alt.Words = append(alt.Words, Word{
+			Word:  strings.TrimSpace(w.Word),
+			Start: time.Duration(w.Start * float64(time.Second)),
+			End:   time.Duration(w.End * float64(time.Second)),
+		})
+	}
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{alt}, Final: true}}}
+}
@@ -0,0 +1,101 @@
+package gorec
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWhisperBackend(t *testing.T) {
+	pcm := bytes.Repeat([]byte{1, 2}, 800)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer sk" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		for field, want := range map[string]string{"model": "whisper-1", "language": "fr", "prompt": "gorec, Kubernetes", "response_format": "verbose_json"} {
+			if got := r.FormValue(field); got != want {
+				t.Errorf("%s = %q, want %q", field, got, want)
+			}
+		}
+		f, hdr, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		audio, _ := io.ReadAll(f)
+		wav, err := ParseWAV(audio)
+		if err != nil || hdr.Filename != "audio.wav" || wav.SampleRate != 8000 || !bytes.Equal(wav.Data, pcm) {
+			t.Errorf("uploaded %s: %v, %v", hdr.Filename, wav, err)
+		}
+		fmt.Fprint(w, `{"text": " bonjour gorec", "words": [{"word": "bonjour", "start": 0, "end": 0.5}, {"word": "gorec", "start": 0.5, "end": 1.25}], "segments": [{"avg_logprob": -0.1}, {"avg_logprob": -0.3}]}`)
+	}))
+	defer srv.Close()
+
+	h, err := ListenFile(pcm, "k", WithBackend(&WhisperBackend{Key: "sk", Endpoint: srv.URL}),
+		WithLanguages(French), WithSampleRate(8000), WithPhrases([]string{"gorec", "Kubernetes"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Alternative.Transcript != "bonjour gorec" || h.Alternative.Confidence < 0.81 || h.Alternative.Confidence > 0.82 {
+		t.Errorf("hypothesis %v", h)
+	}
+	if words := h.Alternative.Words; len(words) != 2 || words[1].Start != 500*time.Millisecond || words[1].End != 1250*time.Millisecond {
+		t.Errorf("Words = %v", words)
+	}
+}
+
+func TestWhisperBackendTranslate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/translations" {
+			t.Errorf("path %s", r.URL.Path)
+		}
+		r.ParseMultipartForm(1 << 20)
+		if r.FormValue("language") != "" || r.FormValue("timestamp_granularities[]") != "" {
+			t.Errorf("form %v", r.MultipartForm.Value)
+		}
+		fmt.Fprint(w, `{"text": "Hello world", "segments": [{"avg_logprob": 0}]}`)
+	}))
+	defer srv.Close()
+
+	b := &WhisperBackend{Endpoint: srv.URL + "/v1/audio/transcriptions", Translate: true}
+	h, err := ListenFile([]byte{1, 2}, "k", WithBackend(b), WithLanguages(French))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Language != French || h.Alternative.Transcript != "Hello world" {
+		t.Errorf("hypothesis %v", h)
+	}
+}
+
+func TestWhisperBackendDetectLanguage(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		r.ParseMultipartForm(1 << 20)
+		if lang := r.FormValue("language"); lang != "" {
+			t.Errorf("language %q sent", lang)
+		}
+		fmt.Fprint(w, `{"text": "hola", "language": "spanish", "segments": [{"avg_logprob": 0}]}`)
+	}))
+	defer srv.Close()
+
+	b := &WhisperBackend{Endpoint: srv.URL}
+	h, err := ListenFile([]byte{1, 2}, "k", WithBackend(b), WithLanguages(French, Spanish, German), WithSingleRequestMultiLang(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 || h.Language != Spanish || h.Alternative.Transcript != "hola" {
+		t.Errorf("%d requests, hypothesis %v", requests, h)
+	}
+
+	_, err = ListenFile([]byte{1, 2}, "k", WithBackend(b), WithLanguages(French, German), WithSingleRequestMultiLang(true))
+	if err == nil {
+		t.Error("Spanish recognized among French and German")
+	}
+}
@@ -0,0 +1,150 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// whisperCppRate is the only sample rate whisper.cpp takes.
+const whisperCppRate = 16000
+
+// WhisperCppBackend is a Backend running whisper.cpp's command-line
+// program, recognizing speech offline on the CPU. Model is the path of the
+// ggml model file to load and Binary the program to run, "whisper-cli" from
+// the PATH if empty. Threads, when above zero, sets how many threads it
+// uses. Phrase hints are sent as its prompt.
+//
+// whisper.cpp takes 16 kHz mono WAV only: linear PCM at other rates is
+// resampled, and other formats are refused for a Decoder to convert first.
+// The hypotheses carry the mean probability of its tokens as confidence.
+type WhisperCppBackend struct {
+	Binary  string
+	Model   string
+	Threads int
+}
+
+type whisperCppOutput struct {
+	Transcription []struct {
+		Text   string `json:"text"`
+		Tokens []struct {
+			Text    string  `json:"text"`
+			P       float64 `json:"p"`
+			Offsets struct {
+				From int64 `json:"from"`
+				To   int64 `json:"to"`
+			} `json:"offsets"`
+		} `json:"tokens"`
+	} `json:"transcription"`
+}
+
+func (b *WhisperCppBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, params BackendParams) (*GoogleResponse, error) {
+	if !isL16(params.ContentType) {
+		return nil, fmt.Errorf("%w: whisper.cpp needs linear PCM, not %s", ErrUnsupportedFormat, params.ContentType)
+	}
+	if b.Model == "" {
+		return nil, errors.New("No whisper.cpp model set")
+	}
+	pcm, err := ioutil.ReadAll(audio)
+	if err != nil {
+		return nil, err
+	}
+	rate := contentTypeRate(params.ContentType)
+	if rate <= 0 {
+		rate = defaultSampleRate
+	}
+	pcm = Resample(pcm, rate, whisperCppRate)
+
+	dir, err := os.MkdirTemp("", "gorec-whisper")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	input, output := filepath.Join(dir, "audio.wav"), filepath.Join(dir, "result")
+	w := WAV{SampleRate: whisperCppRate, BitsPerSample: 16, Channels: 1, Data: pcm}
+	if err := os.WriteFile(input, w.Bytes(), 0600); err != nil {
+		return nil, err
+	}
+
+	binary := b.Binary
+	if binary == "" {
+		binary = "whisper-cli"
+	}
+	base, _, _ := strings.Cut(lang.StringCode(), "-")
+	args := []string{"-m", b.Model, "-f", input, "-l", base, "-np", "-ojf", "-of", output}
+	if b.Threads > 0 {
+		args = append(args, "-t", strconv.Itoa(b.Threads))
+	}
+	if len(params.PhraseHints) > 0 {
+		args = append(args, "--prompt", strings.Join(params.PhraseHints, ", "))
+	}
+	cmd := exec.CommandContext(ctx, binary, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("whisper.cpp: %s", strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+	body, err := os.ReadFile(output + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("Reading whisper.cpp output: %w", err)
+	}
+	var out whisperCppOutput
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("Decoding whisper.cpp output: %w", err)
+	}
+	return out.googleResponse(), nil
+}
+
+// googleResponse joins the segments of out into a single final result,
+// merging the tokens into words: a token starting with a space starts a
+// word. Special tokens such as [_BEG_] are left out.
+func (out *whisperCppOutput) googleResponse() *GoogleResponse {
+	var texts []string
+	var alt Alternative
+	var tokens int
+	for _, seg := range out.Transcription {
+		if text := strings.TrimSpace(seg.Text); text != "" {
+			texts = append(texts, text)
+		}
+		for _, tok := range seg.Tokens {
+			if strings.HasPrefix(tok.Text, "[_") || strings.TrimSpace(tok.Text) == "" {
+				continue
+			}
+			alt.Confidence += tok.P
+			tokens++
+			start := time.Duration(tok.Offsets.From) * time.Millisecond
+			end := time.Duration(tok.Offsets.To) * time.Millisecond
+			if n := len(alt.Words); n > 0 && !strings.HasPrefix(tok.Text, " ") {
+				alt.Words[n-1].Word += tok.Text
+				alt.Words[n-1].End = end
+				continue
+			}
+			alt.Words = append(alt.Words, Word{Word: strings.TrimSpace(tok.Text), Start: start, End: end})
+		}
+	}
+	if len(texts) == 0 {
+		return &GoogleResponse{}
+	}
+	alt.Transcript = strings.Join(texts, " ")
+	if tokens > 0 {
+		alt.Confidence /= float64(tokens)
+	}
+	return &GoogleResponse{Results: []Result{{Alternatives: []Alternative{alt}, Final: true}}}
+}
@@ -0,0 +1,84 @@
+package gorec
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeWhisperCpp writes a program taking whisper.cpp's arguments that
+// records them and the WAV it was given in dir, and outputs result.
+func fakeWhisperCpp(t *testing.T, dir, result string) string {
+	script := `#!/bin/sh
+echo "$@" > ` + dir + `/args
+while [ $# -gt 0 ]; do
+	case "$1" in
+	-f) cp "$2" ` + dir + `/input.wav; shift ;;
+	-of) out="$2"; shift ;;
+	esac
+	shift
+done
+cat > "$out.json" <<'JSON'
+` + result + `
+JSON
+`
+	path := filepath.Join(dir, "whisper-cli")
+	if err := os.WriteFile(path, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestWhisperCppBackend(t *testing.T) {
+	dir := t.TempDir()
+	binary := fakeWhisperCpp(t, dir, `{"result": {"language": "fr"}, "transcription": [
+		{"text": " Bonjour gorec.", "tokens": [
+			{"text": "[_BEG_]", "p": 0.1, "offsets": {"from": 0, "to": 0}},
+			{"text": " Bonjour", "p": 0.9, "offsets": {"from": 0, "to": 500}},
+			{"text": " gor", "p": 0.6, "offsets": {"from": 500, "to": 800}},
+			{"text": "ec.", "p": 0.9, "offsets": {"from": 800, "to": 1200}}]},
+		{"text": " Salut.", "tokens": [{"text": " Salut.", "p": 1, "offsets": {"from": 1500, "to": 2000}}]}]}`)
+	pcm := bytes.Repeat([]byte{1, 2}, 8000)
+	b := &WhisperCppBackend{Binary: binary, Model: "ggml-base.bin", Threads: 2}
+
+	h, err := ListenFile(pcm, "", WithBackend(b), WithLanguages(French), WithSampleRate(8000), WithPhrases([]string{"gorec"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Alternative.Transcript != "Bonjour gorec. Salut." || h.Alternative.Confidence < 0.84 || h.Alternative.Confidence > 0.86 {
+		t.Errorf("hypothesis %v", h)
+	}
+	words := h.Alternative.Words
+	if len(words) != 3 || words[1].Word != "gorec." || words[1].Start != 500*time.Millisecond || words[1].End != 1200*time.Millisecond {
+		t.Errorf("Words = %v", words)
+	}
+
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	for _, want := range []string{"-m ggml-base.bin", "-l fr", "-t 2", "--prompt gorec"} {
+		if !strings.Contains(string(args), want) {
+			t.Errorf("args %q lack %q", args, want)
+		}
+	}
+	input, _ := os.ReadFile(filepath.Join(dir, "input.wav"))
+	wav, err := ParseWAV(input)
+	if err != nil || wav.SampleRate != 16000 || wav.Channels != 1 || len(wav.Data) != 2*len(pcm) {
+		t.Errorf("input %v, %v", wav, err)
+	}
+}
+
+func TestWhisperCppBackendErrors(t *testing.T) {
+	dir := t.TempDir()
+	failing := filepath.Join(dir, "failing")
+	os.WriteFile(failing, []byte("#!/bin/sh\necho 'failed to load model' >&2\nexit 1\n"), 0700)
+	b := &WhisperCppBackend{Binary: failing, Model: "missing.bin"}
+	if _, err := ListenFile([]byte{1, 2}, "", WithBackend(b), WithLanguages(French)); err == nil || !strings.Contains(err.Error(), "failed to load model") {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := ListenFile([]byte("fLaC"), "", WithBackend(b), WithLanguages(French), WithContentType(FLACContentType(16000))); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("err = %v, want ErrUnsupportedFormat", err)
+	}
+}
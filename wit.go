@@ -0,0 +1,180 @@
+package gorec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WitEndpoint is Wit.ai's speech method, dated with the API version
+// WitBackend understands.
+const WitEndpoint = "https://api.wit.ai/speech?v=20240304"
+
+// WitBackend is a Backend for Wit.ai's speech API, transcribing and
+// understanding speech at once: the intents and entities of the app are
+// reported in Hypothesis.Intents and Hypothesis.Entities. A Wit app
+// understands a single language, so each is queried with its app's access
+// token from Tokens, and Token is used for the languages Tokens lacks.
+//
+// Wit takes linear PCM and Ogg, not FLAC.
+type WitBackend struct {
+	Token  string
+	Tokens map[Language]string
+
+	// Endpoint defaults to WitEndpoint and HTTPClient to a default client.
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+// Intent is an intent a backend such as WitBackend understood the speech
+// to express, with how sure it is of it.
+type Intent struct {
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Entity is a value a backend such as WitBackend extracted from the
+// speech: Body is the text it was found in, between the bytes Start and
+// End of the transcript, and Value what it resolved to, such as a time for
+// "tomorrow at noon".
+type Entity struct {
+	Name       string  `json:"name"`
+	Role       string  `json:"role,omitempty"`
+	Body       string  `json:"body"`
+	Value      any     `json:"value,omitempty"`
+	Confidence float64 `json:"confidence"`
+	Start      int     `json:"start"`
+	End        int     `json:"end"`
+}
+
+type witResponse struct {
+	Text     string              `json:"text"`
+	Intents  []Intent            `json:"intents"`
+	Entities map[string][]Entity `json:"entities"`
+	Speech   struct {
+		Confidence float64 `json:"confidence"`
+		Tokens     []struct {
+			Token      string  `json:"token"`
+			Start      int64   `json:"start"`
+			End        int64   `json:"end"`
+			Confidence float64 `json:"confidence"`
+		} `json:"tokens"`
+	} `json:"speech"`
+}
+
+func (b *WitBackend) Recognize(ctx context.Context, audio io.Reader, size int64, lang Language, params BackendParams) (*GoogleResponse, error) {
+	token, ok := b.Tokens[lang]
+	if !ok {
+		token = b.Token
+	}
+	if token == "" {
+		return nil, fmt.Errorf("No Wit.ai app for %s", lang.StringCode())
+	}
+	var contentType string
+	switch {
+	case isL16(params.ContentType):
+		rate := contentTypeRate(params.ContentType)
+		if rate <= 0 {
+			rate = defaultSampleRate
+		}
+		contentType = "audio/raw;encoding=signed-integer;bits=16;rate=" + strconv.Itoa(rate) + ";endian=little"
+	case cloudEncoding(params.ContentType) == "OGG_OPUS":
+		contentType = "audio/ogg"
+	default:
+		return nil, fmt.Errorf("%w: Wit.ai takes no %s", ErrUnsupportedFormat, params.ContentType)
+	}
+
+	endpoint := b.Endpoint
+	if endpoint == "" {
+		endpoint = WitEndpoint
+	}
+	r, err := http.NewRequestWithContext(ctx, "POST", endpoint, audio)
+	if err != nil {
+		return nil, err
+	}
+	r.ContentLength = size
+	r.Header.Set("Content-Type", contentType)
+	r.Header.Set("Authorization", "Bearer "+token)
+	client := b.HTTPClient
+	if client == nil {
+		client = sharedClient
+	}
+	resp, err := client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Reading response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, newAPIError(resp.StatusCode, respBody)
+	}
+	wr, err := decodeWit(respBody)
+	if err != nil {
+		return nil, fmt.Errorf("Decoding response: %w", err)
+	}
+	return wr.googleResponse(), nil
+}
+
+// decodeWit merges the objects Wit streams as it goes, partial and final
+// transcriptions then the understanding, each restating the text: the last
+// of each part wins.
+func decodeWit(body []byte) (*witResponse, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	var merged witResponse
+	for {
+		var wr witResponse
+		err := dec.Decode(&wr)
+		if errors.Is(err, io.EOF) {
+			return &merged, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if wr.Text != "" {
+			merged.Text = wr.Text
+		}
+		if wr.Speech.Tokens != nil {
+			merged.Speech = wr.Speech
+		}
+		if wr.Intents != nil {
+			merged.Intents = wr.Intents
+		}
+		if wr.Entities != nil {
+			merged.Entities = wr.Entities
+		}
+	}
+}
+
+func (wr *witResponse) googleResponse() *GoogleResponse {
+	text := strings.TrimSpace(wr.Text)
+	if text == "" {
+		return &GoogleResponse{}
+	}
+	alt := Alternative{Transcript: text, Confidence: wr.Speech.Confidence}
+	for _, tok := range wr.Speech.Tokens {
+		alt.Words = append(alt.Words, Word{
+			Word:       tok.Token,
+			Start:      time.Duration(tok.Start) * time.Millisecond,
+			End:        time.Duration(tok.End) * time.Millisecond,
+			Confidence: tok.Confidence,
+		})
+	}
+	r := Result{Alternatives: []Alternative{alt}, Final: true, Intents: wr.Intents}
+	for _, entities := range wr.Entities {
+		r.Entities = append(r.Entities, entities...)
+	}
+	sort.Slice(r.Entities, func(i, j int) bool { return r.Entities[i].Start < r.Entities[j].Start })
+	return &GoogleResponse{Results: []Result{r}}
+}
@@ -0,0 +1,54 @@
+package gorec
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWitBackend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer fr-app" {
+			t.Errorf("Authorization %q", got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "audio/raw;encoding=signed-integer;bits=16;rate=16000;endian=little" {
+			t.Errorf("Content-Type %q", got)
+		}
+		// Wit streams its partial transcriptions before the understanding.
+		fmt.Fprint(w, `{"text": "allume"}`+"\r\n")
+		fmt.Fprint(w, `{"text": "allume la lumière", "is_final": true, "speech": {"confidence": 0.85, "tokens": [
+			{"token": "allume", "start": 0, "end": 400, "confidence": 0.9},
+			{"token": "la", "start": 400, "end": 500, "confidence": 0.8},
+			{"token": "lumière", "start": 500, "end": 1000, "confidence": 0.85}]}}`+"\r\n")
+		fmt.Fprint(w, `{"text": "allume la lumière", "intents": [{"id": "1", "name": "turn_on", "confidence": 0.97}],
+			"entities": {"device:device": [{"name": "device", "role": "device", "body": "lumière", "value": "light", "confidence": 0.9, "start": 10, "end": 18}]}}`)
+	}))
+	defer srv.Close()
+
+	b := &WitBackend{Token: "en-app", Tokens: map[Language]string{French: "fr-app"}, Endpoint: srv.URL}
+	h, err := ListenFile([]byte{1, 2}, "", WithBackend(b), WithLanguages(French))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Alternative.Transcript != "allume la lumière" || h.Alternative.Confidence != 0.85 {
+		t.Errorf("hypothesis %v", h)
+	}
+	if words := h.Alternative.Words; len(words) != 3 || words[2].Start != 500*time.Millisecond || words[0].Confidence != 0.9 {
+		t.Errorf("Words = %+v", words)
+	}
+	if len(h.Intents) != 1 || h.Intents[0].Name != "turn_on" || h.Intents[0].Confidence != 0.97 {
+		t.Errorf("Intents = %+v", h.Intents)
+	}
+	if len(h.Entities) != 1 || h.Entities[0].Value != "light" || h.Entities[0].Role != "device" {
+		t.Errorf("Entities = %+v", h.Entities)
+	}
+}
+
+func TestWitBackendNoApp(t *testing.T) {
+	b := &WitBackend{Tokens: map[Language]string{French: "fr-app"}}
+	if _, err := ListenFile([]byte{1, 2}, "", WithBackend(b), WithLanguages(German)); err == nil {
+		t.Error("German recognized without an app")
+	}
+}